@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,29 +10,52 @@ import (
 	"syscall"
 	"time"
 
+	"library-management/internal/backup"
 	"library-management/internal/config"
 	"library-management/internal/database"
+	"library-management/internal/domain"
 	"library-management/internal/handler"
+	"library-management/internal/jobs"
+	"library-management/internal/publicid"
+	"library-management/internal/repository"
 	"library-management/internal/repository/postgres"
 	"library-management/internal/service"
+	"library-management/internal/storage"
+	"library-management/internal/tracing"
 	"library-management/pkg/logger"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
 )
 
 func main() {
-	// Initialize logger
-	log := logger.New()
-
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal("Failed to load configuration", "error", err)
+		logger.New().Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Initialize logger
+	log := logger.NewWithOptions(logger.Options{
+		RedactFields: cfg.LogRedactFields,
+		Level:        cfg.LogLevel,
+		Format:       cfg.LogFormat,
+	})
+	log.Info("Configuration loaded", "config", cfg)
+
+	// Export traces, if configured
+	if cfg.TracingEndpoint != "" {
+		shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingEndpoint)
+		if err != nil {
+			log.Fatal("Failed to set up tracing", "error", err)
+		}
+		defer shutdownTracing(context.Background())
+		log.Info("Tracing enabled", "endpoint", cfg.TracingEndpoint)
 	}
 
 	// Connect to database
 	log.Info("Connecting to database...")
-	db, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL, cfg.DatabaseMaxOpenConns, cfg.DatabaseConnMaxIdleTime)
 	if err != nil {
 		log.Fatal("Failed to connect to database", "error", err)
 	}
@@ -43,35 +67,131 @@ func main() {
 	}
 	log.Info("Database connection established")
 
+	repoOpts := []postgres.Option{
+		postgres.WithRetry(cfg.DatabaseMaxRetries, cfg.DatabaseRetryBackoff),
+	}
+
+	// Connect to the read replica, if configured
+	if cfg.DatabaseReadURL != "" {
+		log.Info("Connecting to read replica...")
+		replica, err := database.Connect(cfg.DatabaseReadURL, cfg.DatabaseMaxOpenConns, cfg.DatabaseConnMaxIdleTime)
+		if err != nil {
+			log.Fatal("Failed to connect to read replica", "error", err)
+		}
+		defer replica.Close()
+
+		repoOpts = append(repoOpts,
+			postgres.WithReadReplica(replica),
+			postgres.WithReplicaLagWindow(cfg.ReplicaLagWindow),
+		)
+		log.Info("Read replica connection established")
+	}
+
 	// Initialize database schema
 	log.Info("Initializing database...")
-	if err := database.InitializeDatabase(db); err != nil {
+	if err := database.InitializeDatabase(db, cfg); err != nil {
 		log.Fatal("Failed to initialize database", "error", err)
 	}
 	log.Info("Database initialization completed")
 
 	// Initialize layers
-	bookRepo := postgres.NewBookRepository(db)
-	bookService := service.NewBookService(bookRepo)
-	handlers := handler.NewHandlers(bookService, log)
+	bookRepo := postgres.NewBookRepository(db, repoOpts...)
+	if cfg.TracingEndpoint != "" {
+		bookRepo = repository.NewTracingRepository(bookRepo, otel.GetTracerProvider())
+	}
+	serviceOpts := []service.Option{
+		service.WithBulkUpdateConfirmThreshold(cfg.BulkUpdateConfirmThreshold),
+		service.WithCountMode(cfg.CountMode),
+		service.WithAuthorityMode(cfg.AuthorityMode),
+		service.WithPublishYearRange(cfg.PublishYears()),
+		service.WithDescriptionMaxLength(cfg.DescriptionMaxLength),
+		service.WithBatchSize(cfg.BatchSize),
+		service.WithImmutableFields(cfg.ImmutableFields),
+		service.WithKeepOriginalISBN(cfg.ISBNBackfillKeepOriginal),
+		service.WithGenreAliases(cfg.GenreAliases),
+		service.WithUnicodeNormalization(cfg.NormalizeUnicode),
+		service.WithProgress(func(operation string, done int) {
+			log.Info("Batch committed", "operation", operation, "done", done)
+		}),
+	}
+	store := newExportStorage(cfg)
+	if store != nil {
+		serviceOpts = append(serviceOpts, service.WithExportStorage(store))
+		log.Info("Catalog export enabled", "storage", cfg.ExportStorage)
+	}
+	publicIDs, err := publicid.New(cfg.PublicIDFormat)
+	if err != nil {
+		log.Fatal("Invalid public ID format", "error", err)
+	}
+	serviceOpts = append(serviceOpts, service.WithPublicIDGenerator(publicIDs))
+	bookService := service.NewBookService(bookRepo, serviceOpts...)
+	if cfg.TracingEndpoint != "" {
+		bookService = service.NewTracingService(bookService, otel.GetTracerProvider())
+	}
+
+	if cfg.ISBNBackfillOnStartup {
+		result, err := bookService.BackfillISBN13(context.Background())
+		if err != nil {
+			log.Fatal("ISBN-13 backfill failed", "error", err)
+		}
+		log.Info("ISBN-13 backfill completed", "scanned", result.Scanned, "converted", result.Converted,
+			"already_isbn13", result.AlreadyISBN13, "invalid", result.Invalid, "conflicts", result.Conflicts)
+	}
+
+	// Heavy operations share a fixed number of job slots
+	jobLimiter := jobs.NewLimiter(cfg.MaxConcurrentJobs, cfg.MaxQueuedJobs)
+
+	// Start scheduled backups, stopped on shutdown. Backups wait for a job
+	// slot rather than being queued or rejected.
+	backupCtx, stopBackups := context.WithCancel(context.Background())
+	defer stopBackups()
+	if cfg.BackupInterval > 0 {
+		exporter := backup.ExporterFunc(func(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+			job, err := jobLimiter.Do(ctx, "backup", func(ctx context.Context) (interface{}, error) {
+				return bookService.BackupCatalog(ctx, format)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return job.Result.(*domain.ExportResult), nil
+		})
+		scheduler := backup.NewScheduler(exporter, store, log, cfg.BackupInterval, cfg.BackupRetain, cfg.BackupFormat)
+		go scheduler.Run(backupCtx)
+		log.Info("Scheduled backups enabled", "interval", cfg.BackupInterval, "retain", cfg.BackupRetain)
+	}
+
+	// Ping the database and watch the pool in the background, stopped on shutdown
+	healthCtx, stopHealthCheck := context.WithCancel(context.Background())
+	defer stopHealthCheck()
+	if cfg.DatabaseHealthCheckInterval > 0 {
+		go database.NewHealthChecker(db, log, cfg.DatabaseHealthCheckInterval).Run(healthCtx)
+		log.Info("Database health checks enabled", "interval", cfg.DatabaseHealthCheckInterval)
+	}
+	if cfg.DatabasePoolMonitorInterval > 0 {
+		go database.NewPoolMonitor(db, log, cfg.DatabasePoolMonitorInterval, cfg.DatabasePoolMaxWaits, cfg.DatabasePoolMaxWaitDuration).Run(healthCtx)
+		log.Info("Database pool monitoring enabled", "interval", cfg.DatabasePoolMonitorInterval,
+			"max_waits", cfg.DatabasePoolMaxWaits, "max_wait_duration", cfg.DatabasePoolMaxWaitDuration)
+	}
+
+	handlers := handler.NewHandlers(bookService, db, log, cfg, handler.WithJobs(jobLimiter))
 
 	// Setup router
 	router := mux.NewRouter()
 	handler.SetupRoutes(router, handlers)
 
 	// Configure server
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.Port),
-		Handler:      router,
-		ReadTimeout:  time.Second * 15,
-		WriteTimeout: time.Second * 15,
-		IdleTimeout:  time.Second * 60,
-	}
+	server := newServer(cfg, router)
 
 	// Start server in goroutine
 	go func() {
-		log.Info("Starting server", "port", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info("Starting server", "port", cfg.Port, "tls", cfg.TLSCertFile != "")
+		var err error
+		if cfg.TLSCertFile != "" {
+			err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatal("Server failed to start", "error", err)
 		}
 	}()
@@ -81,6 +201,8 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Shutting down server...")
+	stopBackups()
+	stopHealthCheck()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -90,5 +212,54 @@ func main() {
 		log.Fatal("Server forced to shutdown", "error", err)
 	}
 
+	// Let running jobs finish in the remaining time, then cancel them
+	if err := jobLimiter.Shutdown(ctx); err != nil {
+		log.Error("Jobs canceled at shutdown", "error", err)
+	}
+
 	log.Info("Server exited")
 }
+
+// newExportStorage returns the configured catalog export storage, or nil when
+// export is disabled
+func newExportStorage(cfg *config.Config) storage.Storage {
+	switch cfg.ExportStorage {
+	case "local":
+		return storage.NewLocal(cfg.ExportDir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		}, &http.Client{Timeout: 5 * time.Minute})
+	default:
+		return nil
+	}
+}
+
+// newTLSConfig applies the configured minimum TLS version and cipher suites.
+// With no suites configured, Go's secure defaults are used.
+func newTLSConfig(cfg *config.Config) *tls.Config {
+	return &tls.Config{
+		MinVersion:   cfg.TLSMinVersion,
+		CipherSuites: cfg.TLSCipherSuites,
+	}
+}
+
+// newServer configures the HTTP server. ReadHeaderTimeout bounds only the
+// request line and headers, so clients that trickle headers to hold
+// connections open are cut off quickly, while ReadTimeout still allows
+// slower request bodies.
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.Port),
+		Handler:           handler,
+		TLSConfig:         newTLSConfig(cfg),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       time.Second * 15,
+		WriteTimeout:      time.Second * 15,
+		IdleTimeout:       time.Second * 60,
+	}
+}
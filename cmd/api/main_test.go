@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"library-management/internal/config"
+)
+
+func TestNewServer_ReadHeaderTimeout(t *testing.T) {
+	t.Setenv("READ_HEADER_TIMEOUT", "3s")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server := newServer(cfg, http.NotFoundHandler())
+	if server.ReadHeaderTimeout != 3*time.Second {
+		t.Errorf("ReadHeaderTimeout = %v, want 3s", server.ReadHeaderTimeout)
+	}
+	if server.ReadTimeout <= server.ReadHeaderTimeout {
+		t.Errorf("want ReadTimeout %v to exceed ReadHeaderTimeout %v", server.ReadTimeout, server.ReadHeaderTimeout)
+	}
+}
+
+func TestNewServer_TLSConfig(t *testing.T) {
+	t.Run("defaults to TLS 1.2 with Go's suites", func(t *testing.T) {
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		server := newServer(cfg, http.NotFoundHandler())
+		if server.TLSConfig.MinVersion != tls.VersionTLS12 {
+			t.Errorf("minimum TLS = %x, want 1.2", server.TLSConfig.MinVersion)
+		}
+		if server.TLSConfig.CipherSuites != nil {
+			t.Errorf("got %v, want default cipher suites", server.TLSConfig.CipherSuites)
+		}
+	})
+
+	t.Run("configured version and suites", func(t *testing.T) {
+		t.Setenv("TLS_MIN_VERSION", "1.3")
+		t.Setenv("TLS_CIPHER_SUITES", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		server := newServer(cfg, http.NotFoundHandler())
+		if server.TLSConfig.MinVersion != tls.VersionTLS13 {
+			t.Errorf("minimum TLS = %x, want 1.3", server.TLSConfig.MinVersion)
+		}
+		expected := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+		if !reflect.DeepEqual(server.TLSConfig.CipherSuites, expected) {
+			t.Errorf("cipher suites = %v, want %v", server.TLSConfig.CipherSuites, expected)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"library-management/internal/domain"
+	"library-management/internal/service"
+	"library-management/internal/storage"
+	"library-management/pkg/logger"
+)
+
+// Exporter writes a full catalog export to storage under
+// service.BackupKeyPrefix, the keys the scheduler prunes
+type Exporter interface {
+	ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error)
+}
+
+// ExporterFunc adapts a function to Exporter
+type ExporterFunc func(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error)
+
+// ExportCatalog calls f
+func (f ExporterFunc) ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	return f(ctx, format)
+}
+
+// Clock creates the ticker that drives the scheduler; tests substitute one
+// they can fire by hand
+type Clock interface {
+	NewTicker(d time.Duration) (<-chan time.Time, func())
+}
+
+type realClock struct{}
+
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+// Scheduler periodically exports the catalog and prunes old backups
+type Scheduler struct {
+	exporter Exporter
+	store    storage.Storage
+	logger   logger.Logger
+	clock    Clock
+
+	interval time.Duration
+	retain   int
+	format   domain.ExportFormat
+}
+
+// NewScheduler creates a scheduler that exports every interval and keeps the
+// newest retain backups; retain <= 0 keeps all of them
+func NewScheduler(exporter Exporter, store storage.Storage, log logger.Logger, interval time.Duration, retain int, format domain.ExportFormat) *Scheduler {
+	return &Scheduler{
+		exporter: exporter,
+		store:    store,
+		logger:   log,
+		clock:    realClock{},
+		interval: interval,
+		retain:   retain,
+		format:   format,
+	}
+}
+
+// Run backs up the catalog on every tick until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticks, stop := s.clock.NewTicker(s.interval)
+	defer stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce takes one backup and prunes old ones, logging the outcome
+func (s *Scheduler) runOnce(ctx context.Context) {
+	result, err := s.exporter.ExportCatalog(ctx, s.format)
+	if err != nil {
+		s.logger.Error("Scheduled backup failed", "error", err)
+		return
+	}
+	s.logger.Info("Scheduled backup completed", "key", result.Key, "size", result.Size)
+
+	if err := s.prune(ctx); err != nil {
+		s.logger.Error("Failed to prune old backups", "error", err)
+	}
+}
+
+// prune deletes all but the newest retain backups. Only keys under the
+// backup prefix are considered, so requested exports are never deleted.
+// Keys embed a UTC timestamp, so lexical order is chronological.
+func (s *Scheduler) prune(ctx context.Context) error {
+	if s.retain <= 0 {
+		return nil
+	}
+
+	keys, err := s.store.List(ctx, service.BackupKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(keys)-s.retain; i++ {
+		if err := s.store.Delete(ctx, keys[i]); err != nil {
+			return fmt.Errorf("failed to delete backup %s: %w", keys[i], err)
+		}
+		s.logger.Info("Deleted old backup", "key", keys[i])
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"library-management/internal/domain"
+	"library-management/internal/storage"
+	"library-management/pkg/logger"
+)
+
+// fakeClock hands out a ticker that only fires when the test sends on it
+type fakeClock struct {
+	ticks    chan time.Time
+	interval time.Duration
+	stopped  bool
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	c.interval = d
+	return c.ticks, func() { c.stopped = true }
+}
+
+// stubExporter writes numbered exports to storage, failing when err is set
+type stubExporter struct {
+	store storage.Storage
+	calls int
+	err   error
+}
+
+func (e *stubExporter) ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	key := fmt.Sprintf("backup-%03d.%s", e.calls, format)
+	size, err := e.store.Put(ctx, key, strings.NewReader("[]"))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExportResult{Key: key, Size: size, Format: format}, nil
+}
+
+// runTicks starts the scheduler, fires n ticks, then shuts it down
+func runTicks(t *testing.T, s *Scheduler, clock *fakeClock, n int) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < n; i++ {
+		clock.ticks <- time.Now()
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Scheduler did not stop after context cancellation")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	log := logger.NewWithOptions(logger.Options{Output: io.Discard})
+
+	t.Run("backs up on every tick and keeps the newest", func(t *testing.T) {
+		store := storage.NewLocal(t.TempDir())
+		exporter := &stubExporter{store: store}
+		clock := &fakeClock{ticks: make(chan time.Time)}
+		s := NewScheduler(exporter, store, log, time.Hour, 2, domain.ExportFormatJSON)
+		s.clock = clock
+
+		runTicks(t, s, clock, 4)
+
+		if clock.interval != time.Hour {
+			t.Errorf("ticker interval = %v, want 1h", clock.interval)
+		}
+		if !clock.stopped {
+			t.Error("want ticker to be stopped on shutdown")
+		}
+		if exporter.calls != 4 {
+			t.Errorf("got %d, want 4 backups", exporter.calls)
+		}
+		keys, _ := store.List(context.Background(), "backup-")
+		if strings.Join(keys, ",") != "backup-003.json,backup-004.json" {
+			t.Errorf("got %v, want the two newest backups", keys)
+		}
+	})
+
+	t.Run("requested exports are not pruned", func(t *testing.T) {
+		store := storage.NewLocal(t.TempDir())
+		if _, err := store.Put(context.Background(), "catalog-20240101T000000Z.json", strings.NewReader("[]")); err != nil {
+			t.Fatal(err)
+		}
+		exporter := &stubExporter{store: store}
+		clock := &fakeClock{ticks: make(chan time.Time)}
+		s := NewScheduler(exporter, store, log, time.Hour, 1, domain.ExportFormatJSON)
+		s.clock = clock
+
+		runTicks(t, s, clock, 3)
+
+		keys, _ := store.List(context.Background(), "")
+		if got, want := strings.Join(keys, ","), "backup-003.json,catalog-20240101T000000Z.json"; got != want {
+			t.Errorf("stored keys = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("failure does not stop the schedule", func(t *testing.T) {
+		store := storage.NewLocal(t.TempDir())
+		exporter := &stubExporter{store: store, err: errors.New("database unavailable")}
+		clock := &fakeClock{ticks: make(chan time.Time)}
+		s := NewScheduler(exporter, store, log, time.Minute, 2, domain.ExportFormatCSV)
+		s.clock = clock
+
+		runTicks(t, s, clock, 2)
+
+		if exporter.calls != 2 {
+			t.Errorf("got %d, want an attempt on each tick", exporter.calls)
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package citation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"library-management/internal/domain"
+)
+
+// Style is a citation format
+type Style string
+
+const (
+	// StyleAPA formats an APA 7th edition reference
+	StyleAPA Style = "apa"
+	// StyleMLA formats an MLA 9th edition works-cited entry
+	StyleMLA Style = "mla"
+	// StyleBibTeX formats a BibTeX @book entry
+	StyleBibTeX Style = "bibtex"
+)
+
+// ParseStyle parses a citation style name
+func ParseStyle(value string) (Style, error) {
+	switch style := Style(strings.ToLower(value)); style {
+	case StyleAPA, StyleMLA, StyleBibTeX:
+		return style, nil
+	default:
+		return "", fmt.Errorf("invalid citation style %q: must be bibtex, apa or mla", value)
+	}
+}
+
+// ContentType is the media type a citation in the style is served as
+func (s Style) ContentType() string {
+	if s == StyleBibTeX {
+		return "application/x-bibtex; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Format builds the citation of book in style. Missing fields are left out
+// rather than rendered empty.
+func Format(book *domain.Book, style Style) string {
+	switch style {
+	case StyleMLA:
+		return formatMLA(book)
+	case StyleBibTeX:
+		return formatBibTeX(book)
+	default:
+		return formatAPA(book)
+	}
+}
+
+// name is one author, split into family and given names. Organisations
+// such as "Gang of Four" keep their whole name in family.
+type name struct {
+	family string
+	given  string
+}
+
+// corporateWords mark an author as an organisation rather than a person
+var corporateWords = map[string]bool{"of": true, "the": true, "for": true}
+
+// authorSeparators split the author field into separate authors
+var authorSeparators = strings.NewReplacer(";", "\x00", " & ", "\x00", " and ", "\x00")
+
+// parseAuthors splits the author field into names. Authors may be separated
+// by ";", " and ", " & " or commas; "Martin, Robert C." is read as one
+// inverted name because its first part is a single word.
+func parseAuthors(field string) []name {
+	var names []name
+	for _, segment := range strings.Split(authorSeparators.Replace(field), "\x00") {
+		parts := strings.Split(segment, ",")
+		if len(parts) == 2 && !strings.Contains(strings.TrimSpace(parts[0]), " ") && strings.TrimSpace(parts[1]) != "" {
+			parts = []string{segment}
+		}
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part != "" {
+				names = append(names, parseName(part))
+			}
+		}
+	}
+	return names
+}
+
+// parseName splits "Given Family" or "Family, Given"
+func parseName(value string) name {
+	if family, given, ok := strings.Cut(value, ","); ok {
+		return name{family: strings.TrimSpace(family), given: strings.TrimSpace(given)}
+	}
+
+	words := strings.Fields(value)
+	for _, word := range words {
+		if corporateWords[strings.ToLower(word)] {
+			return name{family: strings.Join(words, " ")}
+		}
+	}
+	if len(words) == 1 {
+		return name{family: words[0]}
+	}
+	return name{family: words[len(words)-1], given: strings.Join(words[:len(words)-1], " ")}
+}
+
+// inverted renders "Family, Given"
+func (n name) inverted() string {
+	if n.given == "" {
+		return n.family
+	}
+	return n.family + ", " + n.given
+}
+
+// natural renders "Given Family"
+func (n name) natural() string {
+	if n.given == "" {
+		return n.family
+	}
+	return n.given + " " + n.family
+}
+
+// initials renders the given names as APA initials, e.g. "Robert C." as "R. C."
+func (n name) initials() string {
+	var initials []string
+	for _, word := range strings.Fields(n.given) {
+		var parts []string
+		for _, part := range strings.Split(word, "-") {
+			if r := []rune(part); len(r) > 0 {
+				parts = append(parts, string(unicode.ToUpper(r[0]))+".")
+			}
+		}
+		initials = append(initials, strings.Join(parts, "-"))
+	}
+	return strings.Join(initials, " ")
+}
+
+// formatAPA renders "Family, G. (Year). Title. Publisher."
+func formatAPA(book *domain.Book) string {
+	var parts []string
+
+	names := parseAuthors(book.Author)
+	authors := make([]string, len(names))
+	for i, n := range names {
+		authors[i] = n.family
+		if initials := n.initials(); initials != "" {
+			authors[i] += ", " + initials
+		}
+	}
+	switch len(authors) {
+	case 0:
+	case 1:
+		parts = append(parts, withPeriod(authors[0]))
+	default:
+		parts = append(parts, withPeriod(strings.Join(authors[:len(authors)-1], ", ")+", & "+authors[len(authors)-1]))
+	}
+
+	year := "n.d."
+	if book.PublishYear > 0 {
+		year = strconv.Itoa(book.PublishYear)
+	}
+	parts = append(parts, "("+year+").")
+
+	if book.Title != "" {
+		parts = append(parts, withPeriod(book.Title))
+	}
+	if book.Publisher != "" {
+		parts = append(parts, withPeriod(book.Publisher))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatMLA renders "Family, Given. Title. Publisher, Year."
+func formatMLA(book *domain.Book) string {
+	var parts []string
+
+	names := parseAuthors(book.Author)
+	switch len(names) {
+	case 0:
+	case 1:
+		parts = append(parts, withPeriod(names[0].inverted()))
+	case 2:
+		parts = append(parts, withPeriod(names[0].inverted()+", and "+names[1].natural()))
+	default:
+		parts = append(parts, names[0].inverted()+", et al.")
+	}
+
+	if book.Title != "" {
+		parts = append(parts, withPeriod(book.Title))
+	}
+
+	var published []string
+	if book.Publisher != "" {
+		published = append(published, book.Publisher)
+	}
+	if book.PublishYear > 0 {
+		published = append(published, strconv.Itoa(book.PublishYear))
+	}
+	if len(published) > 0 {
+		parts = append(parts, withPeriod(strings.Join(published, ", ")))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatBibTeX renders an @book entry
+func formatBibTeX(book *domain.Book) string {
+	names := parseAuthors(book.Author)
+	authors := make([]string, len(names))
+	for i, n := range names {
+		if n.given == "" && strings.Contains(n.family, " ") {
+			// Braces stop BibTeX splitting an organisation into names
+			authors[i] = "{" + escapeBibTeX(n.family) + "}"
+		} else {
+			authors[i] = escapeBibTeX(n.inverted())
+		}
+	}
+
+	fields := [][2]string{
+		{"author", strings.Join(authors, " and ")},
+		{"title", escapeBibTeX(book.Title)},
+		{"publisher", escapeBibTeX(book.Publisher)},
+	}
+	if book.PublishYear > 0 {
+		fields = append(fields, [2]string{"year", strconv.Itoa(book.PublishYear)})
+	}
+	fields = append(fields, [2]string{"isbn", escapeBibTeX(book.ISBN)})
+
+	var b strings.Builder
+	b.WriteString("@book{" + bibTeXKey(book, names) + ",\n")
+	var lines []string
+	for _, field := range fields {
+		if field[1] != "" {
+			lines = append(lines, fmt.Sprintf("  %-9s = {%s}", field[0], field[1]))
+		}
+	}
+	if len(lines) > 0 {
+		b.WriteString(strings.Join(lines, ",\n") + "\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// bibTeXKey builds a citation key from the first author's family name, the
+// year and the first word of the title, e.g. "martin2008clean"
+func bibTeXKey(book *domain.Book, names []name) string {
+	var key strings.Builder
+	if len(names) > 0 {
+		if words := strings.Fields(names[0].family); len(words) > 0 {
+			key.WriteString(keyWord(words[0]))
+		}
+	}
+	if book.PublishYear > 0 {
+		key.WriteString(strconv.Itoa(book.PublishYear))
+	}
+	for _, word := range strings.Fields(book.Title) {
+		if w := keyWord(word); w != "" && w != "a" && w != "an" && w != "the" {
+			key.WriteString(w)
+			break
+		}
+	}
+	if key.Len() == 0 {
+		return "book" + strconv.Itoa(book.ID)
+	}
+	return key.String()
+}
+
+// keyWord lowercases word and drops everything but ASCII letters and digits
+func keyWord(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// bibTeXEscaper escapes characters special to BibTeX and LaTeX
+var bibTeXEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`, "}", `\}`,
+	"&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`, "_", `\_`,
+)
+
+func escapeBibTeX(value string) string {
+	return bibTeXEscaper.Replace(value)
+}
+
+// withPeriod ends value with a period unless it already ends in terminal
+// punctuation
+func withPeriod(value string) string {
+	if strings.HasSuffix(value, ".") || strings.HasSuffix(value, "?") || strings.HasSuffix(value, "!") {
+		return value
+	}
+	return value + "."
+}
@@ -0,0 +1,115 @@
+package citation
+
+import (
+	"testing"
+
+	"library-management/internal/domain"
+)
+
+func cleanCode() *domain.Book {
+	return &domain.Book{
+		ID:          1,
+		Title:       "Clean Code",
+		Author:      "Robert C. Martin",
+		ISBN:        "978-0132350884",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+	}
+}
+
+func TestFormat(t *testing.T) {
+	designPatterns := &domain.Book{
+		Title:       "Design Patterns",
+		Author:      "Erich Gamma, Richard Helm, Ralph Johnson and John Vlissides",
+		Publisher:   "Addison-Wesley",
+		PublishYear: 1994,
+	}
+	refactoring := &domain.Book{
+		Title:       "Refactoring",
+		Author:      "Fowler, Martin & Kent Beck",
+		Publisher:   "Addison-Wesley",
+		PublishYear: 1999,
+	}
+	gangOfFour := &domain.Book{
+		Title:       "Design Patterns",
+		Author:      "Gang of Four",
+		Publisher:   "Addison-Wesley",
+		PublishYear: 1994,
+	}
+	sparse := &domain.Book{ID: 7, Title: "Who Moved My Cheese?"}
+
+	tests := []struct {
+		name  string
+		book  *domain.Book
+		style Style
+		want  string
+	}{
+		{"apa single author", cleanCode(), StyleAPA,
+			"Martin, R. C. (2008). Clean Code. Prentice Hall."},
+		{"apa many authors", designPatterns, StyleAPA,
+			"Gamma, E., Helm, R., Johnson, R., & Vlissides, J. (1994). Design Patterns. Addison-Wesley."},
+		{"apa inverted and natural names", refactoring, StyleAPA,
+			"Fowler, M., & Beck, K. (1999). Refactoring. Addison-Wesley."},
+		{"apa organisation", gangOfFour, StyleAPA,
+			"Gang of Four. (1994). Design Patterns. Addison-Wesley."},
+		{"apa missing fields", sparse, StyleAPA,
+			"(n.d.). Who Moved My Cheese?"},
+
+		{"mla single author", cleanCode(), StyleMLA,
+			"Martin, Robert C. Clean Code. Prentice Hall, 2008."},
+		{"mla two authors", refactoring, StyleMLA,
+			"Fowler, Martin, and Kent Beck. Refactoring. Addison-Wesley, 1999."},
+		{"mla many authors", designPatterns, StyleMLA,
+			"Gamma, Erich, et al. Design Patterns. Addison-Wesley, 1994."},
+		{"mla missing fields", sparse, StyleMLA,
+			"Who Moved My Cheese?"},
+
+		{"bibtex single author", cleanCode(), StyleBibTeX,
+			"@book{martin2008clean,\n" +
+				"  author    = {Martin, Robert C.},\n" +
+				"  title     = {Clean Code},\n" +
+				"  publisher = {Prentice Hall},\n" +
+				"  year      = {2008},\n" +
+				"  isbn      = {978-0132350884}\n" +
+				"}\n"},
+		{"bibtex many authors", designPatterns, StyleBibTeX,
+			"@book{gamma1994design,\n" +
+				"  author    = {Gamma, Erich and Helm, Richard and Johnson, Ralph and Vlissides, John},\n" +
+				"  title     = {Design Patterns},\n" +
+				"  publisher = {Addison-Wesley},\n" +
+				"  year      = {1994}\n" +
+				"}\n"},
+		{"bibtex organisation", gangOfFour, StyleBibTeX,
+			"@book{gang1994design,\n" +
+				"  author    = {{Gang of Four}},\n" +
+				"  title     = {Design Patterns},\n" +
+				"  publisher = {Addison-Wesley},\n" +
+				"  year      = {1994}\n" +
+				"}\n"},
+		{"bibtex missing fields and escaping", &domain.Book{ID: 7, Title: "R&D at 100%"}, StyleBibTeX,
+			"@book{rd,\n" +
+				"  title     = {R\\&D at 100\\%}\n" +
+				"}\n"},
+		{"bibtex key falls back to id", &domain.Book{ID: 7}, StyleBibTeX,
+			"@book{book7,\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.book, tt.style); got != tt.want {
+				t.Errorf("Format() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for _, value := range []string{"apa", "MLA", "BibTeX"} {
+		if _, err := ParseStyle(value); err != nil {
+			t.Errorf("ParseStyle(%q) returned error %v", value, err)
+		}
+	}
+	if _, err := ParseStyle("chicago"); err == nil {
+		t.Error("want error for unsupported style")
+	}
+}
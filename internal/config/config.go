@@ -1,8 +1,23 @@
 package config
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"library-management/internal/domain"
+	"library-management/internal/publicid"
+	"library-management/pkg/realip"
 )
 
 // Config holds all configuration for our application
@@ -11,11 +26,272 @@ type Config struct {
 	DatabaseURL  string
 	Environment  string
 	LogLevel     string
+	LogFormat    string
 	DatabaseHost string
 	DatabasePort string
 	DatabaseUser string
 	DatabasePass string
 	DatabaseName string
+
+	// ClientErrorLogLevel is the level client errors, such as a missing book
+	// or a failed validation, are logged at; server errors always log at error
+	ClientErrorLogLevel string
+
+	// DatabaseReadURL, when set, is a read-only replica used for book reads
+	DatabaseReadURL string
+	// ReplicaLagWindow is how long reads stay on the primary after a write
+	ReplicaLagWindow time.Duration
+
+	// DatabaseMaxRetries is how many times idempotent writes are retried after
+	// transient errors; DatabaseRetryBackoff is the initial, doubling delay
+	DatabaseMaxRetries   int
+	DatabaseRetryBackoff time.Duration
+
+	// DatabaseConnMaxIdleTime closes pooled connections idle for longer, so
+	// connections dropped by the server or a firewall are recycled
+	DatabaseConnMaxIdleTime time.Duration
+	// DatabaseHealthCheckInterval, when positive, pings the database on that
+	// interval and logs failures
+	DatabaseHealthCheckInterval time.Duration
+	// DatabasePoolMonitorInterval, when positive, samples the pool statistics
+	// on that interval and warns when more than DatabasePoolMaxWaits waits for
+	// a connection, or more than DatabasePoolMaxWaitDuration of waiting, grew
+	// since the previous sample; a zero threshold disables its check
+	DatabasePoolMonitorInterval time.Duration
+	DatabasePoolMaxWaits        int
+	DatabasePoolMaxWaitDuration time.Duration
+
+	// DatabaseMaxOpenConns caps the connection pool. With DatabaseQueueSize
+	// positive, API requests beyond it wait in a queue of that size for up to
+	// DatabaseQueueTimeout; requests finding the queue full, or timing out in
+	// it, get 503 at once instead of blocking on the pool.
+	DatabaseMaxOpenConns int
+	DatabaseQueueSize    int
+	DatabaseQueueTimeout time.Duration
+
+	// DatabaseSSLMode is the libpq sslmode used when building DatabaseURL
+	DatabaseSSLMode string
+	// DatabaseSSLRootCert is an optional CA certificate path for verify-ca/verify-full
+	DatabaseSSLRootCert string
+
+	// TrustedProxies are the proxies whose X-Forwarded-For entries are trusted
+	// when determining the client IP
+	TrustedProxies []netip.Prefix
+
+	// TracingEndpoint, when set, is the OTLP/HTTP endpoint traces are exported to
+	TracingEndpoint string
+
+	// CanonicalHost, when set, is the only host served; other hosts are redirected to it
+	CanonicalHost string
+
+	// TLSCertFile and TLSKeyFile, when both set, make the server listen with TLS
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSMinVersion is the lowest TLS version accepted; TLSCipherSuites, when
+	// set, restricts the TLS 1.2 cipher suites (TLS 1.3 suites are fixed)
+	TLSMinVersion   uint16
+	TLSCipherSuites []uint16
+
+	// ReadHeaderTimeout bounds how long the server waits for request headers
+	ReadHeaderTimeout time.Duration
+
+	// OutputTimezone is the IANA zone name used when rendering timestamps
+	OutputTimezone string
+	OutputLocation *time.Location
+
+	// BulkUpdateConfirmThreshold is the number of affected rows above which
+	// a bulk update requires explicit confirmation
+	BulkUpdateConfirmThreshold int
+
+	// BatchSize is how many books large operations process per batch
+	BatchSize int
+
+	// ImmutableFields lists book fields that cannot be changed by an update
+	// once the book exists
+	ImmutableFields []string
+
+	// GenreAliases maps lower-cased genre variants to their canonical form,
+	// e.g. "prog" to "Programming". Writes are normalized through it; empty
+	// leaves genres as sent.
+	GenreAliases map[string]string
+
+	// ISBNBackfillOnStartup converts valid ISBN-10s to ISBN-13 at startup;
+	// ISBNBackfillKeepOriginal keeps each replaced ISBN-10 in original_isbn
+	ISBNBackfillOnStartup    bool
+	ISBNBackfillKeepOriginal bool
+
+	// WarnDuplicateTitles adds a warning to create responses when another
+	// book has the same title and author
+	WarnDuplicateTitles bool
+	// WarnFuturePublishYear adds a warning to create and update responses when
+	// publish_year is after the current year but within PublishYearMax
+	WarnFuturePublishYear bool
+
+	// DefaultAvailableOnly limits GET /api/v1/books to available books when
+	// the client gives no available parameter and no admin key
+	DefaultAvailableOnly bool
+
+	// MaxListResults caps how many books GET /api/v1/books returns when the
+	// client gives no limit; zero disables the cap
+	MaxListResults int
+
+	// APIKeys, when set, are required in X-API-Key to call write endpoints
+	APIKeys []APIKey
+
+	// ExportStorage selects where catalog exports go: "local", "s3", or empty to disable export
+	ExportStorage string
+	// ExportDir is the directory local exports are written to
+	ExportDir string
+	// S3Endpoint, S3Bucket, S3Region and the credentials locate the S3-compatible
+	// bucket used when ExportStorage is "s3"
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// BackupInterval, when positive, runs a catalog export to ExportStorage
+	// on that interval; BackupRetain is how many backups to keep (0 keeps all)
+	BackupInterval time.Duration
+	BackupRetain   int
+	BackupFormat   domain.ExportFormat
+
+	// HealthToken, when set, is required to see detailed readiness output
+	HealthToken string
+
+	// MaxInFlight and MaxInFlightPerIP cap concurrent requests globally and per
+	// client IP (zero disables each). A request over a limit waits up to
+	// InFlightQueueTimeout for a slot, or is rejected at once when it is zero.
+	MaxInFlight          int
+	MaxInFlightPerIP     int
+	InFlightQueueTimeout time.Duration
+
+	// MaxConcurrentJobs caps how many heavy operations such as catalog
+	// exports run at once; MaxQueuedJobs more may wait for a slot
+	MaxConcurrentJobs int
+	MaxQueuedJobs     int
+
+	// ErrorRateThreshold, when positive, marks /ready degraded once the share
+	// of 5xx responses over ErrorRateWindow exceeds it
+	ErrorRateThreshold float64
+	ErrorRateWindow    time.Duration
+
+	// ResponseSizeMetrics records a histogram of response body sizes per
+	// route, served on /metrics. ResponseSizeBuckets are the ascending upper
+	// bounds of its buckets in bytes.
+	ResponseSizeMetrics bool
+	ResponseSizeBuckets []int
+
+	// MinSearchLength rejects non-empty list searches shorter than this many
+	// characters with 400; zero allows any length
+	MinSearchLength int
+
+	// RecordSearchTerms counts book list search terms in memory for the top
+	// searches endpoint. Off by default, as terms may be personal.
+	RecordSearchTerms bool
+	// SearchTermsMax caps how many distinct terms are kept; the least
+	// recently searched term is evicted first
+	SearchTermsMax int
+
+	// CacheMaxAge is how long successful GET responses may be cached; zero
+	// sends no-cache. CachePublic lets shared caches such as CDNs store
+	// responses to requests without credentials.
+	CacheMaxAge time.Duration
+	CachePublic bool
+
+	// WordsPerPage and WordsPerMinute are the assumptions behind reading time estimates
+	WordsPerPage   int
+	WordsPerMinute int
+
+	// PrettyJSON indents JSON responses unless a request sets pretty=false
+	PrettyJSON bool
+
+	// ProblemDetails sends every error as RFC 7807 application/problem+json
+	// instead of the response envelope. Clients can also ask for it with
+	// Accept: application/problem+json.
+	ProblemDetails bool
+
+	// DescriptionPlaceholder, when set, is shown in place of a missing book
+	// description in responses; stored descriptions are left empty
+	DescriptionPlaceholder string
+	// AccessionNumberDigits is how many digits the per-year counter of a
+	// book's accession number is padded to, e.g. 5 for 2024-00042
+	AccessionNumberDigits int
+
+	// ListDescriptionLength shortens descriptions in list responses to this
+	// many characters, flagging them description_truncated; single-book
+	// responses keep the full text. Zero disables truncation.
+	ListDescriptionLength int
+
+	// ListContentHash adds an X-Content-Hash header to list responses, derived
+	// from the IDs and update times of the books returned, so polling clients
+	// can tell whether the page changed
+	ListContentHash bool
+
+	// PublicIDs makes book routes take the opaque public ID instead of the
+	// sequential integer ID, and hides the integer ID from responses
+	PublicIDs bool
+
+	// PublicIDFormat selects how new books' public IDs are generated: a
+	// random uuid, a short random nanoid, or a slug of the title
+	PublicIDFormat publicid.Format
+
+	// RequireIfMatch rejects updates and deletes that do not send If-Match
+	RequireIfMatch bool
+
+	// RequireJSONContentType rejects API request bodies not sent as
+	// application/json with 415
+	RequireJSONContentType bool
+
+	// StrictAccept answers API requests whose Accept header allows none of
+	// the media types the route can send with 406; a missing Accept or */*
+	// still gets JSON
+	StrictAccept bool
+
+	// SeedCount is the total number of books to seed into an empty database;
+	// values above the fixed sample set add generated books
+	SeedCount int
+	// SeedRandomSeed seeds the generator so generated books are reproducible
+	SeedRandomSeed int64
+	// SeedSampleData seeds an empty database with sample books at startup
+	SeedSampleData bool
+
+	// CORSAllowedOrigins lists the origins allowed to call the API from a
+	// browser; "*" allows any origin and an empty list none
+	CORSAllowedOrigins []string
+
+	// PublishYearMin and PublishYearMax bound a book's publish year
+	PublishYearMin int
+	PublishYearMax int
+
+	// DescriptionMaxLength is the longest description, in characters, that
+	// validation and the database CHECK constraint accept
+	DescriptionMaxLength int
+
+	// NormalizeUnicode converts book text fields to Unicode NFC before they
+	// are stored; whitespace is trimmed and collapsed regardless
+	NormalizeUnicode bool
+
+	// CountMode selects exact, approximate or filtered_exact list totals
+	CountMode domain.CountMode
+
+	// AuthorityMode checks book authors and publishers against their
+	// reference tables: off, strict (reject unknown names) or lenient (add them)
+	AuthorityMode domain.AuthorityMode
+
+	// LogRedactFields lists log field and query parameter names whose values are masked
+	LogRedactFields []string
+	// LogSampleRate is the fraction of successful requests that are logged;
+	// requests failing with 4xx or 5xx are always logged. Nil means unset,
+	// logging every request, so an explicit 0 is kept apart from it.
+	LogSampleRate *float64
+
+	// LatencyBudgets maps "METHOD /route" or a bare "METHOD" to the duration
+	// a request may take before a warning is logged; routes use mux templates
+	// without variable patterns, e.g. "GET /api/v1/books/{id}". Requests
+	// matching no entry use LatencyBudgetDefault, where zero disables the check.
+	LatencyBudgets       map[string]time.Duration
+	LatencyBudgetDefault time.Duration
 }
 
 // Load loads configuration from environment variables
@@ -23,31 +299,664 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		Port:         getEnv("PORT", "8080"),
 		Environment:  getEnv("ENVIRONMENT", "development"),
-		LogLevel:     getEnv("LOG_LEVEL", "info"),
 		DatabaseHost: getEnv("DB_HOST", "localhost"),
 		DatabasePort: getEnv("DB_PORT", "5432"),
 		DatabaseUser: getEnv("DB_USER", "library_user"),
 		DatabasePass: getEnv("DB_PASSWORD", "library_pass"),
 		DatabaseName: getEnv("DB_NAME", "library_db"),
+
+		DatabaseSSLRootCert: os.Getenv("DB_SSLROOTCERT"),
+		DatabaseReadURL:     os.Getenv("DATABASE_READ_URL"),
+
+		OutputTimezone: getEnv("OUTPUT_TIMEZONE", "UTC"),
+		HealthToken:    os.Getenv("HEALTH_TOKEN"),
+		CanonicalHost:  os.Getenv("CANONICAL_HOST"),
+
+		DescriptionPlaceholder: os.Getenv("DESCRIPTION_PLACEHOLDER"),
+
+		TracingEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+
+		TLSCertFile: os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:  os.Getenv("TLS_KEY_FILE"),
+
+		ExportStorage:     os.Getenv("EXPORT_STORAGE"),
+		ExportDir:         getEnv("EXPORT_DIR", "./exports"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+
+		LogRedactFields: getEnvList("LOG_REDACT_FIELDS", []string{"authorization", "password", "token", "api_key", "borrower"}),
+	}
+
+	var err error
+	defaults := cfg.environmentDefaults()
+	cfg.LogLevel = getEnv("LOG_LEVEL", defaults.logLevel)
+	cfg.LogFormat = getEnv("LOG_FORMAT", defaults.logFormat)
+	cfg.ClientErrorLogLevel = getEnv("CLIENT_ERROR_LOG_LEVEL", "warn")
+	cfg.CORSAllowedOrigins = getEnvList("CORS_ALLOWED_ORIGINS", defaults.corsAllowedOrigins)
+	if cfg.SeedSampleData, err = getEnvBool("SEED_SAMPLE_DATA", defaults.seedSampleData); err != nil {
+		return nil, err
+	}
+
+	if cfg.APIKeys, err = parseAPIKeys(os.Getenv("API_KEYS")); err != nil {
+		return nil, err
+	}
+
+	switch cfg.ExportStorage {
+	case "", "local":
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("EXPORT_STORAGE=s3 requires S3_ENDPOINT and S3_BUCKET")
+		}
+	default:
+		return nil, fmt.Errorf("invalid EXPORT_STORAGE %q: must be local or s3", cfg.ExportStorage)
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLSMinVersion, err = parseTLSVersion(getEnv("TLS_MIN_VERSION", "1.2")); err != nil {
+		return nil, err
+	}
+	if cfg.TLSCipherSuites, err = parseCipherSuites(getEnvList("TLS_CIPHER_SUITES", nil)); err != nil {
+		return nil, err
+	}
+
+	if cfg.TrustedProxies, err = realip.ParsePrefixes(getEnvList("TRUSTED_PROXIES", nil)); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	if cfg.BackupInterval, err = getEnvDuration("BACKUP_INTERVAL", 0); err != nil {
+		return nil, err
+	}
+	if cfg.BackupInterval > 0 && cfg.ExportStorage == "" {
+		return nil, fmt.Errorf("BACKUP_INTERVAL requires EXPORT_STORAGE")
+	}
+	if cfg.BackupRetain, err = getEnvInt("BACKUP_RETAIN", 7); err != nil {
+		return nil, err
+	}
+	if cfg.BackupRetain < 0 {
+		return nil, fmt.Errorf("invalid BACKUP_RETAIN %d: must not be negative", cfg.BackupRetain)
+	}
+	if cfg.BackupFormat, err = domain.ParseExportFormat(getEnv("BACKUP_FORMAT", string(domain.ExportFormatJSON))); err != nil {
+		return nil, err
+	}
+
+	if cfg.RequireIfMatch, err = getEnvBool("REQUIRE_IF_MATCH", false); err != nil {
+		return nil, err
+	}
+	if cfg.RequireJSONContentType, err = getEnvBool("REQUIRE_JSON_CONTENT_TYPE", false); err != nil {
+		return nil, err
+	}
+	if cfg.StrictAccept, err = getEnvBool("STRICT_ACCEPT", false); err != nil {
+		return nil, err
+	}
+	if cfg.WarnDuplicateTitles, err = getEnvBool("WARN_DUPLICATE_TITLES", false); err != nil {
+		return nil, err
+	}
+	if cfg.WarnFuturePublishYear, err = getEnvBool("WARN_FUTURE_PUBLISH_YEAR", false); err != nil {
+		return nil, err
+	}
+	if cfg.DefaultAvailableOnly, err = getEnvBool("DEFAULT_AVAILABLE_ONLY", false); err != nil {
+		return nil, err
+	}
+	if cfg.PublicIDs, err = getEnvBool("PUBLIC_IDS", false); err != nil {
+		return nil, err
+	}
+	if cfg.PublicIDFormat, err = publicid.ParseFormat(getEnv("PUBLIC_ID_FORMAT", string(publicid.FormatUUID))); err != nil {
+		return nil, err
+	}
+	if cfg.PrettyJSON, err = getEnvBool("PRETTY_JSON", false); err != nil {
+		return nil, err
+	}
+	if cfg.ProblemDetails, err = getEnvBool("PROBLEM_DETAILS", false); err != nil {
+		return nil, err
+	}
+	if cfg.WordsPerPage, err = getEnvInt("WORDS_PER_PAGE", domain.DefaultWordsPerPage); err != nil {
+		return nil, err
+	}
+	if cfg.WordsPerPage <= 0 {
+		return nil, fmt.Errorf("invalid WORDS_PER_PAGE %d: must be positive", cfg.WordsPerPage)
+	}
+	if cfg.WordsPerMinute, err = getEnvInt("WORDS_PER_MINUTE", domain.DefaultWordsPerMinute); err != nil {
+		return nil, err
+	}
+	if cfg.WordsPerMinute <= 0 {
+		return nil, fmt.Errorf("invalid WORDS_PER_MINUTE %d: must be positive", cfg.WordsPerMinute)
+	}
+	if cfg.CacheMaxAge, err = getEnvDuration("CACHE_MAX_AGE", 0); err != nil {
+		return nil, err
+	}
+	if cfg.CachePublic, err = getEnvBool("CACHE_PUBLIC", false); err != nil {
+		return nil, err
+	}
+	if cfg.SeedCount, err = getEnvInt("SEED_COUNT", 0); err != nil {
+		return nil, err
+	}
+	seed, err := getEnvInt("SEED_RANDOM_SEED", 1)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SeedRandomSeed = int64(seed)
+	if cfg.BulkUpdateConfirmThreshold, err = getEnvInt("BULK_UPDATE_CONFIRM_THRESHOLD", 100); err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize, err = getEnvInt("BATCH_SIZE", domain.DefaultBatchSize); err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid BATCH_SIZE %d: must be positive", cfg.BatchSize)
+	}
+	if cfg.GenreAliases, err = parseGenreAliases(os.Getenv("GENRE_ALIASES")); err != nil {
+		return nil, err
+	}
+	if cfg.ISBNBackfillOnStartup, err = getEnvBool("ISBN_BACKFILL_ON_STARTUP", false); err != nil {
+		return nil, err
+	}
+	if cfg.ISBNBackfillKeepOriginal, err = getEnvBool("ISBN_BACKFILL_KEEP_ORIGINAL", false); err != nil {
+		return nil, err
+	}
+	cfg.ImmutableFields = getEnvList("IMMUTABLE_FIELDS", nil)
+	for _, field := range cfg.ImmutableFields {
+		if !slices.Contains(domain.UpdatableFields, field) {
+			return nil, fmt.Errorf("invalid IMMUTABLE_FIELDS entry %q: must be one of %s", field, strings.Join(domain.UpdatableFields, ", "))
+		}
+	}
+	if cfg.MaxListResults, err = getEnvInt("MAX_LIST_RESULTS", 1000); err != nil {
+		return nil, err
+	}
+	if cfg.MaxListResults < 0 {
+		return nil, fmt.Errorf("invalid MAX_LIST_RESULTS %d: must not be negative", cfg.MaxListResults)
+	}
+
+	if cfg.AccessionNumberDigits, err = getEnvInt("ACCESSION_NUMBER_DIGITS", 5); err != nil {
+		return nil, err
+	}
+	if cfg.AccessionNumberDigits < 1 || cfg.AccessionNumberDigits > 10 {
+		return nil, fmt.Errorf("invalid ACCESSION_NUMBER_DIGITS %d: must be between 1 and 10", cfg.AccessionNumberDigits)
+	}
+
+	if cfg.ListDescriptionLength, err = getEnvInt("LIST_DESCRIPTION_LENGTH", 0); err != nil {
+		return nil, err
+	}
+	if cfg.ListDescriptionLength < 0 {
+		return nil, fmt.Errorf("invalid LIST_DESCRIPTION_LENGTH %d: must not be negative", cfg.ListDescriptionLength)
+	}
+	if cfg.ListContentHash, err = getEnvBool("LIST_CONTENT_HASH", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxInFlight, err = getEnvInt("MAX_IN_FLIGHT", 0); err != nil {
+		return nil, err
+	}
+	if cfg.MaxInFlightPerIP, err = getEnvInt("MAX_IN_FLIGHT_PER_IP", 0); err != nil {
+		return nil, err
+	}
+	if cfg.MaxInFlight < 0 || cfg.MaxInFlightPerIP < 0 {
+		return nil, fmt.Errorf("invalid MAX_IN_FLIGHT %d / MAX_IN_FLIGHT_PER_IP %d: must not be negative", cfg.MaxInFlight, cfg.MaxInFlightPerIP)
+	}
+	if cfg.InFlightQueueTimeout, err = getEnvDuration("IN_FLIGHT_QUEUE_TIMEOUT", 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConcurrentJobs, err = getEnvInt("MAX_CONCURRENT_JOBS", 2); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrentJobs <= 0 {
+		return nil, fmt.Errorf("invalid MAX_CONCURRENT_JOBS %d: must be positive", cfg.MaxConcurrentJobs)
+	}
+	if cfg.MaxQueuedJobs, err = getEnvInt("MAX_QUEUED_JOBS", 10); err != nil {
+		return nil, err
+	}
+	if cfg.MaxQueuedJobs < 0 {
+		return nil, fmt.Errorf("invalid MAX_QUEUED_JOBS %d: must not be negative", cfg.MaxQueuedJobs)
+	}
+
+	if cfg.ErrorRateThreshold, err = getEnvFloat("ERROR_RATE_THRESHOLD", 0); err != nil {
+		return nil, err
+	}
+	if !(cfg.ErrorRateThreshold >= 0 && cfg.ErrorRateThreshold <= 1) {
+		return nil, fmt.Errorf("invalid ERROR_RATE_THRESHOLD %v: must be between 0 and 1", cfg.ErrorRateThreshold)
+	}
+	if cfg.ErrorRateWindow, err = getEnvDuration("ERROR_RATE_WINDOW", time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ErrorRateWindow <= 0 {
+		return nil, fmt.Errorf("invalid ERROR_RATE_WINDOW %v: must be positive", cfg.ErrorRateWindow)
+	}
+	if cfg.ResponseSizeMetrics, err = getEnvBool("RESPONSE_SIZE_METRICS", false); err != nil {
+		return nil, err
+	}
+	if cfg.ResponseSizeBuckets, err = parseSizeBuckets(getEnv("RESPONSE_SIZE_BUCKETS", "256,1024,4096,16384,65536,262144,1048576")); err != nil {
+		return nil, err
+	}
+
+	if cfg.RecordSearchTerms, err = getEnvBool("RECORD_SEARCH_TERMS", false); err != nil {
+		return nil, err
+	}
+	if cfg.MinSearchLength, err = getEnvInt("MIN_SEARCH_LENGTH", 2); err != nil {
+		return nil, err
+	}
+	if cfg.MinSearchLength < 0 {
+		return nil, fmt.Errorf("invalid MIN_SEARCH_LENGTH %d: must not be negative", cfg.MinSearchLength)
+	}
+	if cfg.SearchTermsMax, err = getEnvInt("SEARCH_TERMS_MAX", 1000); err != nil {
+		return nil, err
+	}
+	if cfg.SearchTermsMax <= 0 {
+		return nil, fmt.Errorf("invalid SEARCH_TERMS_MAX %d: must be positive", cfg.SearchTermsMax)
+	}
+
+	if os.Getenv("LOG_SAMPLE_RATE") != "" {
+		rate, err := getEnvFloat("LOG_SAMPLE_RATE", 1)
+		if err != nil {
+			return nil, err
+		}
+		if !(rate >= 0 && rate <= 1) {
+			return nil, fmt.Errorf("invalid LOG_SAMPLE_RATE %v: must be between 0 and 1", rate)
+		}
+		cfg.LogSampleRate = &rate
+	}
+
+	if cfg.LatencyBudgets, err = parseLatencyBudgets(os.Getenv("LATENCY_BUDGETS")); err != nil {
+		return nil, err
+	}
+	if cfg.LatencyBudgetDefault, err = getEnvDuration("LATENCY_BUDGET_DEFAULT", 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.ReadHeaderTimeout, err = getEnvDuration("READ_HEADER_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseMaxRetries, err = getEnvInt("DB_MAX_RETRIES", 2); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseMaxRetries < 0 {
+		return nil, fmt.Errorf("invalid DB_MAX_RETRIES %d: must not be negative", cfg.DatabaseMaxRetries)
+	}
+	if cfg.DatabaseRetryBackoff, err = getEnvDuration("DB_RETRY_BACKOFF", 50*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseConnMaxIdleTime, err = getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseHealthCheckInterval, err = getEnvDuration("DB_HEALTH_CHECK_INTERVAL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DatabasePoolMonitorInterval, err = getEnvDuration("DB_POOL_MONITOR_INTERVAL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DatabasePoolMaxWaits, err = getEnvInt("DB_POOL_MAX_WAITS", 10); err != nil {
+		return nil, err
+	}
+	if cfg.DatabasePoolMaxWaits < 0 {
+		return nil, fmt.Errorf("invalid DB_POOL_MAX_WAITS %d: must not be negative", cfg.DatabasePoolMaxWaits)
+	}
+	if cfg.DatabasePoolMaxWaitDuration, err = getEnvDuration("DB_POOL_MAX_WAIT_DURATION", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseMaxOpenConns, err = getEnvInt("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseMaxOpenConns <= 0 {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS %d: must be positive", cfg.DatabaseMaxOpenConns)
+	}
+	if cfg.DatabaseQueueSize, err = getEnvInt("DB_QUEUE_SIZE", 0); err != nil {
+		return nil, err
+	}
+	if cfg.DatabaseQueueSize < 0 {
+		return nil, fmt.Errorf("invalid DB_QUEUE_SIZE %d: must not be negative", cfg.DatabaseQueueSize)
+	}
+	if cfg.DatabaseQueueTimeout, err = getEnvDuration("DB_QUEUE_TIMEOUT", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ReplicaLagWindow, err = getEnvDuration("REPLICA_LAG_WINDOW", 5*time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.CountMode, err = domain.ParseCountMode(getEnv("COUNT_MODE", string(domain.CountModeExact))); err != nil {
+		return nil, err
+	}
+
+	if cfg.AuthorityMode, err = domain.ParseAuthorityMode(getEnv("AUTHORITY_MODE", string(domain.AuthorityModeOff))); err != nil {
+		return nil, err
+	}
+
+	defaultYears := domain.DefaultPublishYears()
+	if cfg.PublishYearMin, err = getEnvInt("PUBLISH_YEAR_MIN", defaultYears.Min); err != nil {
+		return nil, err
+	}
+	if cfg.PublishYearMax, err = getEnvInt("PUBLISH_YEAR_MAX", defaultYears.Max); err != nil {
+		return nil, err
+	}
+	if cfg.PublishYearMin > cfg.PublishYearMax {
+		return nil, fmt.Errorf("PUBLISH_YEAR_MIN (%d) must not exceed PUBLISH_YEAR_MAX (%d)", cfg.PublishYearMin, cfg.PublishYearMax)
+	}
+	if currentYear := time.Now().Year(); cfg.PublishYearMax < currentYear {
+		return nil, fmt.Errorf("PUBLISH_YEAR_MAX (%d) is before the current year (%d)", cfg.PublishYearMax, currentYear)
+	}
+	if cfg.DescriptionMaxLength, err = getEnvInt("DESCRIPTION_MAX_LENGTH", domain.DefaultDescriptionMaxLength); err != nil {
+		return nil, err
+	}
+	if cfg.DescriptionMaxLength < 1 {
+		return nil, fmt.Errorf("invalid DESCRIPTION_MAX_LENGTH %d: must be at least 1", cfg.DescriptionMaxLength)
+	}
+	if cfg.NormalizeUnicode, err = getEnvBool("NORMALIZE_UNICODE", false); err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(cfg.OutputTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTPUT_TIMEZONE %q: %w", cfg.OutputTimezone, err)
+	}
+	cfg.OutputLocation = loc
+
+	cfg.DatabaseSSLMode = getEnv("DB_SSLMODE", defaults.sslMode)
+	if !validSSLModes[cfg.DatabaseSSLMode] {
+		return nil, fmt.Errorf("invalid DB_SSLMODE %q: must be one of disable, require, verify-ca, verify-full", cfg.DatabaseSSLMode)
 	}
 
 	// Build database URL if not provided directly
 	if dbURL := os.Getenv("DATABASE_URL"); dbURL != "" {
 		cfg.DatabaseURL = dbURL
 	} else {
-		cfg.DatabaseURL = fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			cfg.DatabaseUser,
-			cfg.DatabasePass,
-			cfg.DatabaseHost,
-			cfg.DatabasePort,
-			cfg.DatabaseName,
-		)
+		cfg.DatabaseURL = cfg.buildDatabaseURL()
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// environmentDefaults are the settings whose defaults depend on ENVIRONMENT;
+// an explicit environment variable still overrides each of them
+type environmentDefaults struct {
+	logLevel           string
+	logFormat          string
+	seedSampleData     bool
+	sslMode            string
+	corsAllowedOrigins []string
+}
+
+// environmentDefaults returns the defaults for the configured environment.
+// Production logs JSON, seeds nothing, requires SSL and allows no
+// cross-origin requests; other environments suit local work, with verbose
+// text logs, sample data, plain connections and any origin allowed.
+func (c *Config) environmentDefaults() environmentDefaults {
+	if c.IsProduction() {
+		return environmentDefaults{
+			logLevel:  "info",
+			logFormat: "json",
+			sslMode:   "require",
+		}
+	}
+	return environmentDefaults{
+		logLevel:           "debug",
+		logFormat:          "text",
+		seedSampleData:     true,
+		sslMode:            "disable",
+		corsAllowedOrigins: []string{"*"},
+	}
+}
+
+// validSSLModes are the sslmode values supported by the postgres driver
+var validSSLModes = map[string]bool{
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// buildDatabaseURL builds a PostgreSQL connection URL from the DB_* settings
+func (c *Config) buildDatabaseURL() string {
+	query := url.Values{}
+	query.Set("sslmode", c.DatabaseSSLMode)
+	if c.DatabaseSSLRootCert != "" {
+		query.Set("sslrootcert", c.DatabaseSSLRootCert)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.DatabaseUser, c.DatabasePass),
+		Host:     net.JoinHostPort(c.DatabaseHost, c.DatabasePort),
+		Path:     "/" + c.DatabaseName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// APIKey is an accepted API key, stored as the hex SHA-256 of the key, with
+// an optional role
+type APIKey struct {
+	Hash string
+	Role string
+}
+
+// parseAPIKeys parses comma-separated "<sha256-hex>[:role]" entries
+func parseAPIKeys(value string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, entry := range getEnvListValue(value) {
+		hash, role, _ := strings.Cut(entry, ":")
+		hash = strings.ToLower(hash)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid API_KEYS entry %q: must be a hex SHA-256 hash with an optional :role", entry)
+		}
+		keys = append(keys, APIKey{Hash: hash, Role: role})
+	}
+	return keys, nil
+}
+
+// parseGenreAliases parses comma-separated "variant=Canonical" entries. Keys
+// are lower-cased, and each canonical genre is also mapped from its own
+// lower-cased form so differently cased copies are folded into it.
+func parseGenreAliases(value string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, entry := range getEnvListValue(value) {
+		variant, canonical, _ := strings.Cut(entry, "=")
+		variant, canonical = strings.TrimSpace(variant), strings.TrimSpace(canonical)
+		if variant == "" || canonical == "" {
+			return nil, fmt.Errorf("invalid GENRE_ALIASES entry %q: must be variant=Canonical", entry)
+		}
+		aliases[strings.ToLower(variant)] = canonical
+		aliases[strings.ToLower(canonical)] = canonical
+	}
+	return aliases, nil
+}
+
+// parseSizeBuckets parses comma-separated byte sizes, which must be positive
+// and ascending
+func parseSizeBuckets(value string) ([]int, error) {
+	var buckets []int
+	for _, entry := range getEnvListValue(value) {
+		size, err := strconv.Atoi(entry)
+		if err != nil || size <= 0 || (len(buckets) > 0 && size <= buckets[len(buckets)-1]) {
+			return nil, fmt.Errorf("invalid RESPONSE_SIZE_BUCKETS entry %q: sizes must be positive and ascending", entry)
+		}
+		buckets = append(buckets, size)
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("invalid RESPONSE_SIZE_BUCKETS %q: must list at least one size", value)
+	}
+	return buckets, nil
+}
+
+// parseLatencyBudgets parses comma-separated "METHOD[ /route]=duration"
+// entries, upper-casing the method
+func parseLatencyBudgets(value string) (map[string]time.Duration, error) {
+	budgets := make(map[string]time.Duration)
+	for _, entry := range getEnvListValue(value) {
+		key, duration, _ := strings.Cut(entry, "=")
+		method, route, hasRoute := strings.Cut(strings.TrimSpace(key), " ")
+		budget, err := time.ParseDuration(strings.TrimSpace(duration))
+		route = strings.TrimSpace(route)
+		if method == "" || err != nil || budget <= 0 || (hasRoute && !strings.HasPrefix(route, "/")) {
+			return nil, fmt.Errorf("invalid LATENCY_BUDGETS entry %q: must be METHOD[ /route]=duration", entry)
+		}
+
+		key = strings.ToUpper(method)
+		if hasRoute {
+			key += " " + route
+		}
+		budgets[key] = budget
+	}
+	return budgets, nil
+}
+
+// tlsVersions maps TLS_MIN_VERSION values to crypto/tls versions
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses a minimum TLS version; versions below 1.2 are rejected
+func parseTLSVersion(value string) (uint16, error) {
+	version, ok := tlsVersions[value]
+	if !ok {
+		return 0, fmt.Errorf("invalid TLS_MIN_VERSION %q: must be 1.2 or 1.3", value)
+	}
+	return version, nil
+}
+
+// parseCipherSuites resolves cipher suite names, as listed by tls.CipherSuites,
+// to their IDs. Suites Go considers insecure are rejected.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	var ids []uint16
+	for _, name := range names {
+		id, ok := secureCipherSuite(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid TLS_CIPHER_SUITES entry %q: not a supported secure cipher suite", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func secureCipherSuite(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// environments, logLevels and logFormats are the accepted ENVIRONMENT,
+// LOG_LEVEL and LOG_FORMAT values
+var (
+	environments = []string{"development", "staging", "production"}
+	logLevels    = []string{"debug", "info", "warn", "error"}
+	logFormats   = []string{"json", "text"}
+)
+
+// Validate checks the settings Load reads as plain strings, so a bad value
+// stops startup instead of failing later
+func (c *Config) Validate() error {
+	if !validPort(c.Port) {
+		return fmt.Errorf("invalid PORT %q: must be a number between 1 and 65535", c.Port)
+	}
+	if !slices.Contains(environments, c.Environment) {
+		return fmt.Errorf("invalid ENVIRONMENT %q: must be one of %s", c.Environment, strings.Join(environments, ", "))
+	}
+	if !slices.Contains(logLevels, strings.ToLower(c.LogLevel)) {
+		return fmt.Errorf("invalid LOG_LEVEL %q: must be one of %s", c.LogLevel, strings.Join(logLevels, ", "))
+	}
+	if !slices.Contains(logFormats, strings.ToLower(c.LogFormat)) {
+		return fmt.Errorf("invalid LOG_FORMAT %q: must be one of %s", c.LogFormat, strings.Join(logFormats, ", "))
+	}
+	if !slices.Contains(logLevels, strings.ToLower(c.ClientErrorLogLevel)) {
+		return fmt.Errorf("invalid CLIENT_ERROR_LOG_LEVEL %q: must be one of %s", c.ClientErrorLogLevel, strings.Join(logLevels, ", "))
+	}
+	if c.DatabaseHost == "" || c.DatabaseUser == "" || c.DatabaseName == "" {
+		return fmt.Errorf("DB_HOST, DB_USER and DB_NAME must not be empty")
+	}
+	if !validPort(c.DatabasePort) {
+		return fmt.Errorf("invalid DB_PORT %q: must be a number between 1 and 65535", c.DatabasePort)
+	}
+	if c.DatabaseURL == "" {
+		return fmt.Errorf("no database configured: set DATABASE_URL or the DB_* settings")
+	}
+	return nil
+}
+
+// validPort reports whether port is a TCP port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// LogValue summarizes the effective configuration for the startup log.
+// Passwords in database URLs are redacted and other secrets are left out.
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("port", c.Port),
+		slog.String("environment", c.Environment),
+		slog.String("log_level", c.LogLevel),
+		slog.String("log_format", c.LogFormat),
+		slog.String("client_error_log_level", c.ClientErrorLogLevel),
+		slog.String("database_url", redactURL(c.DatabaseURL)),
+		slog.String("database_read_url", redactURL(c.DatabaseReadURL)),
+		slog.String("db_sslmode", c.DatabaseSSLMode),
+		slog.Int("db_max_retries", c.DatabaseMaxRetries),
+		slog.Duration("db_health_check_interval", c.DatabaseHealthCheckInterval),
+		slog.Duration("db_pool_monitor_interval", c.DatabasePoolMonitorInterval),
+		slog.Int("db_pool_max_waits", c.DatabasePoolMaxWaits),
+		slog.Duration("db_pool_max_wait_duration", c.DatabasePoolMaxWaitDuration),
+		slog.Int("db_max_open_conns", c.DatabaseMaxOpenConns),
+		slog.Int("db_queue_size", c.DatabaseQueueSize),
+		slog.Duration("db_queue_timeout", c.DatabaseQueueTimeout),
+		slog.Bool("tls", c.TLSCertFile != ""),
+		slog.String("canonical_host", c.CanonicalHost),
+		slog.String("cors_allowed_origins", strings.Join(c.CORSAllowedOrigins, ",")),
+		slog.Bool("seed_sample_data", c.SeedSampleData),
+		slog.String("tracing_endpoint", c.TracingEndpoint),
+		slog.Int("api_keys", len(c.APIKeys)),
+		slog.String("export_storage", c.ExportStorage),
+		slog.Duration("backup_interval", c.BackupInterval),
+		slog.String("output_timezone", c.OutputTimezone),
+		slog.String("count_mode", string(c.CountMode)),
+		slog.String("authority_mode", string(c.AuthorityMode)),
+		slog.Int("batch_size", c.BatchSize),
+		slog.Bool("isbn_backfill_on_startup", c.ISBNBackfillOnStartup),
+		slog.Int("max_list_results", c.MaxListResults),
+		slog.Int("list_description_length", c.ListDescriptionLength),
+		slog.Bool("list_content_hash", c.ListContentHash),
+		slog.Int("accession_number_digits", c.AccessionNumberDigits),
+		slog.Int("description_max_length", c.DescriptionMaxLength),
+		slog.Bool("normalize_unicode", c.NormalizeUnicode),
+		slog.Int("max_in_flight", c.MaxInFlight),
+		slog.Int("max_in_flight_per_ip", c.MaxInFlightPerIP),
+		slog.Int("max_concurrent_jobs", c.MaxConcurrentJobs),
+		slog.Bool("public_ids", c.PublicIDs),
+		slog.String("public_id_format", string(c.PublicIDFormat)),
+		slog.Bool("require_if_match", c.RequireIfMatch),
+		slog.Bool("strict_accept", c.StrictAccept),
+		slog.Int("min_search_length", c.MinSearchLength),
+		slog.Bool("record_search_terms", c.RecordSearchTerms),
+		slog.Float64("log_sample_rate", c.LogSampleFraction()),
+		slog.Int("latency_budgets", len(c.LatencyBudgets)),
+		slog.Int("genre_aliases", len(c.GenreAliases)),
+		slog.Duration("latency_budget_default", c.LatencyBudgetDefault),
+		slog.Bool("response_size_metrics", c.ResponseSizeMetrics),
+	)
+}
+
+// redactURL masks the password in a URL; values that do not parse are
+// dropped entirely, since they may still hold credentials
+func redactURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return "<unparseable>"
+	}
+	return u.Redacted()
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -58,6 +967,20 @@ func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
 
+// PublishYears returns the configured publish year range
+func (c *Config) PublishYears() domain.PublishYearRange {
+	return domain.PublishYearRange{Min: c.PublishYearMin, Max: c.PublishYearMax}
+}
+
+// LogSampleFraction returns the fraction of successful requests to log:
+// LogSampleRate when set, otherwise 1
+func (c *Config) LogSampleFraction() float64 {
+	if c.LogSampleRate == nil {
+		return 1
+	}
+	return *c.LogSampleRate
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -65,3 +988,79 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvInt gets an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be an integer", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvBool gets a boolean environment variable with a fallback value
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be a boolean", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvFloat gets a floating point environment variable with a fallback value
+func getEnvFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be a number", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvDuration gets a duration environment variable with a fallback value
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative duration", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvList gets a comma-separated environment variable with a fallback value
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return getEnvListValue(value)
+}
+
+// getEnvListValue splits a comma-separated value, dropping blank items
+func getEnvListValue(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"library-management/pkg/logger"
+)
+
+// Pinger is the part of *sql.DB the health checker uses
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthChecker periodically pings the database so dropped connections are
+// noticed, and discarded by the pool, before a request trips over them
+type HealthChecker struct {
+	db       Pinger
+	logger   logger.Logger
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewHealthChecker creates a checker that pings db every interval. Each ping
+// is bounded by the interval, so a hung connection cannot stall the checker.
+func NewHealthChecker(db Pinger, log logger.Logger, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		db:       db,
+		logger:   log,
+		interval: interval,
+		timeout:  interval,
+	}
+}
+
+// Run pings the database on every interval until ctx is cancelled. Failures
+// are logged as errors, and the first success after a failure as recovery.
+func (c *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		err := c.ping(ctx)
+		duration := time.Since(start)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			failures++
+			c.logger.Error("Database health check failed", "error", err, "consecutive_failures", failures, "duration", duration)
+		case failures > 0:
+			c.logger.Info("Database health check recovered", "failed_checks", failures, "duration", duration)
+			failures = 0
+		default:
+			c.logger.Debug("Database health check passed", "duration", duration)
+		}
+	}
+}
+
+// ping runs one bounded health check
+func (c *HealthChecker) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.db.PingContext(ctx)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"library-management/pkg/logger"
+)
+
+// countingPinger records when it is pinged, failing while err is set
+type countingPinger struct {
+	mu    sync.Mutex
+	pings []time.Time
+	err   error
+}
+
+func (p *countingPinger) PingContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pings = append(p.pings, time.Now())
+	return p.err
+}
+
+func (p *countingPinger) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pings)
+}
+
+func TestHealthChecker_RunsOnInterval(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	pinger := &countingPinger{err: errors.New("driver: bad connection")}
+	checker := NewHealthChecker(pinger, logger.NewWithOptions(logger.Options{Output: io.Discard}), interval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pinger.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Health checker did not stop after context cancellation")
+	}
+
+	pinger.mu.Lock()
+	defer pinger.mu.Unlock()
+	if len(pinger.pings) < 3 {
+		t.Fatalf("got %d, want at least 3 pings", len(pinger.pings))
+	}
+	// Pings are driven by the ticker, not issued back to back
+	if first := pinger.pings[0].Sub(start); first < interval/2 {
+		t.Errorf("got %v, want the first ping after about %v", first, interval)
+	}
+	if elapsed := pinger.pings[2].Sub(start); elapsed < 2*interval {
+		t.Errorf("want 3 pings to take at least %v, took %v", 2*interval, elapsed)
+	}
+}
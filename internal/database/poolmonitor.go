@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"library-management/pkg/logger"
+)
+
+// StatsSource is the part of *sql.DB the pool monitor uses
+type StatsSource interface {
+	Stats() sql.DBStats
+}
+
+// PoolMonitor periodically samples the connection pool statistics and warns
+// when requests wait for connections, so a pool too small for the load is
+// noticed before requests start timing out
+type PoolMonitor struct {
+	db              StatsSource
+	logger          logger.Logger
+	interval        time.Duration
+	maxWaits        int64
+	maxWaitDuration time.Duration
+
+	last      sql.DBStats
+	saturated bool
+}
+
+// NewPoolMonitor creates a monitor that samples db every interval. A warning
+// is logged when more than maxWaits connection waits, or more than
+// maxWaitDuration of waiting in total, happened since the previous sample.
+// A zero threshold disables that check.
+func NewPoolMonitor(db StatsSource, log logger.Logger, interval time.Duration, maxWaits int, maxWaitDuration time.Duration) *PoolMonitor {
+	return &PoolMonitor{
+		db:              db,
+		logger:          log,
+		interval:        interval,
+		maxWaits:        int64(maxWaits),
+		maxWaitDuration: maxWaitDuration,
+	}
+}
+
+// Run samples the pool on every interval until ctx is cancelled
+func (m *PoolMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.last = m.db.Stats()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.check()
+	}
+}
+
+// check compares the current statistics with the previous sample. The wait
+// counters are cumulative, so only their growth since then is judged; the
+// first sample after saturation ends is logged as recovery.
+func (m *PoolMonitor) check() {
+	stats := m.db.Stats()
+	waits := stats.WaitCount - m.last.WaitCount
+	waited := stats.WaitDuration - m.last.WaitDuration
+	m.last = stats
+
+	saturated := (m.maxWaits > 0 && waits > m.maxWaits) ||
+		(m.maxWaitDuration > 0 && waited > m.maxWaitDuration)
+	args := []interface{}{
+		"waits", waits,
+		"wait_duration", waited,
+		"in_use", stats.InUse,
+		"idle", stats.Idle,
+		"open", stats.OpenConnections,
+		"max_open", stats.MaxOpenConnections,
+	}
+	switch {
+	case saturated:
+		m.logger.Warn("Database connection pool saturated", args...)
+	case m.saturated:
+		m.logger.Info("Database connection pool recovered", args...)
+	default:
+		m.logger.Debug("Database connection pool sampled", args...)
+	}
+	m.saturated = saturated
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"library-management/pkg/logger"
+)
+
+// sequenceStats returns the next of its samples on each call, repeating the last
+type sequenceStats struct {
+	samples []sql.DBStats
+}
+
+func (s *sequenceStats) Stats() sql.DBStats {
+	stats := s.samples[0]
+	if len(s.samples) > 1 {
+		s.samples = s.samples[1:]
+	}
+	return stats
+}
+
+func TestPoolMonitor_WarnsWhenWaitsCrossThreshold(t *testing.T) {
+	pool := func(waits int64, waited time.Duration, inUse int) sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 10, InUse: inUse, Idle: 10 - inUse, WaitCount: waits, WaitDuration: waited}
+	}
+	source := &sequenceStats{samples: []sql.DBStats{
+		pool(100, 5*time.Second, 4),  // baseline; earlier waits are not judged
+		pool(103, 5*time.Second, 6),  // 3 waits: below both thresholds
+		pool(110, 5*time.Second, 10), // 7 waits: over the count threshold
+		pool(111, 8*time.Second, 10), // 3s of waiting: over the duration threshold
+		pool(111, 8*time.Second, 3),  // no waits: recovered
+	}}
+
+	var buf bytes.Buffer
+	log := logger.NewWithOptions(logger.Options{Output: &buf, Level: "info"})
+	monitor := NewPoolMonitor(source, log, time.Minute, 5, 2*time.Second)
+	monitor.last = source.Stats()
+
+	var entries []map[string]interface{}
+	sample := func() map[string]interface{} {
+		buf.Reset()
+		monitor.check()
+		if buf.Len() == 0 {
+			return nil
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &entry); err != nil {
+			t.Fatalf("Failed to decode log entry %q: %v", buf.String(), err)
+		}
+		entries = append(entries, entry)
+		return entry
+	}
+
+	if entry := sample(); entry != nil {
+		t.Errorf("got %v, want nothing logged below the thresholds", entry)
+	}
+
+	entry := sample()
+	if entry == nil || entry["level"] != "WARN" {
+		t.Fatalf("got %v, want a warning once waits cross the threshold", entry)
+	}
+	if entry["waits"] != float64(7) || entry["in_use"] != float64(10) || entry["idle"] != float64(0) {
+		t.Errorf("got %v, want the wait growth and pool usage in the warning", entry)
+	}
+
+	if entry := sample(); entry == nil || entry["level"] != "WARN" || entry["waits"] != float64(1) {
+		t.Errorf("got %v, want a warning once wait time crosses the threshold", entry)
+	}
+
+	if entry := sample(); entry == nil || entry["level"] != "INFO" || entry["in_use"] != float64(3) {
+		t.Errorf("got %v, want recovery to be logged", entry)
+	}
+	if entry := sample(); entry != nil {
+		t.Errorf("got %v, want nothing logged once recovered", entry)
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d, want 3 entries", len(entries))
+	}
+}
@@ -2,13 +2,20 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"library-management/internal/config"
+	"library-management/internal/domain"
+
+	"github.com/lib/pq"
 )
 
-// Connect establishes a connection to PostgreSQL database
-func Connect(databaseURL string) (*sql.DB, error) {
+// Connect establishes a connection to PostgreSQL database, with a pool of at
+// most maxOpenConns connections that are closed after connMaxIdleTime idle
+func Connect(databaseURL string, maxOpenConns int, connMaxIdleTime time.Duration) (*sql.DB, error) {
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -20,14 +27,15 @@ func Connect(databaseURL string) (*sql.DB, error) {
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
+	db.SetMaxOpenConns(maxOpenConns)
 	db.SetMaxIdleConns(5)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
 
 	return db, nil
 }
 
 // InitializeDatabase creates the database schema and sample data
-func InitializeDatabase(db *sql.DB) error {
+func InitializeDatabase(db *sql.DB, cfg *config.Config) error {
 	fmt.Println("Initializing database schema...")
 
 	// Create books table
@@ -35,6 +43,31 @@ func InitializeDatabase(db *sql.DB) error {
 		return fmt.Errorf("failed to create books table: %w", err)
 	}
 
+	// Give every book an opaque public ID
+	if err := addPublicIDColumn(db); err != nil {
+		return fmt.Errorf("failed to add public ID column: %w", err)
+	}
+
+	// Keep the ISBN-10 a book had before an ISBN-13 backfill
+	if err := addOriginalISBNColumn(db); err != nil {
+		return fmt.Errorf("failed to add original ISBN column: %w", err)
+	}
+
+	// Number books per year of acquisition
+	if err := applyAccessionNumbers(db, cfg.AccessionNumberDigits); err != nil {
+		return fmt.Errorf("failed to apply accession numbers: %w", err)
+	}
+
+	// Align the publish year CHECK constraint with the configured range
+	if err := applyPublishYearConstraint(db, cfg.PublishYearMin, cfg.PublishYearMax); err != nil {
+		return fmt.Errorf("failed to apply publish year constraint: %w", err)
+	}
+
+	// Align the description length CHECK constraint with the configured limit
+	if err := applyDescriptionLengthConstraint(db, cfg.DescriptionMaxLength); err != nil {
+		return fmt.Errorf("failed to apply description length constraint: %w", err)
+	}
+
 	// Create indexes
 	if err := createIndexes(db); err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
@@ -45,9 +78,19 @@ func InitializeDatabase(db *sql.DB) error {
 		fmt.Printf("Warning: failed to create triggers: %v\n", err)
 	}
 
-	// Insert sample data if table is empty
-	if err := insertSampleData(db); err != nil {
-		return fmt.Errorf("failed to insert sample data: %w", err)
+	// Insert sample data if enabled and the table is empty
+	if cfg.SeedSampleData {
+		if err := insertSampleData(db, cfg.SeedCount, cfg.SeedRandomSeed, cfg.BatchSize); err != nil {
+			return fmt.Errorf("failed to insert sample data: %w", err)
+		}
+	} else {
+		fmt.Println("Sample data seeding disabled")
+	}
+
+	// Reference tables of known authors and publishers, created after the
+	// sample data so a new catalog's tables are seeded from it
+	if err := createAuthorityTables(db); err != nil {
+		return fmt.Errorf("failed to create authority tables: %w", err)
 	}
 
 	fmt.Println("Database initialization completed successfully")
@@ -63,7 +106,7 @@ func createBooksTable(db *sql.DB) error {
 		author VARCHAR(255) NOT NULL,
 		isbn VARCHAR(20) UNIQUE NOT NULL,
 		publisher VARCHAR(255) NOT NULL,
-		publish_year INTEGER NOT NULL CHECK (publish_year >= 1000 AND publish_year <= 2030),
+		publish_year INTEGER NOT NULL,
 		genre VARCHAR(100) NOT NULL,
 		pages INTEGER NOT NULL CHECK (pages > 0),
 		available BOOLEAN NOT NULL DEFAULT true,
@@ -80,6 +123,203 @@ func createBooksTable(db *sql.DB) error {
 	return nil
 }
 
+// addPublicIDColumn adds the public_id column used as an opaque external key.
+// The volatile default gives existing rows distinct IDs when the column is added.
+// The column is text so it can hold any PUBLIC_ID_FORMAT; databases created
+// with the earlier uuid column are converted in place, as migration 010 does.
+func addPublicIDColumn(db *sql.DB) error {
+	query := `
+	ALTER TABLE books ADD COLUMN IF NOT EXISTS public_id TEXT NOT NULL DEFAULT gen_random_uuid()::text;
+	DO $$
+	BEGIN
+		IF (SELECT data_type FROM information_schema.columns
+		    WHERE table_name = 'books' AND column_name = 'public_id') = 'uuid' THEN
+			ALTER TABLE books ALTER COLUMN public_id TYPE TEXT USING public_id::text;
+			ALTER TABLE books ALTER COLUMN public_id SET DEFAULT gen_random_uuid()::text;
+		END IF;
+	END $$;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_books_public_id ON books(public_id);`
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addOriginalISBNColumn adds the original_isbn column, where the ISBN-13
+// backfill records the ISBN-10 it replaced when asked to keep it
+func addOriginalISBNColumn(db *sql.DB) error {
+	query := `ALTER TABLE books ADD COLUMN IF NOT EXISTS original_isbn VARCHAR(20);`
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createAuthorityTables creates the authors and publishers reference tables
+// checked by AUTHORITY_MODE, with names unique ignoring case. A table that
+// is still empty is seeded with the names already on books, so enabling
+// strict checks does not reject the existing catalog's authors and
+// publishers.
+func createAuthorityTables(db *sql.DB) error {
+	for table, column := range map[string]string{"authors": "author", "publishers": "publisher"} {
+		query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_%[1]s_name_lower ON %[1]s (LOWER(name));
+		INSERT INTO %[1]s (name)
+		SELECT DISTINCT ON (LOWER(%[2]s)) %[2]s FROM books
+		WHERE NOT EXISTS (SELECT 1 FROM %[1]s)
+		ORDER BY LOWER(%[2]s), %[2]s
+		ON CONFLICT DO NOTHING;`, table, column)
+
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// applyAccessionNumbers gives every book an accession number such as
+// 2024-00042: the year it was created and a counter for that year, padded to
+// digits. A BEFORE INSERT trigger takes the next number from the year's row
+// in accession_counters. The row stays locked until the inserting
+// transaction ends, so concurrent creates are numbered one after another,
+// and a rolled back create gives its number back. An insert whose ISBN
+// already exists keeps the existing book's number, so upserts use none.
+func applyAccessionNumbers(db *sql.DB, digits int) error {
+	query := fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS accession_counters (
+		year INTEGER PRIMARY KEY,
+		last_number INTEGER NOT NULL
+	);
+	ALTER TABLE books ADD COLUMN IF NOT EXISTS accession_number VARCHAR(20);
+
+	CREATE OR REPLACE FUNCTION assign_accession_number()
+	RETURNS TRIGGER AS $func$
+	DECLARE
+		acc_year INTEGER := EXTRACT(YEAR FROM COALESCE(NEW.created_at, CURRENT_TIMESTAMP) AT TIME ZONE 'UTC');
+		acc_number INTEGER;
+	BEGIN
+		IF NEW.accession_number IS NOT NULL THEN
+			RETURN NEW;
+		END IF;
+		SELECT accession_number INTO NEW.accession_number FROM books WHERE isbn = NEW.isbn;
+		IF NEW.accession_number IS NOT NULL THEN
+			RETURN NEW;
+		END IF;
+
+		INSERT INTO accession_counters (year, last_number) VALUES (acc_year, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = accession_counters.last_number + 1
+		RETURNING last_number INTO acc_number;
+		NEW.accession_number := acc_year || '-' || lpad(acc_number::text, GREATEST(%d, length(acc_number::text)), '0');
+		RETURN NEW;
+	END;
+	$func$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS assign_books_accession_number ON books;
+	CREATE TRIGGER assign_books_accession_number
+		BEFORE INSERT ON books
+		FOR EACH ROW
+		EXECUTE FUNCTION assign_accession_number();`, digits)
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	return backfillAccessionNumbers(db, digits)
+}
+
+// backfillAccessionNumbers numbers the books from before accession numbers
+// existed in creation order, after any numbers already given out for their
+// year, and advances the counters past them. Books and counters are locked
+// against writes throughout, in the order inserts take them, so creates wait
+// rather than take a number twice.
+func backfillAccessionNumbers(db *sql.DB, digits int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"LOCK TABLE books, accession_counters IN EXCLUSIVE MODE",
+		fmt.Sprintf(`
+		WITH pending AS (
+			SELECT id, created_at,
+			       EXTRACT(YEAR FROM COALESCE(created_at, CURRENT_TIMESTAMP) AT TIME ZONE 'UTC')::int AS year
+			FROM books
+			WHERE accession_number IS NULL
+		), numbered AS (
+			SELECT p.id, p.year,
+			       COALESCE(c.last_number, 0) + ROW_NUMBER() OVER (PARTITION BY p.year ORDER BY p.created_at, p.id) AS number
+			FROM pending p
+			LEFT JOIN accession_counters c ON c.year = p.year
+		)
+		UPDATE books b
+		SET accession_number = n.year || '-' || lpad(n.number::text, GREATEST(%d, length(n.number::text)), '0')
+		FROM numbered n
+		WHERE b.id = n.id`, digits),
+		`
+		INSERT INTO accession_counters (year, last_number)
+		SELECT split_part(accession_number, '-', 1)::int, MAX(split_part(accession_number, '-', 2)::int)
+		FROM books
+		GROUP BY 1
+		ON CONFLICT (year) DO UPDATE SET last_number = GREATEST(accession_counters.last_number, EXCLUDED.last_number)`,
+		"ALTER TABLE books ALTER COLUMN accession_number SET NOT NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_books_accession_number ON books(accession_number)",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// applyPublishYearConstraint replaces the publish_year CHECK constraint with one
+// for the configured range. The constraint is added NOT VALID so existing rows
+// outside a narrowed range do not block startup; new writes are still checked.
+func applyPublishYearConstraint(db *sql.DB, min, max int) error {
+	query := fmt.Sprintf(`
+	ALTER TABLE books DROP CONSTRAINT IF EXISTS books_publish_year_check;
+	ALTER TABLE books ADD CONSTRAINT books_publish_year_check
+		CHECK (publish_year >= %d AND publish_year <= %d) NOT VALID;`, min, max)
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	fmt.Printf("Publish year constraint set to %d-%d\n", min, max)
+	return nil
+}
+
+// applyDescriptionLengthConstraint replaces the description CHECK constraint
+// with one for the configured limit. Like the publish year constraint it is
+// added NOT VALID, so longer descriptions already stored do not block
+// startup.
+func applyDescriptionLengthConstraint(db *sql.DB, max int) error {
+	query := fmt.Sprintf(`
+	ALTER TABLE books DROP CONSTRAINT IF EXISTS books_description_length_check;
+	ALTER TABLE books ADD CONSTRAINT books_description_length_check
+		CHECK (char_length(description) <= %d) NOT VALID;`, max)
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	fmt.Printf("Description length constraint set to %d characters\n", max)
+	return nil
+}
+
 // createIndexes creates database indexes for better performance
 func createIndexes(db *sql.DB) error {
 	indexes := []string{
@@ -88,6 +328,18 @@ func createIndexes(db *sql.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_books_available ON books(available);",
 		"CREATE INDEX IF NOT EXISTS idx_books_title ON books(title);",
 		"CREATE INDEX IF NOT EXISTS idx_books_isbn ON books(isbn);",
+		"CREATE INDEX IF NOT EXISTS idx_books_publisher ON books(publisher);",
+		"CREATE INDEX IF NOT EXISTS idx_books_isbn_normalized ON books(UPPER(REPLACE(REPLACE(isbn, '-', ''), ' ', '')));",
+		// The list filters compare LOWER(column); these expression indexes let
+		// them use an index. Trigram indexes serve the '%term%' LIKE filters.
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm;",
+		"CREATE INDEX IF NOT EXISTS idx_books_genre_lower ON books(LOWER(genre));",
+		// Serves the publisher prefix lookups behind GET /publishers?prefix=
+		"CREATE INDEX IF NOT EXISTS idx_books_publisher_lower_prefix ON books(LOWER(publisher) text_pattern_ops);",
+		"CREATE INDEX IF NOT EXISTS idx_books_author_lower_trgm ON books USING GIN (LOWER(author) gin_trgm_ops);",
+		"CREATE INDEX IF NOT EXISTS idx_books_publisher_lower_trgm ON books USING GIN (LOWER(publisher) gin_trgm_ops);",
+		"CREATE INDEX IF NOT EXISTS idx_books_title_lower_trgm ON books USING GIN (LOWER(title) gin_trgm_ops);",
+		"CREATE INDEX IF NOT EXISTS idx_books_description_lower_trgm ON books USING GIN (LOWER(description) gin_trgm_ops);",
 	}
 
 	for _, indexQuery := range indexes {
@@ -132,12 +384,34 @@ func createTriggers(db *sql.DB) error {
 	return nil
 }
 
-// insertSampleData inserts sample books if the table is empty
-func insertSampleData(db *sql.DB) error {
+// sampleDataLockKey identifies the advisory lock held while seeding sample data
+const sampleDataLockKey int64 = 0x4c4942 // "LIB"
+
+// insertSampleData inserts sample books if the table is empty. When seedCount
+// exceeds the fixed sample set, additional synthetic books are generated.
+// Rows are inserted batchSize per statement.
+//
+// The check and insert run in one transaction holding a transaction-scoped
+// advisory lock, so when several instances start against a fresh database
+// only the first one seeds and the others see the seeded rows.
+func insertSampleData(db *sql.DB, seedCount int, seed int64, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = domain.DefaultBatchSize
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", sampleDataLockKey); err != nil {
+		return fmt.Errorf("failed to acquire sample data lock: %w", err)
+	}
+
 	// Check if sample data already exists
 	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM books").Scan(&count)
-	if err != nil {
+	if err := tx.QueryRow("SELECT COUNT(*) FROM books").Scan(&count); err != nil {
 		return err
 	}
 
@@ -148,114 +422,73 @@ func insertSampleData(db *sql.DB) error {
 
 	fmt.Println("Inserting sample data...")
 
-	// Define sample books
-	sampleBooks := []struct {
-		title, author, isbn, publisher, genre, description string
-		publishYear, pages                                 int
-	}{
-		{
-			title:       "The Go Programming Language",
-			author:      "Alan Donovan, Brian Kernighan",
-			isbn:        "978-0134190440",
-			publisher:   "Addison-Wesley",
-			publishYear: 2015,
-			genre:       "Programming",
-			pages:       380,
-			description: "The authoritative resource to writing clear and idiomatic Go to solve real-world problems.",
-		},
-		{
-			title:       "Clean Code",
-			author:      "Robert C. Martin",
-			isbn:        "978-0132350884",
-			publisher:   "Prentice Hall",
-			publishYear: 2008,
-			genre:       "Programming",
-			pages:       464,
-			description: "A handbook of agile software craftsmanship.",
-		},
-		{
-			title:       "Design Patterns",
-			author:      "Gang of Four",
-			isbn:        "978-0201633610",
-			publisher:   "Addison-Wesley",
-			publishYear: 1994,
-			genre:       "Programming",
-			pages:       395,
-			description: "Elements of reusable object-oriented software.",
-		},
-		{
-			title:       "The Pragmatic Programmer",
-			author:      "David Thomas, Andrew Hunt",
-			isbn:        "978-0135957059",
-			publisher:   "Addison-Wesley",
-			publishYear: 2019,
-			genre:       "Programming",
-			pages:       352,
-			description: "Your journey to mastery.",
-		},
-		{
-			title:       "Microservices Patterns",
-			author:      "Chris Richardson",
-			isbn:        "978-1617294549",
-			publisher:   "Manning Publications",
-			publishYear: 2018,
-			genre:       "Architecture",
-			pages:       520,
-			description: "With examples in Java.",
-		},
-		{
-			title:       "Building Microservices",
-			author:      "Sam Newman",
-			isbn:        "978-1491950357",
-			publisher:   "O'Reilly Media",
-			publishYear: 2015,
-			genre:       "Architecture",
-			pages:       280,
-			description: "Designing fine-grained systems.",
-		},
-		{
-			title:       "Domain-Driven Design",
-			author:      "Eric Evans",
-			isbn:        "978-0321125217",
-			publisher:   "Addison-Wesley",
-			publishYear: 2003,
-			genre:       "Architecture",
-			pages:       560,
-			description: "Tackling complexity in the heart of software.",
-		},
-		{
-			title:       "The Art of Computer Programming",
-			author:      "Donald Knuth",
-			isbn:        "978-0201896831",
-			publisher:   "Addison-Wesley",
-			publishYear: 1997,
-			genre:       "Computer Science",
-			pages:       650,
-			description: "Volume 1: Fundamental Algorithms.",
-		},
-	}
-
-	// Insert each book
-	insertQuery := `
+	inserted, err := insertBooks(tx, buildSampleBooks(seedCount, seed), batchSize)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			fmt.Printf("Warning: sample data already inserted by another instance: %v\n", err)
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("Sample data inserted successfully (%d books)\n", inserted)
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// insertBooks inserts books using multi-row INSERT statements of up to batchSize rows.
+// Rows whose ISBN already exists are skipped.
+func insertBooks(tx *sql.Tx, books []sampleBook, batchSize int) (int, error) {
+	inserted := 0
+
+	for start := 0; start < len(books); start += batchSize {
+		end := start + batchSize
+		if end > len(books) {
+			end = len(books)
+		}
+		batch := books[start:end]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*8)
+		for i, book := range batch {
+			n := i * 8
+			placeholders = append(placeholders, fmt.Sprintf(
+				"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8,
+			))
+			args = append(args,
+				book.title,
+				book.author,
+				book.isbn,
+				book.publisher,
+				book.publishYear,
+				book.genre,
+				book.pages,
+				book.description,
+			)
+		}
+
+		query := `
 	INSERT INTO books (title, author, isbn, publisher, publish_year, genre, pages, description) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-
-	for _, book := range sampleBooks {
-		_, err := db.Exec(insertQuery,
-			book.title,
-			book.author,
-			book.isbn,
-			book.publisher,
-			book.publishYear,
-			book.genre,
-			book.pages,
-			book.description,
-		)
+	VALUES ` + strings.Join(placeholders, ", ") + `
+	ON CONFLICT (isbn) DO NOTHING`
+
+		result, err := tx.Exec(query, args...)
 		if err != nil {
-			fmt.Printf("Warning: failed to insert book '%s': %v\n", book.title, err)
+			return inserted, fmt.Errorf("failed to insert sample batch %d-%d: %w", start, end, err)
+		}
+		if rows, err := result.RowsAffected(); err == nil {
+			inserted += int(rows)
 		}
 	}
 
-	fmt.Printf("Sample data inserted successfully (%d books)\n", len(sampleBooks))
-	return nil
+	return inserted, nil
 }
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInsertSampleData_Concurrent(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := Connect(databaseURL, 25, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DROP TABLE IF EXISTS books"); err != nil {
+		t.Fatalf("Failed to reset books table: %v", err)
+	}
+	if err := createBooksTable(db); err != nil {
+		t.Fatalf("Failed to create books table: %v", err)
+	}
+
+	const seedCount = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- insertSampleData(db, seedCount, 1, 50)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error from concurrent seeding: %v", err)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM books").Scan(&count); err != nil {
+		t.Fatalf("Failed to count books: %v", err)
+	}
+	if count != seedCount {
+		t.Errorf("got %d, want %d books after concurrent seeding", count, seedCount)
+	}
+}
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// sampleBook holds the column values for a seeded book
+type sampleBook struct {
+	title, author, isbn, publisher, genre, description string
+	publishYear, pages                                 int
+}
+
+// fixedSampleBooks is the curated sample set inserted into an empty database
+var fixedSampleBooks = []sampleBook{
+	{
+		title:       "The Go Programming Language",
+		author:      "Alan Donovan, Brian Kernighan",
+		isbn:        "978-0134190440",
+		publisher:   "Addison-Wesley",
+		publishYear: 2015,
+		genre:       "Programming",
+		pages:       380,
+		description: "The authoritative resource to writing clear and idiomatic Go to solve real-world problems.",
+	},
+	{
+		title:       "Clean Code",
+		author:      "Robert C. Martin",
+		isbn:        "978-0132350884",
+		publisher:   "Prentice Hall",
+		publishYear: 2008,
+		genre:       "Programming",
+		pages:       464,
+		description: "A handbook of agile software craftsmanship.",
+	},
+	{
+		title:       "Design Patterns",
+		author:      "Gang of Four",
+		isbn:        "978-0201633610",
+		publisher:   "Addison-Wesley",
+		publishYear: 1994,
+		genre:       "Programming",
+		pages:       395,
+		description: "Elements of reusable object-oriented software.",
+	},
+	{
+		title:       "The Pragmatic Programmer",
+		author:      "David Thomas, Andrew Hunt",
+		isbn:        "978-0135957059",
+		publisher:   "Addison-Wesley",
+		publishYear: 2019,
+		genre:       "Programming",
+		pages:       352,
+		description: "Your journey to mastery.",
+	},
+	{
+		title:       "Microservices Patterns",
+		author:      "Chris Richardson",
+		isbn:        "978-1617294549",
+		publisher:   "Manning Publications",
+		publishYear: 2018,
+		genre:       "Architecture",
+		pages:       520,
+		description: "With examples in Java.",
+	},
+	{
+		title:       "Building Microservices",
+		author:      "Sam Newman",
+		isbn:        "978-1491950357",
+		publisher:   "O'Reilly Media",
+		publishYear: 2015,
+		genre:       "Architecture",
+		pages:       280,
+		description: "Designing fine-grained systems.",
+	},
+	{
+		title:       "Domain-Driven Design",
+		author:      "Eric Evans",
+		isbn:        "978-0321125217",
+		publisher:   "Addison-Wesley",
+		publishYear: 2003,
+		genre:       "Architecture",
+		pages:       560,
+		description: "Tackling complexity in the heart of software.",
+	},
+	{
+		title:       "The Art of Computer Programming",
+		author:      "Donald Knuth",
+		isbn:        "978-0201896831",
+		publisher:   "Addison-Wesley",
+		publishYear: 1997,
+		genre:       "Computer Science",
+		pages:       650,
+		description: "Volume 1: Fundamental Algorithms.",
+	},
+}
+
+var (
+	seedTitleAdjectives = []string{
+		"Practical", "Advanced", "Modern", "Essential", "Applied", "Effective",
+		"Concurrent", "Distributed", "Functional", "Pragmatic", "Secure", "Scalable",
+	}
+	seedTitleNouns = []string{
+		"Algorithms", "Systems", "Databases", "Networks", "Compilers", "Patterns",
+		"Architecture", "Testing", "Cryptography", "Operating Systems", "Data Structures", "APIs",
+	}
+	seedFirstNames = []string{
+		"Ada", "Alan", "Barbara", "Brian", "Donald", "Edsger", "Frances", "Grace",
+		"John", "Ken", "Leslie", "Margaret", "Niklaus", "Radia", "Rob", "Tony",
+	}
+	seedLastNames = []string{
+		"Hopper", "Knuth", "Liskov", "Lamport", "Hamilton", "Wirth", "Dijkstra", "Kernighan",
+		"Thompson", "Pike", "Allen", "Perlman", "Hoare", "Backus", "McCarthy", "Turing",
+	}
+	seedPublishers = []string{
+		"Addison-Wesley", "O'Reilly Media", "Manning Publications", "Prentice Hall",
+		"No Starch Press", "MIT Press", "Pragmatic Bookshelf", "Apress",
+	}
+	seedGenres = []string{
+		"Programming", "Architecture", "Computer Science", "Databases",
+		"Security", "Networking", "Mathematics", "Software Engineering",
+	}
+)
+
+// buildSampleBooks returns the fixed sample set, topped up with generated books
+// when seedCount is larger than it
+func buildSampleBooks(seedCount int, seed int64) []sampleBook {
+	books := append([]sampleBook{}, fixedSampleBooks...)
+	if extra := seedCount - len(books); extra > 0 {
+		books = append(books, generateSampleBooks(extra, seed, books)...)
+	}
+	return books
+}
+
+// generateSampleBooks generates count synthetic books using a PRNG seeded with seed,
+// so the same seed always yields the same books. Generated ISBNs are valid ISBN-13s
+// and never collide with each other or with the existing books.
+func generateSampleBooks(count int, seed int64, existing []sampleBook) []sampleBook {
+	rng := rand.New(rand.NewSource(seed))
+	maxYear := time.Now().Year()
+
+	used := make(map[string]bool, len(existing)+count)
+	for _, book := range existing {
+		used[book.isbn] = true
+	}
+
+	books := make([]sampleBook, 0, count)
+	for len(books) < count {
+		isbn := randomISBN13(rng)
+		if used[isbn] {
+			continue
+		}
+		used[isbn] = true
+
+		title := fmt.Sprintf("%s %s", pick(rng, seedTitleAdjectives), pick(rng, seedTitleNouns))
+		if rng.Intn(3) == 0 {
+			title += fmt.Sprintf(", Volume %d", rng.Intn(4)+1)
+		}
+
+		books = append(books, sampleBook{
+			title:       title,
+			author:      fmt.Sprintf("%s %s", pick(rng, seedFirstNames), pick(rng, seedLastNames)),
+			isbn:        isbn,
+			publisher:   pick(rng, seedPublishers),
+			publishYear: 1960 + rng.Intn(maxYear-1960+1),
+			genre:       pick(rng, seedGenres),
+			pages:       80 + rng.Intn(1120),
+			description: fmt.Sprintf("A synthetic book about %s.", strings.ToLower(pick(rng, seedTitleNouns))),
+		})
+	}
+
+	return books
+}
+
+// randomISBN13 returns a random 978-prefixed ISBN-13 with a valid check digit,
+// formatted like the sample data (e.g. 978-0134190440)
+func randomISBN13(rng *rand.Rand) string {
+	digits := make([]byte, 0, 13)
+	digits = append(digits, '9', '7', '8')
+	for i := 0; i < 9; i++ {
+		digits = append(digits, byte('0'+rng.Intn(10)))
+	}
+	digits = append(digits, isbn13CheckDigit(digits))
+
+	return string(digits[:3]) + "-" + string(digits[3:])
+}
+
+// isbn13CheckDigit computes the ISBN-13 check digit for the first 12 digits
+func isbn13CheckDigit(digits []byte) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSampleBooks(t *testing.T) {
+	t.Run("fixed set when count is small", func(t *testing.T) {
+		books := buildSampleBooks(0, 1)
+		if len(books) != len(fixedSampleBooks) {
+			t.Errorf("got %d, want %d books", len(books), len(fixedSampleBooks))
+		}
+	})
+
+	t.Run("requested count with unique valid ISBNs", func(t *testing.T) {
+		books := buildSampleBooks(10000, 42)
+		if len(books) != 10000 {
+			t.Fatalf("got %d, want 10000 books", len(books))
+		}
+
+		seen := make(map[string]bool, len(books))
+		for _, book := range books[len(fixedSampleBooks):] {
+			if seen[book.isbn] {
+				t.Fatalf("Duplicate ISBN generated: %s", book.isbn)
+			}
+			seen[book.isbn] = true
+
+			digits := []byte(strings.ReplaceAll(book.isbn, "-", ""))
+			if len(digits) != 13 {
+				t.Fatalf("want 13 digits in %s", book.isbn)
+			}
+			if isbn13CheckDigit(digits) != digits[12] {
+				t.Fatalf("Invalid check digit in %s", book.isbn)
+			}
+			if book.publishYear < 1000 || book.pages < 1 || book.genre == "" {
+				t.Fatalf("Generated book fails validation: %+v", book)
+			}
+		}
+	})
+
+	t.Run("reproducible for the same seed", func(t *testing.T) {
+		first := buildSampleBooks(50, 7)
+		second := buildSampleBooks(50, 7)
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("got %+v and %+v, want identical books at %d", first[i], second[i], i)
+			}
+		}
+	})
+}
+
+func TestISBN13CheckDigit(t *testing.T) {
+	// Known-good ISBNs from the fixed sample set
+	for _, isbn := range []string{"9780134190440", "9780132350884", "9781617294549"} {
+		if got := isbn13CheckDigit([]byte(isbn)); got != isbn[12] {
+			t.Errorf("got %c, want check digit %c for %s", got, isbn[12], isbn)
+		}
+	}
+}
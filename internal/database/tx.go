@@ -0,0 +1,19 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying the given transaction
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored in ctx, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
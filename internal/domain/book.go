@@ -1,24 +1,185 @@
 package domain
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Default bounds for a book's publish year
+const (
+	DefaultPublishYearMin = 1000
+	DefaultPublishYearMax = 2030
 )
 
-// Book represents a book in the library
+// PublishYearRange holds the inclusive publish year bounds that validation
+// enforces. Callers pass the configured range to the request validators.
+type PublishYearRange struct {
+	Min int
+	Max int
+}
+
+// DefaultPublishYears returns the range enforced when none is configured.
+// Once DefaultPublishYearMax has passed, the max is the current year.
+func DefaultPublishYears() PublishYearRange {
+	return PublishYearRange{Min: DefaultPublishYearMin, Max: max(DefaultPublishYearMax, time.Now().Year())}
+}
+
+// check returns an error when year falls outside the range
+func (y PublishYearRange) check(year int) error {
+	if year < y.Min || year > y.Max {
+		return fmt.Errorf("publish year must be between %d and %d", y.Min, y.Max)
+	}
+	return nil
+}
+
+// ErrBookNotFound is returned, wrapped with the key that was looked up, when
+// no book matches, as in "book with ID 7 not found"
+var ErrBookNotFound = errors.New("not found")
+
+// DefaultDescriptionMaxLength is the default limit, in characters, on a
+// book's description
+const DefaultDescriptionMaxLength = 1000
+
+// ErrDescriptionTooLong is returned when a description is longer than the
+// configured limit
+var ErrDescriptionTooLong = errors.New("description is too long")
+
+// checkDescriptionLength rejects a description that is longer than max
+// characters once normalized, counting characters as the database does
+func checkDescriptionLength(description string, max int) error {
+	if utf8.RuneCountInString(normalizeDescription(description)) > max {
+		return fmt.Errorf("%w: must be at most %d characters", ErrDescriptionTooLong, max)
+	}
+	return nil
+}
+
+// NormalizeSpace trims s and collapses runs of whitespace inside it to a
+// single space, so copy-pasted values match their clean forms
+func NormalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// normalizeDescription trims a description. Line breaks and spacing inside
+// it are kept.
+func normalizeDescription(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// ToNFC returns s in Unicode NFC, so text typed with combining accents
+// matches its precomposed form
+func ToNFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// Book represents a book in the library. AccessionNumber, such as 2024-00042,
+// is assigned by the database when the book is created. DescriptionTruncated
+// is set only on list responses whose description was shortened for output.
 type Book struct {
-	ID          int       `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Author      string    `json:"author" db:"author"`
-	ISBN        string    `json:"isbn" db:"isbn"`
-	Publisher   string    `json:"publisher" db:"publisher"`
-	PublishYear int       `json:"publish_year" db:"publish_year"`
-	Genre       string    `json:"genre" db:"genre"`
-	Pages       int       `json:"pages" db:"pages"`
-	Available   bool      `json:"available" db:"available"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                   int       `json:"id,omitempty" xml:"id,omitempty" db:"id"`
+	PublicID             string    `json:"public_id,omitempty" xml:"public_id,omitempty" db:"public_id"`
+	AccessionNumber      string    `json:"accession_number,omitempty" xml:"accession_number,omitempty" db:"accession_number"`
+	Title                string    `json:"title" xml:"title" db:"title"`
+	Author               string    `json:"author" xml:"author" db:"author"`
+	ISBN                 string    `json:"isbn" xml:"isbn" db:"isbn"`
+	Publisher            string    `json:"publisher" xml:"publisher" db:"publisher"`
+	PublishYear          int       `json:"publish_year" xml:"publish_year" db:"publish_year"`
+	Genre                string    `json:"genre" xml:"genre" db:"genre"`
+	Pages                int       `json:"pages" xml:"pages" db:"pages"`
+	Available            bool      `json:"available" xml:"available" db:"available"`
+	Description          string    `json:"description" xml:"description" db:"description"`
+	DescriptionTruncated bool      `json:"description_truncated,omitempty" xml:"description_truncated,omitempty" db:"-"`
+	CreatedAt            time.Time `json:"created_at" xml:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" xml:"updated_at" db:"updated_at"`
+}
+
+// publicIDPattern matches every public ID format: UUIDs, nano IDs and
+// title slugs. It must stay within the {id} route pattern.
+var publicIDPattern = regexp.MustCompile(`^[0-9A-Za-z-]{1,100}$`)
+
+// sequentialIDPattern matches the integer IDs public IDs must not look like
+var sequentialIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// IsPublicID reports whether s is a well-formed public book ID. All-digit
+// strings are sequential IDs, never public ones.
+func IsPublicID(s string) bool {
+	return publicIDPattern.MatchString(s) && !sequentialIDPattern.MatchString(s)
+}
+
+// InLocation converts the book's timestamps to the given location for output
+func (b *Book) InLocation(loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	b.CreatedAt = b.CreatedAt.In(loc)
+	b.UpdatedAt = b.UpdatedAt.In(loc)
+}
+
+// TruncateDescription shortens the description to at most max characters,
+// ending it with an ellipsis, and flags the book as truncated. Descriptions
+// that fit, or a non-positive max, are left alone.
+func (b *Book) TruncateDescription(max int) {
+	runes := []rune(b.Description)
+	if max <= 0 || len(runes) <= max {
+		return
+	}
+	b.Description = strings.TrimRightFunc(string(runes[:max-1]), unicode.IsSpace) + "…"
+	b.DescriptionTruncated = true
+}
+
+// ETag returns a strong entity tag identifying the current state of the book
+func (b *Book) ETag() string {
+	sum := sha1.Sum([]byte(strconv.Itoa(b.ID) + ":" + strconv.FormatInt(b.UpdatedAt.UnixNano(), 10)))
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// ContentHash returns a hash of the IDs and update times of books, for
+// clients polling a list to detect changes. Books are hashed in ID order, so
+// the same books give the same hash however the page was sorted.
+func ContentHash(books []*Book) string {
+	ordered := slices.Clone(books)
+	slices.SortFunc(ordered, func(a, b *Book) int { return a.ID - b.ID })
+
+	h := sha1.New()
+	for _, book := range ordered {
+		fmt.Fprintf(h, "%d:%d\n", book.ID, book.UpdatedAt.UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Default reading speed assumptions for reading time estimates
+const (
+	DefaultWordsPerPage   = 250
+	DefaultWordsPerMinute = 250
+)
+
+// ReadingTimeMinutes estimates how long the book takes to read, rounded up to
+// whole minutes. Books without a positive page count, or non-positive
+// assumptions, estimate zero.
+func (b *Book) ReadingTimeMinutes(wordsPerPage, wordsPerMinute int) int {
+	if b.Pages <= 0 || wordsPerPage <= 0 || wordsPerMinute <= 0 {
+		return 0
+	}
+	words := b.Pages * wordsPerPage
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// ReadingTime is a book's estimated reading time and the assumptions behind it
+type ReadingTime struct {
+	Pages          int `json:"pages" xml:"pages"`
+	WordsPerPage   int `json:"words_per_page" xml:"words_per_page"`
+	WordsPerMinute int `json:"words_per_minute" xml:"words_per_minute"`
+	Minutes        int `json:"minutes" xml:"minutes"`
 }
 
 // CreateBookRequest represents the request payload for creating a book
@@ -30,7 +191,7 @@ type CreateBookRequest struct {
 	PublishYear int    `json:"publish_year" validate:"required,min=1000,max=2030"`
 	Genre       string `json:"genre" validate:"required,min=1,max=100"`
 	Pages       int    `json:"pages" validate:"required,min=1"`
-	Description string `json:"description" validate:"max=1000"`
+	Description string `json:"description"`
 }
 
 // UpdateBookRequest represents the request payload for updating a book
@@ -43,55 +204,225 @@ type UpdateBookRequest struct {
 	Genre       *string `json:"genre,omitempty" validate:"omitempty,min=1,max=100"`
 	Pages       *int    `json:"pages,omitempty" validate:"omitempty,min=1"`
 	Available   *bool   `json:"available,omitempty"`
-	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Description *string `json:"description,omitempty" validate:"omitempty"`
 }
 
-// Validate validates the CreateBookRequest
-func (r *CreateBookRequest) Validate() error {
-	if r.Title == "" {
-		return errors.New("title is required")
+// Validate validates the CreateBookRequest, with publish years bounded by
+// years and descriptions by descriptionMax characters
+func (r *CreateBookRequest) Validate(years PublishYearRange, descriptionMax int) error {
+	if failures := r.fieldFailures(years, descriptionMax); len(failures) > 0 {
+		return failures[0].err
 	}
-	if r.Author == "" {
-		return errors.New("author is required")
+	return nil
+}
+
+// FieldError describes a validation failure on a single request field
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// fieldFailure is a validation failure on a request field, keeping the
+// error so Validate can return sentinels such as ErrDescriptionTooLong
+type fieldFailure struct {
+	field string
+	err   error
+}
+
+// FieldErrors returns every validation failure in the request, in field
+// order, with publish years bounded by years and descriptions by
+// descriptionMax characters
+func (r *CreateBookRequest) FieldErrors(years PublishYearRange, descriptionMax int) []FieldError {
+	var errs []FieldError
+	for _, failure := range r.fieldFailures(years, descriptionMax) {
+		errs = append(errs, FieldError{Field: failure.field, Message: failure.err.Error()})
 	}
-	if r.ISBN == "" {
-		return errors.New("ISBN is required")
+	return errs
+}
+
+// fieldFailures returns every validation failure in the request, in field
+// order
+func (r *CreateBookRequest) fieldFailures(years PublishYearRange, descriptionMax int) []fieldFailure {
+	var failures []fieldFailure
+	if strings.TrimSpace(r.Title) == "" {
+		failures = append(failures, fieldFailure{"title", errors.New("title is required")})
+	}
+	if strings.TrimSpace(r.Author) == "" {
+		failures = append(failures, fieldFailure{"author", errors.New("author is required")})
 	}
-	if r.Publisher == "" {
-		return errors.New("publisher is required")
+	if strings.TrimSpace(r.ISBN) == "" {
+		failures = append(failures, fieldFailure{"isbn", errors.New("ISBN is required")})
 	}
-	if r.Genre == "" {
-		return errors.New("genre is required")
+	if strings.TrimSpace(r.Publisher) == "" {
+		failures = append(failures, fieldFailure{"publisher", errors.New("publisher is required")})
 	}
-	if r.PublishYear < 1000 || r.PublishYear > 2030 {
-		return errors.New("publish year must be between 1000 and 2030")
+	if strings.TrimSpace(r.Genre) == "" {
+		failures = append(failures, fieldFailure{"genre", errors.New("genre is required")})
+	}
+	if err := years.check(r.PublishYear); err != nil {
+		failures = append(failures, fieldFailure{"publish_year", err})
 	}
 	if r.Pages < 1 {
+		failures = append(failures, fieldFailure{"pages", errors.New("pages must be greater than 0")})
+	}
+	if err := checkDescriptionLength(r.Description, descriptionMax); err != nil {
+		failures = append(failures, fieldFailure{"description", err})
+	}
+	return failures
+}
+
+// Validate validates the fields present in the UpdateBookRequest, with
+// publish years bounded by years and descriptions by descriptionMax
+// characters
+func (r *UpdateBookRequest) Validate(years PublishYearRange, descriptionMax int) error {
+	if r.Title != nil && strings.TrimSpace(*r.Title) == "" {
+		return errors.New("title cannot be empty")
+	}
+	if r.Author != nil && strings.TrimSpace(*r.Author) == "" {
+		return errors.New("author cannot be empty")
+	}
+	if r.ISBN != nil && strings.TrimSpace(*r.ISBN) == "" {
+		return errors.New("ISBN cannot be empty")
+	}
+	if r.Publisher != nil && strings.TrimSpace(*r.Publisher) == "" {
+		return errors.New("publisher cannot be empty")
+	}
+	if r.Genre != nil && strings.TrimSpace(*r.Genre) == "" {
+		return errors.New("genre cannot be empty")
+	}
+	if r.PublishYear != nil {
+		if err := years.check(*r.PublishYear); err != nil {
+			return err
+		}
+	}
+	if r.Pages != nil && *r.Pages < 1 {
 		return errors.New("pages must be greater than 0")
 	}
+	if r.Description != nil {
+		return checkDescriptionLength(*r.Description, descriptionMax)
+	}
 	return nil
 }
 
-// ToBook converts CreateBookRequest to Book domain model
+// ToBook converts CreateBookRequest to Book domain model, normalizing the
+// whitespace of its text fields
 func (r *CreateBookRequest) ToBook() *Book {
-	now := time.Now()
+	now := time.Now().UTC()
 	return &Book{
-		Title:       r.Title,
-		Author:      r.Author,
-		ISBN:        r.ISBN,
-		Publisher:   r.Publisher,
+		Title:       NormalizeSpace(r.Title),
+		Author:      NormalizeSpace(r.Author),
+		ISBN:        NormalizeSpace(r.ISBN),
+		Publisher:   NormalizeSpace(r.Publisher),
 		PublishYear: r.PublishYear,
-		Genre:       r.Genre,
+		Genre:       NormalizeSpace(r.Genre),
 		Pages:       r.Pages,
 		Available:   true, // Default to available
-		Description: r.Description,
+		Description: normalizeDescription(r.Description),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
-// ApplyTo applies UpdateBookRequest changes to existing Book
+// UpdatableFields lists the book fields an UpdateBookRequest can change
+var UpdatableFields = []string{"title", "author", "isbn", "publisher", "publish_year", "genre", "pages", "available", "description"}
+
+// Normalized returns a copy of the request with the whitespace of its text
+// fields normalized as they will be stored
+func (r *UpdateBookRequest) Normalized() *UpdateBookRequest {
+	normalized := *r
+	normalized.Title = normalizeSpacePtr(r.Title)
+	normalized.Author = normalizeSpacePtr(r.Author)
+	normalized.ISBN = normalizeSpacePtr(r.ISBN)
+	normalized.Publisher = normalizeSpacePtr(r.Publisher)
+	normalized.Genre = normalizeSpacePtr(r.Genre)
+	if r.Description != nil {
+		description := normalizeDescription(*r.Description)
+		normalized.Description = &description
+	}
+	return &normalized
+}
+
+// ToNFC returns a copy of the request with its text fields in Unicode NFC
+func (r *CreateBookRequest) ToNFC() *CreateBookRequest {
+	normalized := *r
+	normalized.Title = ToNFC(r.Title)
+	normalized.Author = ToNFC(r.Author)
+	normalized.ISBN = ToNFC(r.ISBN)
+	normalized.Publisher = ToNFC(r.Publisher)
+	normalized.Genre = ToNFC(r.Genre)
+	normalized.Description = ToNFC(r.Description)
+	return &normalized
+}
+
+// ToNFC returns a copy of the request with its text fields in Unicode NFC
+func (r *UpdateBookRequest) ToNFC() *UpdateBookRequest {
+	normalized := *r
+	normalized.Title = toNFCPtr(r.Title)
+	normalized.Author = toNFCPtr(r.Author)
+	normalized.ISBN = toNFCPtr(r.ISBN)
+	normalized.Publisher = toNFCPtr(r.Publisher)
+	normalized.Genre = toNFCPtr(r.Genre)
+	normalized.Description = toNFCPtr(r.Description)
+	return &normalized
+}
+
+// toNFCPtr applies ToNFC to an optional field
+func toNFCPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	normalized := ToNFC(*s)
+	return &normalized
+}
+
+// normalizeSpacePtr applies NormalizeSpace to an optional field
+func normalizeSpacePtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	normalized := NormalizeSpace(*s)
+	return &normalized
+}
+
+// ChangedFields returns the fields the request would change on book, in
+// UpdatableFields order; fields set to their current value are not included
+func (r *UpdateBookRequest) ChangedFields(book *Book) []string {
+	r = r.Normalized()
+	var fields []string
+	if r.Title != nil && *r.Title != book.Title {
+		fields = append(fields, "title")
+	}
+	if r.Author != nil && *r.Author != book.Author {
+		fields = append(fields, "author")
+	}
+	if r.ISBN != nil && *r.ISBN != book.ISBN {
+		fields = append(fields, "isbn")
+	}
+	if r.Publisher != nil && *r.Publisher != book.Publisher {
+		fields = append(fields, "publisher")
+	}
+	if r.PublishYear != nil && *r.PublishYear != book.PublishYear {
+		fields = append(fields, "publish_year")
+	}
+	if r.Genre != nil && *r.Genre != book.Genre {
+		fields = append(fields, "genre")
+	}
+	if r.Pages != nil && *r.Pages != book.Pages {
+		fields = append(fields, "pages")
+	}
+	if r.Available != nil && *r.Available != book.Available {
+		fields = append(fields, "available")
+	}
+	if r.Description != nil && *r.Description != book.Description {
+		fields = append(fields, "description")
+	}
+	return fields
+}
+
+// ApplyTo applies UpdateBookRequest changes to existing Book, normalizing
+// the whitespace of text fields
 func (r *UpdateBookRequest) ApplyTo(book *Book) {
+	r = r.Normalized()
 	if r.Title != nil {
 		book.Title = *r.Title
 	}
@@ -119,13 +450,538 @@ func (r *UpdateBookRequest) ApplyTo(book *Book) {
 	if r.Description != nil {
 		book.Description = *r.Description
 	}
-	book.UpdatedAt = time.Now()
+	book.UpdatedAt = time.Now().UTC()
 }
 
 // BookFilter represents filtering options for books
 type BookFilter struct {
 	Author    string `json:"author,omitempty"`
 	Genre     string `json:"genre,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
 	Available *bool  `json:"available,omitempty"`
 	Search    string `json:"search,omitempty"` // Search in title, author, or description
-}
\ No newline at end of file
+	Sort      string `json:"sort,omitempty"`   // One of SortableFields; empty for the default order
+	Order     string `json:"order,omitempty"`  // "asc" or "desc"
+
+	// AfterID and Limit select a page for cursor pagination: only books with
+	// a greater ID, at most Limit of them. Zero means no bound. AfterID only
+	// makes sense with Sort "id" in ascending order.
+	AfterID int `json:"-"`
+	Limit   int `json:"-"`
+}
+
+// SortableFields lists the fields books can be explicitly sorted by
+var SortableFields = []string{"id", "title", "author", "publish_year", "pages", "created_at", "updated_at"}
+
+// Validate validates the sort options of the BookFilter
+func (f *BookFilter) Validate() error {
+	if f.Sort != "" {
+		valid := false
+		for _, field := range SortableFields {
+			if f.Sort == field {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid sort field %q", f.Sort)
+		}
+	}
+	if f.Order != "" && f.Order != "asc" && f.Order != "desc" {
+		return fmt.Errorf("invalid sort order %q: must be asc or desc", f.Order)
+	}
+	if f.AfterID < 0 || f.Limit < 0 {
+		return errors.New("cursor and limit must not be negative")
+	}
+	return nil
+}
+
+// IsEmpty reports whether the filter has no criteria set
+func (f *BookFilter) IsEmpty() bool {
+	return f == nil || (f.Author == "" && f.Genre == "" && f.Publisher == "" && f.Available == nil && f.Search == "")
+}
+
+// DefaultBatchSize is how many books large operations process per batch
+const DefaultBatchSize = 500
+
+// MaxISBNExistsBatch caps how many ISBNs one existence check may ask about
+const MaxISBNExistsBatch = 500
+
+// NormalizeISBN strips hyphens and spaces and upper-cases the X check digit,
+// so differently formatted copies of an ISBN compare equal
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+// IsISBN13 reports whether isbn, ignoring hyphens and spaces, is 13 digits
+// with a valid check digit
+func IsISBN13(isbn string) bool {
+	digits := NormalizeISBN(isbn)
+	if len(digits) != 13 {
+		return false
+	}
+	sum := 0
+	for i, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(c-'0') * weight
+	}
+	return sum%10 == 0
+}
+
+// ISBN10To13 converts a valid ISBN-10 to ISBN-13 in the catalog's
+// "978-XXXXXXXXXX" form, e.g. "0-13-235088-2" becomes "978-0132350884". It
+// returns false when isbn, ignoring hyphens and spaces, is not a valid ISBN-10.
+func ISBN10To13(isbn string) (string, bool) {
+	digits := NormalizeISBN(isbn)
+	if len(digits) != 10 {
+		return "", false
+	}
+	sum := 0
+	for i, c := range digits {
+		value := int(c - '0')
+		switch {
+		case c >= '0' && c <= '9':
+		case c == 'X' && i == 9:
+			value = 10
+		default:
+			return "", false
+		}
+		sum += value * (10 - i)
+	}
+	if sum%11 != 0 {
+		return "", false
+	}
+
+	body := "978" + digits[:9]
+	sum = 0
+	for i, c := range body {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(c-'0') * weight
+	}
+	return fmt.Sprintf("978-%s%d", digits[:9], (10-sum%10)%10), true
+}
+
+// ISBNChange replaces a book's ISBN-10 with its ISBN-13 form
+type ISBNChange struct {
+	ID   int
+	From string
+	To   string
+}
+
+// ISBNBackfillResult reports what an ISBN-13 backfill did with each book
+type ISBNBackfillResult struct {
+	Scanned   int `json:"scanned" xml:"scanned"`
+	Converted int `json:"converted" xml:"converted"`
+	// AlreadyISBN13 books had a valid ISBN-13 and were left alone
+	AlreadyISBN13 int `json:"already_isbn13" xml:"already_isbn13"`
+	// Invalid books had neither a valid ISBN-10 nor a valid ISBN-13
+	Invalid int `json:"invalid" xml:"invalid"`
+	// Conflicts were valid ISBN-10s whose ISBN-13 another book already has
+	Conflicts int `json:"conflicts" xml:"conflicts"`
+}
+
+// ISBNExistsRequest asks which of a set of ISBNs are already in the catalog
+type ISBNExistsRequest struct {
+	ISBNs []string `json:"isbns"`
+}
+
+// Validate validates the ISBNExistsRequest
+func (r *ISBNExistsRequest) Validate() error {
+	if len(r.ISBNs) == 0 {
+		return errors.New("isbns must not be empty")
+	}
+	if len(r.ISBNs) > MaxISBNExistsBatch {
+		return fmt.Errorf("at most %d isbns may be checked at once", MaxISBNExistsBatch)
+	}
+	for _, isbn := range r.ISBNs {
+		if NormalizeISBN(isbn) == "" {
+			return errors.New("isbns must not contain empty values")
+		}
+	}
+	return nil
+}
+
+// BulkUpdateRequest represents a request to change fields on all books matching a filter
+type BulkUpdateRequest struct {
+	Filter  BookFilter      `json:"filter"`
+	Changes BulkBookChanges `json:"changes"`
+	Confirm bool            `json:"confirm"`
+}
+
+// BulkBookChanges holds the fields that may be changed by a bulk update.
+// Identifying fields such as ISBN are deliberately not included.
+type BulkBookChanges struct {
+	Genre     *string `json:"genre,omitempty"`
+	Publisher *string `json:"publisher,omitempty"`
+	Available *bool   `json:"available,omitempty"`
+}
+
+// BulkUpdateResult reports how many books a bulk update matched when it
+// was checked against the confirm threshold and how many it changed. The
+// update stops at Matched books, so books that start matching mid-update
+// are left alone; Affected is lower when matched books stop matching first.
+type BulkUpdateResult struct {
+	Matched  int `json:"matched" xml:"matched"`
+	Affected int `json:"affected" xml:"affected"`
+}
+
+// Validate validates the BulkUpdateRequest
+func (r *BulkUpdateRequest) Validate() error {
+	if r.Filter.IsEmpty() {
+		return errors.New("filter must specify at least one criterion")
+	}
+	if r.Changes.Genre == nil && r.Changes.Publisher == nil && r.Changes.Available == nil {
+		return errors.New("at least one change is required")
+	}
+	if r.Changes.Genre != nil && strings.TrimSpace(*r.Changes.Genre) == "" {
+		return errors.New("genre cannot be empty")
+	}
+	if r.Changes.Publisher != nil && strings.TrimSpace(*r.Changes.Publisher) == "" {
+		return errors.New("publisher cannot be empty")
+	}
+	return nil
+}
+
+// CountMode selects how list totals are computed
+type CountMode string
+
+const (
+	// CountModeExact always runs COUNT(*)
+	CountModeExact CountMode = "exact"
+	// CountModeApproximate always uses planner estimates
+	CountModeApproximate CountMode = "approximate"
+	// CountModeFilteredExact estimates unfiltered totals and counts filtered ones exactly
+	CountModeFilteredExact CountMode = "filtered_exact"
+)
+
+// ParseCountMode parses a count mode name
+func ParseCountMode(value string) (CountMode, error) {
+	switch mode := CountMode(value); mode {
+	case CountModeExact, CountModeApproximate, CountModeFilteredExact:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid count mode %q: must be exact, approximate or filtered_exact", value)
+	}
+}
+
+// AuthorityMode selects how the author and publisher of a write are checked
+// against the authors and publishers reference tables
+type AuthorityMode string
+
+const (
+	// AuthorityModeOff does not check authorities
+	AuthorityModeOff AuthorityMode = "off"
+	// AuthorityModeStrict rejects names missing from their reference table
+	AuthorityModeStrict AuthorityMode = "strict"
+	// AuthorityModeLenient adds missing names to their reference table
+	AuthorityModeLenient AuthorityMode = "lenient"
+)
+
+// ParseAuthorityMode parses an authority mode name
+func ParseAuthorityMode(value string) (AuthorityMode, error) {
+	switch mode := AuthorityMode(value); mode {
+	case AuthorityModeOff, AuthorityModeStrict, AuthorityModeLenient:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid authority mode %q: must be off, strict or lenient", value)
+	}
+}
+
+// AuthorityKind names the reference table an authority belongs to
+type AuthorityKind string
+
+const (
+	AuthorityAuthor    AuthorityKind = "author"
+	AuthorityPublisher AuthorityKind = "publisher"
+)
+
+// ErrUnknownAuthority is returned, wrapped with the kind and name, when
+// strict authority checks find a name missing from its reference table
+var ErrUnknownAuthority = errors.New("is not a known authority")
+
+// AuthorityNameMaxLength is the longest authority name, in characters,
+// matching the book columns the names are checked against
+const AuthorityNameMaxLength = 255
+
+// Authority is a known author or publisher name. Names are unique per kind,
+// ignoring case.
+type Authority struct {
+	ID        int           `json:"id" xml:"id" db:"id"`
+	Kind      AuthorityKind `json:"kind" xml:"kind"`
+	Name      string        `json:"name" xml:"name" db:"name"`
+	CreatedAt time.Time     `json:"created_at" xml:"created_at" db:"created_at"`
+}
+
+// CreateAuthorityRequest represents the request to add a known author or
+// publisher
+type CreateAuthorityRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+// Validate validates the CreateAuthorityRequest
+func (r *CreateAuthorityRequest) Validate() error {
+	name := NormalizeSpace(r.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > AuthorityNameMaxLength {
+		return fmt.Errorf("name must be at most %d characters", AuthorityNameMaxLength)
+	}
+	return nil
+}
+
+// Default and maximum page sizes for paginated endpoints
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Default and maximum number of related books returned for a book
+const (
+	DefaultRelatedLimit = 5
+	MaxRelatedLimit     = 20
+)
+
+// AttentionMinPages is the page count below which a book is flagged as
+// needing attention
+const AttentionMinPages = 10
+
+// Reasons a book is flagged as needing attention
+const (
+	ReasonMissingDescription = "missing_description"
+	ReasonLowPageCount       = "low_page_count"
+	ReasonFuturePublishYear  = "future_publish_year"
+	ReasonMissingGenre       = "missing_genre"
+)
+
+// BookAttention is a book with data-quality issues and the reasons it was flagged
+type BookAttention struct {
+	Book    *Book    `json:"book" xml:"book"`
+	Reasons []string `json:"reasons" xml:"reasons>reason"`
+}
+
+// Pagination represents offset/limit paging options
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// Normalize applies defaults and bounds to the pagination options
+func (p *Pagination) Normalize() {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+}
+
+// AuthorCount represents an author and the number of books by them
+type AuthorCount struct {
+	Author string `json:"author" xml:"author" db:"author"`
+	Count  int    `json:"count" xml:"count" db:"count"`
+}
+
+// GenreCount represents a genre and the number of books in it
+type GenreCount struct {
+	Genre string `json:"genre" xml:"genre" db:"genre"`
+	Count int    `json:"count" xml:"count" db:"count"`
+}
+
+// PublisherCount represents a publisher and the number of books they published
+type PublisherCount struct {
+	Publisher string `json:"publisher" xml:"publisher" db:"publisher"`
+	Count     int    `json:"count" xml:"count" db:"count"`
+}
+
+// DistinctFields lists the fields whose distinct values can be listed
+var DistinctFields = []string{"genre", "author", "publisher", "publish_year"}
+
+// ValidateDistinctField checks that field is one of DistinctFields
+func ValidateDistinctField(field string) error {
+	for _, f := range DistinctFields {
+		if field == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid field %q: must be one of %s", field, strings.Join(DistinctFields, ", "))
+}
+
+// DistinctValue represents a distinct value of a book field and the number
+// of books with it. Numeric fields are reported as their decimal text.
+type DistinctValue struct {
+	Value string `json:"value" xml:"value" db:"value"`
+	Count int    `json:"count" xml:"count" db:"count"`
+}
+
+// GenreStats represents the availability breakdown of books in a genre
+type GenreStats struct {
+	Genre      string `json:"genre" xml:"genre" db:"genre"`
+	Total      int    `json:"total" xml:"total" db:"total"`
+	Available  int    `json:"available" xml:"available" db:"available"`
+	CheckedOut int    `json:"checked_out" xml:"checked_out" db:"checked_out"`
+}
+
+// PageStats summarizes the page counts of a set of books. The min, max,
+// average and median are nil when no books match.
+type PageStats struct {
+	Books      int      `json:"books" xml:"books"`
+	TotalPages int      `json:"total_pages" xml:"total_pages"`
+	MinPages   *int     `json:"min_pages" xml:"min_pages,omitempty"`
+	MaxPages   *int     `json:"max_pages" xml:"max_pages,omitempty"`
+	Average    *float64 `json:"average_pages" xml:"average_pages,omitempty"`
+	Median     *float64 `json:"median_pages" xml:"median_pages,omitempty"`
+}
+
+// GrowthInterval is the bucket size of catalog growth stats
+type GrowthInterval string
+
+const (
+	GrowthIntervalDay   GrowthInterval = "day"
+	GrowthIntervalWeek  GrowthInterval = "week"
+	GrowthIntervalMonth GrowthInterval = "month"
+)
+
+// ParseGrowthInterval parses a growth interval name
+func ParseGrowthInterval(value string) (GrowthInterval, error) {
+	switch interval := GrowthInterval(value); interval {
+	case GrowthIntervalDay, GrowthIntervalWeek, GrowthIntervalMonth:
+		return interval, nil
+	default:
+		return "", fmt.Errorf("invalid interval %q: must be day, week or month", value)
+	}
+}
+
+// Truncate returns the start of the bucket holding t, in UTC. Weeks start on
+// Monday, matching PostgreSQL's date_trunc.
+func (i GrowthInterval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch i {
+	case GrowthIntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case GrowthIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the bucket after the one starting at start
+func (i GrowthInterval) Next(start time.Time) time.Time {
+	switch i {
+	case GrowthIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case GrowthIntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// MaxGrowthBuckets caps how many buckets one growth query may span
+const MaxGrowthBuckets = 1000
+
+// GrowthQuery selects the books created from From to To, both inclusive,
+// counted per Interval. The range is widened to whole buckets.
+type GrowthQuery struct {
+	Interval GrowthInterval
+	From     time.Time
+	To       time.Time
+}
+
+// Validate checks the interval and that the range is ordered and spans at
+// most MaxGrowthBuckets buckets
+func (q *GrowthQuery) Validate() error {
+	if _, err := ParseGrowthInterval(string(q.Interval)); err != nil {
+		return err
+	}
+	if q.To.Before(q.From) {
+		return errors.New("from must not be after to")
+	}
+	buckets := 0
+	for start := q.Start(); start.Before(q.End()); start = q.Interval.Next(start) {
+		if buckets++; buckets > MaxGrowthBuckets {
+			return fmt.Errorf("range spans more than %d %s buckets", MaxGrowthBuckets, q.Interval)
+		}
+	}
+	return nil
+}
+
+// Start returns the start of the first bucket
+func (q *GrowthQuery) Start() time.Time {
+	return q.Interval.Truncate(q.From)
+}
+
+// End returns the exclusive end of the last bucket
+func (q *GrowthQuery) End() time.Time {
+	return q.Interval.Next(q.Interval.Truncate(q.To))
+}
+
+// GrowthBucket is the number of books created in the bucket beginning at Start
+type GrowthBucket struct {
+	Start time.Time `json:"start" xml:"start"`
+	Books int       `json:"books" xml:"books"`
+}
+
+// CatalogGrowth is the number of books created per bucket over a range, with
+// a bucket for every interval in the range
+type CatalogGrowth struct {
+	Interval GrowthInterval  `json:"interval" xml:"interval"`
+	Total    int             `json:"total" xml:"total"`
+	Buckets  []*GrowthBucket `json:"buckets" xml:"bucket"`
+}
+
+// FillGrowthGaps returns one bucket per interval of q, taking counts from
+// buckets and zero for intervals it lacks
+func FillGrowthGaps(q *GrowthQuery, buckets []*GrowthBucket) []*GrowthBucket {
+	counts := make(map[time.Time]int, len(buckets))
+	for _, bucket := range buckets {
+		counts[q.Interval.Truncate(bucket.Start)] += bucket.Books
+	}
+
+	filled := []*GrowthBucket{}
+	for start := q.Start(); start.Before(q.End()); start = q.Interval.Next(start) {
+		filled = append(filled, &GrowthBucket{Start: start, Books: counts[start]})
+	}
+	return filled
+}
+
+// ExportFormat is the file format of a catalog export
+type ExportFormat string
+
+const (
+	// ExportFormatJSON writes the catalog as a JSON array of books
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatCSV writes the catalog as CSV with a header row
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+// ParseExportFormat parses an export format name
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch format := ExportFormat(value); format {
+	case ExportFormatJSON, ExportFormatCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid export format %q: must be json or csv", value)
+	}
+}
+
+// ExportResult describes a catalog export written to storage
+type ExportResult struct {
+	Key    string       `json:"key" xml:"key"`
+	Size   int64        `json:"size" xml:"size"`
+	Format ExportFormat `json:"format" xml:"format"`
+}
@@ -0,0 +1,284 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBook_ReadingTimeMinutes(t *testing.T) {
+	tests := []struct {
+		name           string
+		pages          int
+		wordsPerPage   int
+		wordsPerMinute int
+		expected       int
+	}{
+		{"one page per minute", 300, 250, 250, 300},
+		{"rounds up partial minutes", 3, 100, 250, 2},
+		{"single page", 1, 250, 300, 1},
+		{"fast reader", 464, 250, 500, 232},
+		{"zero pages", 0, 250, 250, 0},
+		{"negative pages", -10, 250, 250, 0},
+		{"invalid assumptions", 100, 0, 250, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Pages: tt.pages}
+			if got := book.ReadingTimeMinutes(tt.wordsPerPage, tt.wordsPerMinute); got != tt.expected {
+				t.Errorf("got %d, want %d minutes", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBook_TruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		max         int
+		expected    string
+		truncated   bool
+	}{
+		{"fits", "Short", 10, "Short", false},
+		{"exact length", "0123456789", 10, "0123456789", false},
+		{"too long", "Patterns of enterprise design", 10, "Patterns…", true},
+		{"trailing space trimmed", "Clean code, clean mind", 7, "Clean…", true},
+		{"counts characters not bytes", "Ünïcödé text", 5, "Ünïc…", true},
+		{"disabled", "Patterns of enterprise design", 0, "Patterns of enterprise design", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Description: tt.description}
+			book.TruncateDescription(tt.max)
+			if book.Description != tt.expected || book.DescriptionTruncated != tt.truncated {
+				t.Errorf("got %q (truncated %v), want %q (truncated %v)", book.Description, book.DescriptionTruncated, tt.expected, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestCreateBookRequest_ToBookNormalizes(t *testing.T) {
+	req := &CreateBookRequest{
+		Title:       "  Clean \t Code ",
+		Author:      "Robert  C. Martin",
+		ISBN:        " 978-0132350884 ",
+		Description: "  First line\n\nSecond line  ",
+	}
+
+	book := req.ToBook()
+	if book.Title != "Clean Code" || book.Author != "Robert C. Martin" || book.ISBN != "978-0132350884" {
+		t.Errorf("got %q, %q, %q, want collapsed whitespace", book.Title, book.Author, book.ISBN)
+	}
+	if book.Description != "First line\n\nSecond line" {
+		t.Errorf("got %q, want description trimmed with line breaks kept", book.Description)
+	}
+
+	decomposed := "Caf" + "e\u0301"
+	if got := (&CreateBookRequest{Title: decomposed}).ToBook().Title; got != decomposed {
+		t.Errorf("got %q, want Unicode left alone by default", got)
+	}
+
+	if got := (&CreateBookRequest{Title: decomposed}).ToNFC().ToBook().Title; got != "Caf\u00e9" {
+		t.Errorf("got %q, want NFC title", got)
+	}
+	description := decomposed
+	if got := (&UpdateBookRequest{Description: &description}).ToNFC().Description; *got != "Caf\u00e9" || description != decomposed {
+		t.Errorf("got %q from %q, want an NFC copy of the update", *got, description)
+	}
+}
+
+func TestCreateBookRequest_DescriptionMaxLength(t *testing.T) {
+	req := &CreateBookRequest{
+		Title:       "Clean Code",
+		Author:      "Robert C. Martin",
+		ISBN:        "978-0132350884",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+		Genre:       "Programming",
+		Pages:       464,
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		valid       bool
+	}{
+		{"empty", "", true},
+		{"at limit", "abcde", true},
+		{"at limit once trimmed", "  abcde\n", true},
+		{"counts characters not bytes", "ééééé", true},
+		{"over limit", "abcdef", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req.Description = tt.description
+			err := req.Validate(DefaultPublishYears(), 5)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrDescriptionTooLong) {
+				t.Errorf("got %v, want ErrDescriptionTooLong", err)
+			}
+
+			update := &UpdateBookRequest{Description: &tt.description}
+			if err := update.Validate(DefaultPublishYears(), 5); (err == nil) != tt.valid {
+				t.Errorf("got %v, want update valid=%v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestUpdateBookRequest_Normalized(t *testing.T) {
+	title := " Clean  Code "
+	description := " Notes\n"
+	req := &UpdateBookRequest{Title: &title, Description: &description}
+
+	book := &Book{Title: "Clean Code", Description: "Notes"}
+	if changed := req.ChangedFields(book); len(changed) != 0 {
+		t.Errorf("got %v, want no changes after normalization", changed)
+	}
+
+	normalized := req.Normalized()
+	if *normalized.Title != "Clean Code" || *normalized.Description != "Notes" {
+		t.Errorf("got %q, %q, want normalized copy", *normalized.Title, *normalized.Description)
+	}
+	if title != " Clean  Code " {
+		t.Error("want the original request to be left unchanged")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	first := &Book{ID: 1, UpdatedAt: updated}
+	second := &Book{ID: 2, UpdatedAt: updated}
+
+	hash := ContentHash([]*Book{first, second})
+	if got := ContentHash([]*Book{second, first}); got != hash {
+		t.Errorf("got %q and %q, want the hash not to depend on order", hash, got)
+	}
+	if got := ContentHash([]*Book{first}); got == hash {
+		t.Error("want a different set of books to hash differently")
+	}
+
+	second.UpdatedAt = updated.Add(time.Second)
+	if got := ContentHash([]*Book{first, second}); got == hash {
+		t.Error("want the hash to change when a book is updated")
+	}
+	if ContentHash(nil) == "" {
+		t.Error("want an empty list to have a hash")
+	}
+}
+
+func TestGrowthInterval_Truncate(t *testing.T) {
+	at := time.Date(2024, 2, 29, 15, 30, 0, 0, time.FixedZone("EST", -5*60*60)) // 20:30 UTC, a Thursday
+
+	tests := []struct {
+		interval GrowthInterval
+		expected time.Time
+	}{
+		{GrowthIntervalDay, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{GrowthIntervalWeek, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		{GrowthIntervalMonth, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.interval), func(t *testing.T) {
+			if got := tt.interval.Truncate(at); !got.Equal(tt.expected) {
+				t.Errorf("got %s, want %s", got, tt.expected)
+			}
+		})
+	}
+
+	sunday := time.Date(2024, 3, 3, 23, 0, 0, 0, time.UTC)
+	if got := GrowthIntervalWeek.Truncate(sunday); !got.Equal(time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s, want Sunday in the week from Monday 26 February", got)
+	}
+}
+
+func TestFillGrowthGaps(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	query := &GrowthQuery{Interval: GrowthIntervalWeek, From: day(3), To: day(24)}
+
+	filled := FillGrowthGaps(query, []*GrowthBucket{
+		{Start: day(8), Books: 4},
+		{Start: day(22), Books: 1},
+	})
+
+	// The range widens to whole weeks: Monday 1 January to Sunday 28 January
+	expected := []GrowthBucket{{day(1), 0}, {day(8), 4}, {day(15), 0}, {day(22), 1}}
+	if len(filled) != len(expected) {
+		t.Fatalf("got %d, want %d buckets", len(filled), len(expected))
+	}
+	for i, want := range expected {
+		if !filled[i].Start.Equal(want.Start) || filled[i].Books != want.Books {
+			t.Errorf("Bucket %d: got %+v, want %+v", i, *filled[i], want)
+		}
+	}
+}
+
+func TestGrowthQuery_Validate(t *testing.T) {
+	day := func(y, m, d int) time.Time { return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		query GrowthQuery
+		valid bool
+	}{
+		{"year by month", GrowthQuery{GrowthIntervalMonth, day(2023, 1, 1), day(2023, 12, 31)}, true},
+		{"single day", GrowthQuery{GrowthIntervalDay, day(2024, 1, 1), day(2024, 1, 1)}, true},
+		{"from after to", GrowthQuery{GrowthIntervalDay, day(2024, 1, 2), day(2024, 1, 1)}, false},
+		{"unknown interval", GrowthQuery{"year", day(2020, 1, 1), day(2024, 1, 1)}, false},
+		{"too many buckets", GrowthQuery{GrowthIntervalDay, day(2000, 1, 1), day(2024, 1, 1)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); (err == nil) != tt.valid {
+				t.Errorf("got error %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestISBN10To13(t *testing.T) {
+	tests := []struct {
+		isbn   string
+		want   string
+		wantOK bool
+	}{
+		{"0132350882", "978-0132350884", true},
+		{"0-306-40615-2", "978-0306406157", true},
+		{"0-8044-2957-x", "978-0804429573", true},
+		{"0132350883", "", false}, // bad check digit
+		{"X132350882", "", false}, // X only as the check digit
+		{"978-0132350884", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ISBN10To13(tt.isbn)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ISBN10To13(%q) = %q, %v, want %q, %v", tt.isbn, got, ok, tt.want, tt.wantOK)
+		}
+		if ok && !IsISBN13(got) {
+			t.Errorf("ISBN10To13(%q) = %q, which is not a valid ISBN-13", tt.isbn, got)
+		}
+	}
+}
+
+func TestIsISBN13(t *testing.T) {
+	for _, isbn := range []string{"978-0132350884", "9780306406157", "978 0 8044 2957 3"} {
+		if !IsISBN13(isbn) {
+			t.Errorf("want %q to be a valid ISBN-13", isbn)
+		}
+	}
+	for _, isbn := range []string{"978-0132350885", "0132350882", "978-013235088X", ""} {
+		if IsISBN13(isbn) {
+			t.Errorf("want %q not to be a valid ISBN-13", isbn)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldSchema describes one book field for clients that build forms. For
+// strings Min and Max bound the length; for integers they bound the value.
+type FieldSchema struct {
+	Name       string   `json:"name" xml:"name"`
+	Type       string   `json:"type" xml:"type"`
+	Format     string   `json:"format,omitempty" xml:"format,omitempty"`
+	Required   bool     `json:"required" xml:"required"`
+	Min        *int     `json:"min,omitempty" xml:"min,omitempty"`
+	Max        *int     `json:"max,omitempty" xml:"max,omitempty"`
+	Enum       []string `json:"enum,omitempty" xml:"enum,omitempty"`
+	Filterable bool     `json:"filterable" xml:"filterable"`
+	Sortable   bool     `json:"sortable" xml:"sortable"`
+}
+
+// BookSchema describes the fields a client can set on a book. It is derived
+// from the json and validate tags of the request types, so it follows them
+// as they change; publish_year reports years and description descriptionMax.
+func BookSchema(years PublishYearRange, descriptionMax int) []FieldSchema {
+	createFields := make(map[string]reflect.StructField)
+	createType := reflect.TypeOf(CreateBookRequest{})
+	for i := 0; i < createType.NumField(); i++ {
+		field := createType.Field(i)
+		createFields[jsonName(field)] = field
+	}
+
+	filterable := make(map[string]bool)
+	filterType := reflect.TypeOf(BookFilter{})
+	for i := 0; i < filterType.NumField(); i++ {
+		filterable[jsonName(filterType.Field(i))] = true
+	}
+
+	updateType := reflect.TypeOf(UpdateBookRequest{})
+	fields := make([]FieldSchema, 0, updateType.NumField())
+	for i := 0; i < updateType.NumField(); i++ {
+		field := updateType.Field(i)
+		name := jsonName(field)
+
+		// Constraints come from the create request when the field is there,
+		// since it also carries whether the field is required
+		tag := field.Tag.Get("validate")
+		if createField, ok := createFields[name]; ok {
+			tag = createField.Tag.Get("validate")
+		}
+
+		schema := FieldSchema{
+			Name:       name,
+			Type:       schemaType(field.Type),
+			Filterable: filterable[name],
+			Sortable:   slices.Contains(SortableFields, name),
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				schema.Required = true
+			case "isbn":
+				schema.Format = "isbn"
+			case "min":
+				if n, err := strconv.Atoi(value); err == nil {
+					schema.Min = &n
+				}
+			case "max":
+				if n, err := strconv.Atoi(value); err == nil {
+					schema.Max = &n
+				}
+			}
+		}
+		if name == "publish_year" {
+			min, max := years.Min, years.Max
+			schema.Min, schema.Max = &min, &max
+		}
+		if name == "description" {
+			schema.Max = &descriptionMax
+		}
+		fields = append(fields, schema)
+	}
+	return fields
+}
+
+// jsonName returns the JSON key of a struct field
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// schemaType maps a Go field type to the JSON type clients see
+func schemaType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "integer"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"library-management/internal/domain"
+)
+
+// unsafeArchiveName matches characters not kept in archive file names
+var unsafeArchiveName = regexp.MustCompile(`[^0-9A-Za-z-]+`)
+
+// GetBooksArchive handles GET /api/v1/books/archive, streaming the books
+// that match the list filters as a ZIP archive holding one JSON file per
+// book, named by ISBN. Books are written as they are read, so memory use
+// does not grow with the catalog; no matches give an empty archive.
+func (h *BookHandler) GetBooksArchive(w http.ResponseWriter, r *http.Request) {
+	filter := h.parseBookFilter(r)
+	if err := h.validateFilter(filter); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	out := &countingWriter{w: w}
+	zw := zip.NewWriter(out)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="books.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	err := h.service.StreamBooks(r.Context(), filter, func(book *domain.Book) error {
+		name := archiveFileName(book)
+		h.presentBook(r, book)
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(book)
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	h.logger.Error("Failed to archive books", "error", err)
+	// Once part of the archive is sent the status cannot change; the
+	// truncated archive fails to open instead
+	if out.n == 0 {
+		w.Header().Del("Content-Disposition")
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to archive books")
+	}
+}
+
+// archiveFileName names a book's file in the archive after its ISBN, falling
+// back to its ID when the ISBN has no usable characters
+func archiveFileName(book *domain.Book) string {
+	if unsafeArchiveName.ReplaceAllString(book.ISBN, "") == "" {
+		return "book-" + strconv.Itoa(book.ID) + ".json"
+	}
+	return unsafeArchiveName.ReplaceAllString(book.ISBN, "_") + ".json"
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
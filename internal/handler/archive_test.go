@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"library-management/internal/config"
+	"library-management/internal/domain"
+)
+
+func TestBookHandler_GetBooksArchive(t *testing.T) {
+	refactoring := sampleBook()
+	refactoring.ID = 2
+	refactoring.Title = "Refactoring"
+	refactoring.ISBN = "978-0201485677"
+	refactoring.Genre = "Software Engineering"
+	router := newTestRouter(newStubBookService(sampleBook(), refactoring), &config.Config{})
+
+	archive := func(target string) map[string]domain.Book {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+			t.Errorf("got %q, want Content-Type application/zip", ct)
+		}
+		if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="books.zip"` {
+			t.Errorf("Unexpected Content-Disposition %q", cd)
+		}
+
+		body := rec.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		books := make(map[string]domain.Book)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", f.Name, err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", f.Name, err)
+			}
+			var book domain.Book
+			if err := json.Unmarshal(data, &book); err != nil {
+				t.Fatalf("Failed to decode %s: %v", f.Name, err)
+			}
+			books[f.Name] = book
+		}
+		return books
+	}
+
+	t.Run("one file per book named by ISBN", func(t *testing.T) {
+		books := archive("/api/v1/books/archive")
+		if len(books) != 2 {
+			t.Fatalf("got %d, want 2 files", len(books))
+		}
+		if book := books["978-0132350884.json"]; book.Title != "Clean Code" || book.ID != 1 {
+			t.Errorf("Unexpected Clean Code entry %+v", book)
+		}
+		if book := books["978-0201485677.json"]; book.Title != "Refactoring" {
+			t.Errorf("Unexpected Refactoring entry %+v", book)
+		}
+	})
+
+	t.Run("filters apply", func(t *testing.T) {
+		books := archive("/api/v1/books/archive?genre=programming")
+		if len(books) != 1 || books["978-0132350884.json"].Title != "Clean Code" {
+			t.Errorf("got %v, want only Clean Code", books)
+		}
+	})
+
+	t.Run("no matches give an empty archive", func(t *testing.T) {
+		if books := archive("/api/v1/books/archive?genre=poetry"); len(books) != 0 {
+			t.Errorf("got %v, want an empty archive", books)
+		}
+	})
+}
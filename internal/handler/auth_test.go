@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+)
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// adminKey is the key adminConfig accepts with the admin role, for tests of
+// admin routes
+const adminKey = "admin-key"
+
+func adminConfig() *config.Config {
+	return &config.Config{APIKeys: []config.APIKey{{Hash: hashAPIKey(adminKey), Role: RoleAdmin}}}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	cfg := &config.Config{APIKeys: []config.APIKey{
+		{Hash: hashAPIKey("editor-key")},
+		{Hash: hashAPIKey("admin-key"), Role: RoleAdmin},
+	}}
+
+	send := func(cfg *config.Config, method, path, key string) int {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		var body *strings.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(`{"filter":{"genre":"Programming"},"changes":{"available":false}}`)
+		} else {
+			body = strings.NewReader("")
+		}
+		req := httptest.NewRequest(method, path, body)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{"valid key", cfg, http.MethodDelete, "/api/v1/books/1", "editor-key", http.StatusOK},
+		{"invalid key", cfg, http.MethodDelete, "/api/v1/books/1", "wrong-key", http.StatusUnauthorized},
+		{"missing key", cfg, http.MethodDelete, "/api/v1/books/1", "", http.StatusUnauthorized},
+		{"reads stay public", cfg, http.MethodGet, "/api/v1/books/1", "", http.StatusOK},
+		{"role required", cfg, http.MethodPost, "/api/v1/books/bulk-update", "editor-key", http.StatusForbidden},
+		{"role granted", cfg, http.MethodPost, "/api/v1/books/bulk-update", "admin-key", http.StatusOK},
+		{"no keys configured", &config.Config{}, http.MethodDelete, "/api/v1/books/1", "", http.StatusOK},
+		{"bulk update fails closed", &config.Config{}, http.MethodPost, "/api/v1/books/bulk-update", "", http.StatusForbidden},
+		{"export fails closed", &config.Config{}, http.MethodPost, "/api/v1/admin/export", "", http.StatusForbidden},
+		{"isbn backfill fails closed", &config.Config{}, http.MethodPost, "/api/v1/admin/isbn-backfill", "", http.StatusForbidden},
+		{"jobs fail closed", &config.Config{}, http.MethodGet, "/api/v1/admin/jobs", "", http.StatusForbidden},
+		{"authorities fail closed", &config.Config{}, http.MethodPost, "/api/v1/admin/authorities/authors", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := send(tt.cfg, tt.method, tt.path, tt.key); code != tt.expected {
+				t.Errorf("status = %d, want %d", code, tt.expected)
+			}
+		})
+	}
+}
@@ -1,164 +1,520 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
+	"library-management/internal/citation"
+	"library-management/internal/config"
 	"library-management/internal/domain"
+	"library-management/internal/jobs"
 	"library-management/internal/service"
 	"library-management/pkg/logger"
 )
 
+// DatabaseChecker reports database connectivity and connection pool statistics
+// and starts the transactions used by transactional routes
+type DatabaseChecker interface {
+	TxBeginner
+	PingContext(ctx context.Context) error
+	Stats() sql.DBStats
+}
+
 type BookHandler struct {
 	service service.BookService
+	db      DatabaseChecker
 	logger  logger.Logger
+	config  *config.Config
+	// errors tracks the recent 5xx rate for /ready; nil when disabled
+	errors *errorRate
+	// jobs limits concurrent catalog exports; nil runs them unlimited
+	jobs *jobs.Limiter
+	// searches counts list search terms; nil when recording is disabled
+	searches *searchTerms
+	// dbQueue bounds requests waiting for a database connection; nil when
+	// DB_QUEUE_SIZE is zero
+	dbQueue *dbQueue
+	// sizes records response body sizes for /metrics; nil when disabled
+	sizes *responseSizes
 }
 
 type Handlers struct {
 	Book *BookHandler
 }
 
+// Option configures optional handler behaviour
+type Option func(*BookHandler)
+
+// WithJobs runs catalog exports through limiter, so they share its slots
+// with other heavy operations and can be followed on the jobs endpoints
+func WithJobs(limiter *jobs.Limiter) Option {
+	return func(h *BookHandler) {
+		h.jobs = limiter
+	}
+}
+
 // NewHandlers creates a new handlers instance
-func NewHandlers(bookService service.BookService, log logger.Logger) *Handlers {
-	return &Handlers{
-		Book: &BookHandler{
-			service: bookService,
-			logger:  log,
-		},
+func NewHandlers(bookService service.BookService, db DatabaseChecker, log logger.Logger, cfg *config.Config, opts ...Option) *Handlers {
+	book := &BookHandler{
+		service: bookService,
+		db:      db,
+		logger:  log,
+		config:  cfg,
+	}
+	if cfg != nil && cfg.ErrorRateThreshold > 0 {
+		book.errors = newErrorRate(cfg.ErrorRateWindow)
+	}
+	if cfg != nil && cfg.RecordSearchTerms {
+		book.searches = newSearchTerms(cfg.SearchTermsMax)
 	}
+	if cfg != nil && cfg.DatabaseQueueSize > 0 {
+		book.dbQueue = newDBQueue(cfg.DatabaseMaxOpenConns, cfg.DatabaseQueueSize, cfg.DatabaseQueueTimeout)
+	}
+	if cfg != nil && cfg.ResponseSizeMetrics {
+		book.sizes = newResponseSizes(cfg.ResponseSizeBuckets)
+	}
+	for _, opt := range opts {
+		opt(book)
+	}
+	return &Handlers{Book: book}
 }
 
 // Response represents a standard API response
 type Response struct {
-	Status  string      `json:"status"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Status  string      `json:"status" xml:"status"`
+	Message string      `json:"message,omitempty" xml:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty" xml:"-"`
+	Error   string      `json:"error,omitempty" xml:"error,omitempty"`
+	// Warnings flag possible problems with a request that still succeeded
+	Warnings []string `json:"warnings,omitempty" xml:"-"`
 }
 
 // CreateBook handles POST /api/v1/books
 func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateBookRequest
 	
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid JSON payload")
+	if !h.decodeRequest(w, r, &req, false) {
 		return
 	}
 
 	book, err := h.service.CreateBook(r.Context(), &req)
 	if err != nil {
-		h.logger.Error("Failed to create book", "error", err)
-		h.respondError(w, http.StatusBadRequest, err.Error())
+		h.logRequestError("Failed to create book", err)
+		if !isClientError(err) {
+			h.respondError(w, r, http.StatusInternalServerError, "Failed to create book")
+			return
+		}
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrDescriptionTooLong) || errors.Is(err, domain.ErrUnknownAuthority) {
+			status = http.StatusUnprocessableEntity
+		}
+		h.respondFieldErrors(w, r, status, err.Error(), req.FieldErrors(h.publishYears(), h.descriptionMaxLength()))
 		return
 	}
 
-	h.respondSuccess(w, http.StatusCreated, "Book created successfully", book)
+	warnings := append(h.duplicateWarnings(r, book), h.publishYearWarnings(book.PublishYear)...)
+	warnings = append(warnings, h.genreWarnings(req.Genre, book)...)
+
+	h.presentBook(r, book)
+	h.respond(w, r, http.StatusCreated, Response{
+		Status:   "success",
+		Message:  "Book created successfully",
+		Data:     book,
+		Warnings: warnings,
+	})
+}
+
+// duplicateWarnings notes other books with the same title and author as
+// book when WARN_DUPLICATE_TITLES is on. The check never fails the request.
+func (h *BookHandler) duplicateWarnings(r *http.Request, book *domain.Book) []string {
+	if h.config == nil || !h.config.WarnDuplicateTitles {
+		return nil
+	}
+
+	duplicates, err := h.service.FindDuplicateBooks(r.Context(), book)
+	if err != nil {
+		h.logger.Warn("Failed to check for duplicate books", "error", err, "id", book.ID)
+		return nil
+	}
+
+	var warnings []string
+	for _, duplicate := range duplicates {
+		warnings = append(warnings, fmt.Sprintf("Possible duplicate: book with ISBN %s has the same title and author", duplicate.ISBN))
+	}
+	return warnings
+}
+
+// publishYears returns the configured publish year range. A config without
+// one, where PUBLISH_YEAR_MAX is zero, gets the default range.
+func (h *BookHandler) publishYears() domain.PublishYearRange {
+	if h.config == nil || h.config.PublishYearMax == 0 {
+		return domain.DefaultPublishYears()
+	}
+	return h.config.PublishYears()
+}
+
+// descriptionMaxLength returns the configured description limit, or the
+// default for a config without one
+func (h *BookHandler) descriptionMaxLength() int {
+	if h.config == nil || h.config.DescriptionMaxLength == 0 {
+		return domain.DefaultDescriptionMaxLength
+	}
+	return h.config.DescriptionMaxLength
+}
+
+// publishYearWarnings notes a publish year after the current year when
+// WARN_FUTURE_PUBLISH_YEAR is on; such years are allowed but often typos
+func (h *BookHandler) publishYearWarnings(year int) []string {
+	if h.config == nil || !h.config.WarnFuturePublishYear {
+		return nil
+	}
+	if current := time.Now().Year(); year > current {
+		return []string{fmt.Sprintf("publish_year %d is after the current year %d; check for a typo", year, current)}
+	}
+	return nil
+}
+
+// genreWarnings reports a genre the service normalized through
+// GENRE_ALIASES, so clients learn the canonical form
+func (h *BookHandler) genreWarnings(sent string, book *domain.Book) []string {
+	if domain.ToNFC(domain.NormalizeSpace(sent)) == domain.ToNFC(book.Genre) {
+		return nil
+	}
+	h.logger.Info("Genre normalized", "id", book.ID, "from", sent, "to", book.Genre)
+	return []string{fmt.Sprintf("genre %q was normalized to %q", sent, book.Genre)}
+}
+
+// ValidateBook handles POST /api/v1/books/validate, running create
+// validation without saving the book
+func (h *BookHandler) ValidateBook(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateBookRequest
+
+	if !h.decodeRequest(w, r, &req, false) {
+		return
+	}
+
+	errs := h.service.ValidateBook(r.Context(), &req)
+	if errs == nil {
+		errs = []domain.FieldError{}
+	}
+
+	message := "Book is valid"
+	if len(errs) > 0 {
+		message = "Book has validation errors"
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, message, map[string]interface{}{
+		"valid":  len(errs) == 0,
+		"errors": errs,
+	})
+}
+
+// GetBookSchema handles GET /api/v1/books/schema, describing the book
+// fields so clients can build forms that match server-side validation
+func (h *BookHandler) GetBookSchema(w http.ResponseWriter, r *http.Request) {
+	h.respondSuccess(w, r, http.StatusOK, "Book schema retrieved successfully", map[string]interface{}{
+		"fields": domain.BookSchema(h.publishYears(), h.descriptionMaxLength()),
+	})
 }
 
 // GetBook handles GET /api/v1/books/{id}
 func (h *BookHandler) GetBook(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, ok := h.bookID(w, r)
+	if !ok {
+		return
+	}
+
+	book, err := h.service.GetBookByID(r.Context(), id)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book", err, "id", id)
+		return
+	}
+
+	w.Header().Set("ETag", book.ETag())
+	h.presentBook(r, book)
+	h.respondSuccess(w, r, http.StatusOK, "Book retrieved successfully", book)
+}
+
+// GetRandomBook handles GET /api/v1/books/random. Only available books are
+// considered unless available=false or available=any is given.
+func (h *BookHandler) GetRandomBook(w http.ResponseWriter, r *http.Request) {
+	filter := &domain.BookFilter{Genre: r.URL.Query().Get("genre")}
+
+	available := true
+	filter.Available = &available
+	switch value := r.URL.Query().Get("available"); value {
+	case "":
+	case "any":
+		filter.Available = nil
+	default:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondError(w, r, http.StatusBadRequest, "available must be true, false or any")
+			return
+		}
+		filter.Available = &parsed
+	}
+
+	book, err := h.service.GetRandomBook(r.Context(), filter)
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid book ID")
+		h.logger.Error("Failed to get random book", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve random book")
+		return
+	}
+	if book == nil {
+		h.respondError(w, r, http.StatusNotFound, "No matching books")
+		return
+	}
+
+	// A random pick must not be served from cache
+	w.Header().Set("Cache-Control", "no-store")
+	h.presentBook(r, book)
+	h.respondSuccess(w, r, http.StatusOK, "Book retrieved successfully", book)
+}
+
+// GetReadingTime handles GET /api/v1/books/{id}/reading-time
+func (h *BookHandler) GetReadingTime(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.bookID(w, r)
+	if !ok {
 		return
 	}
 
 	book, err := h.service.GetBookByID(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Failed to get book", "error", err, "id", id)
-		h.respondError(w, http.StatusNotFound, "Book not found")
+		h.respondLookupError(w, r, "Failed to get book", err, "id", id)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, "Book retrieved successfully", book)
+	wordsPerPage, wordsPerMinute := domain.DefaultWordsPerPage, domain.DefaultWordsPerMinute
+	if h.config != nil && h.config.WordsPerPage > 0 && h.config.WordsPerMinute > 0 {
+		wordsPerPage, wordsPerMinute = h.config.WordsPerPage, h.config.WordsPerMinute
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Reading time estimated successfully", &domain.ReadingTime{
+		Pages:          book.Pages,
+		WordsPerPage:   wordsPerPage,
+		WordsPerMinute: wordsPerMinute,
+		Minutes:        book.ReadingTimeMinutes(wordsPerPage, wordsPerMinute),
+	})
+}
+
+// GetCitation handles GET /api/v1/books/{id}/citation, returning the book's
+// citation as plain text in the style given by ?style (default apa)
+func (h *BookHandler) GetCitation(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.bookID(w, r)
+	if !ok {
+		return
+	}
+
+	style := citation.StyleAPA
+	if value := r.URL.Query().Get("style"); value != "" {
+		var err error
+		if style, err = citation.ParseStyle(value); err != nil {
+			h.respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	book, err := h.service.GetBookByID(r.Context(), id)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book", err, "id", id)
+		return
+	}
+
+	h.setCacheControl(w, r, http.StatusOK)
+	w.Header().Set("Content-Type", style.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, citation.Format(book, style)); err != nil {
+		h.logger.Error("Failed to write citation", "error", err, "id", id)
+	}
+}
+
+// GetRelatedBooks handles GET /api/v1/books/{id}/related
+func (h *BookHandler) GetRelatedBooks(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.bookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 0 {
+			h.respondError(w, r, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+	}
+
+	book, err := h.service.GetBookByID(r.Context(), id)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book", err, "id", id)
+		return
+	}
+
+	books, err := h.service.GetRelatedBooks(r.Context(), book, limit)
+	if err != nil {
+		h.logger.Error("Failed to get related books", "error", err, "id", id)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve related books")
+		return
+	}
+
+	h.presentBooks(r, books)
+	h.respondSuccess(w, r, http.StatusOK, "Related books retrieved successfully", books)
 }
 
 // GetBooks handles GET /api/v1/books
 func (h *BookHandler) GetBooks(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters for filtering
-	filter := &domain.BookFilter{
-		Author: r.URL.Query().Get("author"),
-		Genre:  r.URL.Query().Get("genre"),
-		Search: r.URL.Query().Get("search"),
+	filter := h.parseBookFilter(r)
+	if err := h.validateFilter(filter); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Parse available filter
-	if availableStr := r.URL.Query().Get("available"); availableStr != "" {
-		if available, err := strconv.ParseBool(availableStr); err == nil {
-			filter.Available = &available
-		}
+	h.recordSearch(filter.Search)
+
+	// Cap the unpaginated list, fetching one extra book to detect truncation
+	maxResults := 0
+	if h.config != nil {
+		maxResults = h.config.MaxListResults
+	}
+	if maxResults > 0 {
+		filter.Limit = maxResults + 1
 	}
 
 	books, err := h.service.GetAllBooks(r.Context(), filter)
 	if err != nil {
 		h.logger.Error("Failed to get books", "error", err)
-		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve books")
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve books")
 		return
 	}
 
+	truncated := maxResults > 0 && len(books) > maxResults
+	if truncated {
+		books = books[:maxResults]
+		h.logger.Warn("Book list truncated", "max_results", maxResults)
+	}
+
 	// Get count for metadata
-	count, err := h.service.GetBooksCount(r.Context(), filter)
+	count, estimated, err := h.service.GetBooksCount(r.Context(), filter)
 	if err != nil {
 		h.logger.Warn("Failed to get books count", "error", err)
 		count = len(books) // Fallback to actual count
 	}
 
+	meta := map[string]interface{}{
+		"total": count,
+		"count": len(books),
+	}
+	if estimated {
+		meta["estimated"] = true
+	}
+	if truncated {
+		meta["truncated"] = true
+	}
+
+	h.setContentHash(w, books)
+	h.presentBooks(r, books)
 	response := map[string]interface{}{
 		"books": books,
-		"meta": map[string]interface{}{
-			"total": count,
-			"count": len(books),
-		},
+		"meta":  meta,
 	}
 
-	h.respondSuccess(w, http.StatusOK, "Books retrieved successfully", response)
+	h.respondSuccess(w, r, http.StatusOK, "Books retrieved successfully", response)
 }
 
 // UpdateBook handles PUT /api/v1/books/{id}
 func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid book ID")
+	id, ok := h.bookID(w, r)
+	if !ok {
 		return
 	}
 
 	var req domain.UpdateBookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid JSON payload")
+	if !h.decodeRequest(w, r, &req, false) {
+		return
+	}
+
+	if !h.checkIfMatch(w, r, id) {
 		return
 	}
 
 	book, err := h.service.UpdateBook(r.Context(), id, &req)
 	if err != nil {
-		h.logger.Error("Failed to update book", "error", err, "id", id)
-		h.respondError(w, http.StatusBadRequest, err.Error())
+		h.logRequestError("Failed to update book", err, "id", id)
+		if !isClientError(err) {
+			h.respondError(w, r, http.StatusInternalServerError, "Failed to update book")
+			return
+		}
+		if errors.Is(err, service.ErrImmutableField) {
+			h.respondError(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrDescriptionTooLong) || errors.Is(err, domain.ErrUnknownAuthority) {
+			h.respondError(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, "Book updated successfully", book)
+	// Only a publish year set in this request is worth a warning
+	var warnings []string
+	if req.PublishYear != nil {
+		warnings = h.publishYearWarnings(book.PublishYear)
+	}
+	if req.Genre != nil {
+		warnings = append(warnings, h.genreWarnings(*req.Genre, book)...)
+	}
+
+	w.Header().Set("ETag", book.ETag())
+	h.presentBook(r, book)
+	h.respond(w, r, http.StatusOK, Response{
+		Status:   "success",
+		Message:  "Book updated successfully",
+		Data:     book,
+		Warnings: warnings,
+	})
 }
 
 // DeleteBook handles DELETE /api/v1/books/{id}
 func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid book ID")
+	id, ok := h.bookID(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkIfMatch(w, r, id) {
 		return
 	}
 
-	err = h.service.DeleteBook(r.Context(), id)
+	err := h.service.DeleteBook(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Failed to delete book", "error", err, "id", id)
-		h.respondError(w, http.StatusNotFound, "Book not found")
+		h.logRequestError("Failed to delete book", err, "id", id)
+		if errors.Is(err, service.ErrValidation) {
+			h.respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !errors.Is(err, domain.ErrBookNotFound) {
+			h.respondError(w, r, http.StatusInternalServerError, "Failed to delete book")
+			return
+		}
+		h.respondError(w, r, http.StatusNotFound, "Book not found")
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, "Book deleted successfully", nil)
+	h.respondSuccess(w, r, http.StatusOK, "Book deleted successfully", nil)
 }
 
 // GetBookByISBN handles GET /api/v1/books/isbn/{isbn}
@@ -168,51 +524,792 @@ func (h *BookHandler) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
 
 	book, err := h.service.GetBookByISBN(r.Context(), isbn)
 	if err != nil {
-		h.logger.Error("Failed to get book by ISBN", "error", err, "isbn", isbn)
-		h.respondError(w, http.StatusNotFound, "Book not found")
+		h.respondLookupError(w, r, "Failed to get book by ISBN", err, "isbn", isbn)
+		return
+	}
+
+	w.Header().Set("ETag", book.ETag())
+	h.presentBook(r, book)
+	h.respondSuccess(w, r, http.StatusOK, "Book retrieved successfully", book)
+}
+
+// GetBookByAccessionNumber handles GET /api/v1/books/accession/{number}
+func (h *BookHandler) GetBookByAccessionNumber(w http.ResponseWriter, r *http.Request) {
+	number := mux.Vars(r)["number"]
+
+	book, err := h.service.GetBookByAccessionNumber(r.Context(), number)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book by accession number", err, "accession_number", number)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, "Book retrieved successfully", book)
+	w.Header().Set("ETag", book.ETag())
+	h.presentBook(r, book)
+	h.respondSuccess(w, r, http.StatusOK, "Book retrieved successfully", book)
+}
+
+// CheckISBNsExist handles POST /api/v1/books/isbn/exists
+func (h *BookHandler) CheckISBNsExist(w http.ResponseWriter, r *http.Request) {
+	var req domain.ISBNExistsRequest
+
+	if !h.decodeRequest(w, r, &req, false) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	exists, err := h.service.CheckISBNsExist(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to check ISBNs", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to check ISBNs")
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "ISBNs checked successfully", exists)
+}
+
+// BulkUpdateBooks handles POST /api/v1/books/bulk-update
+func (h *BookHandler) BulkUpdateBooks(w http.ResponseWriter, r *http.Request) {
+	var req domain.BulkUpdateRequest
+
+	if !h.decodeRequest(w, r, &req, true) {
+		return
+	}
+
+	result, err := h.service.BulkUpdateBooks(r.Context(), &req)
+	if err != nil {
+		h.logRequestError("Failed to bulk update books", err)
+		if !isClientError(err) {
+			h.respondError(w, r, http.StatusInternalServerError, "Failed to bulk update books")
+			return
+		}
+		if errors.Is(err, domain.ErrUnknownAuthority) {
+			h.respondError(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if result.Affected != result.Matched {
+		h.logger.Warn("Bulk update changed fewer books than matched", "matched", result.Matched, "affected", result.Affected)
+	}
+	h.respondSuccess(w, r, http.StatusOK, "Books updated successfully", result)
+}
+
+// BackfillISBN13 handles POST /api/v1/admin/isbn-backfill
+func (h *BookHandler) BackfillISBN13(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.BackfillISBN13(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to backfill ISBN-13s", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to backfill ISBN-13s")
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "ISBN-13 backfill completed", result)
+}
+
+// GetAuthors handles GET /api/v1/authors
+func (h *BookHandler) GetAuthors(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authors, total, err := h.service.GetAuthors(r.Context(), page)
+	if err != nil {
+		h.logger.Error("Failed to get authors", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve authors")
+		return
+	}
+
+	response := map[string]interface{}{
+		"authors": authors,
+		"meta":    paginationMeta(total, len(authors), page),
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Authors retrieved successfully", response)
+}
+
+// GetGenres handles GET /api/v1/genres
+func (h *BookHandler) GetGenres(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	genres, total, err := h.service.GetGenres(r.Context(), page)
+	if err != nil {
+		h.logger.Error("Failed to get genres", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve genres")
+		return
+	}
+
+	response := map[string]interface{}{
+		"genres": genres,
+		"meta":   paginationMeta(total, len(genres), page),
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Genres retrieved successfully", response)
+}
+
+// GetPublishers handles GET /api/v1/publishers
+func (h *BookHandler) GetPublishers(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	publishers, total, err := h.service.GetPublishers(r.Context(), r.URL.Query().Get("prefix"), page)
+	if err != nil {
+		h.logger.Error("Failed to get publishers", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve publishers")
+		return
+	}
+
+	response := map[string]interface{}{
+		"publishers": publishers,
+		"meta":       paginationMeta(total, len(publishers), page),
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Publishers retrieved successfully", response)
+}
+
+// authorityKinds maps the path segment of the authority endpoint to its kind
+var authorityKinds = map[string]domain.AuthorityKind{
+	"authors":    domain.AuthorityAuthor,
+	"publishers": domain.AuthorityPublisher,
+}
+
+// CreateAuthority handles POST /api/v1/admin/authorities/{kind}, adding a
+// known author or publisher. Adding a name already known, ignoring case,
+// returns it with 200 rather than 201.
+func (h *BookHandler) CreateAuthority(w http.ResponseWriter, r *http.Request) {
+	kind, ok := authorityKinds[mux.Vars(r)["kind"]]
+	if !ok {
+		h.respondError(w, r, http.StatusNotFound, "Unknown authority kind")
+		return
+	}
+
+	var req domain.CreateAuthorityRequest
+	if !h.decodeRequest(w, r, &req, true) {
+		return
+	}
+
+	authority, created, err := h.service.CreateAuthority(r.Context(), kind, &req)
+	if err != nil {
+		h.logRequestError("Failed to create authority", err, "kind", kind)
+		if !isClientError(err) {
+			h.respondError(w, r, http.StatusInternalServerError, "Failed to create authority")
+			return
+		}
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !created {
+		h.respondSuccess(w, r, http.StatusOK, "Authority already exists", authority)
+		return
+	}
+	h.respondSuccess(w, r, http.StatusCreated, "Authority created successfully", authority)
+}
+
+// GetDistinctValues handles GET /api/v1/books/distinct, listing the distinct
+// values of the field parameter with their book counts. The list filters
+// scope which books are counted.
+func (h *BookHandler) GetDistinctValues(w http.ResponseWriter, r *http.Request) {
+	field := r.URL.Query().Get("field")
+	if err := domain.ValidateDistinctField(field); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := h.parseBookFilter(r)
+	if err := h.validateFilter(filter); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	values, total, err := h.service.GetDistinctValues(r.Context(), field, filter, page)
+	if err != nil {
+		h.logger.Error("Failed to get distinct values", "error", err, "field", field)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve distinct values")
+		return
+	}
+
+	response := map[string]interface{}{
+		"field":  field,
+		"values": values,
+		"meta":   paginationMeta(total, len(values), page),
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Distinct values retrieved successfully", response)
+}
+
+// GetBooksNeedingAttention handles GET /api/v1/books/attention
+func (h *BookHandler) GetBooksNeedingAttention(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	books, total, err := h.service.GetBooksNeedingAttention(r.Context(), page)
+	if err != nil {
+		h.logger.Error("Failed to get books needing attention", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve books needing attention")
+		return
+	}
+
+	for _, result := range books {
+		h.presentBook(r, result.Book)
+	}
+
+	response := map[string]interface{}{
+		"books": books,
+		"meta":  paginationMeta(total, len(books), page),
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Books needing attention retrieved successfully", response)
+}
+
+// GetGenreStats handles GET /api/v1/genres/stats
+func (h *BookHandler) GetGenreStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetGenreStats(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get genre stats", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve genre stats")
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Genre stats retrieved successfully", stats)
+}
+
+// GetPageStats handles GET /api/v1/stats/pages, summarizing the page counts
+// of the books matching the list filters
+func (h *BookHandler) GetPageStats(w http.ResponseWriter, r *http.Request) {
+	filter := h.parseBookFilter(r)
+	if err := h.validateFilter(filter); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := h.service.GetPageStats(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get page stats", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve page stats")
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Page stats retrieved successfully", stats)
+}
+
+// GetGrowth handles GET /api/v1/stats/growth, counting the books created per
+// day, week or month between from and to
+func (h *BookHandler) GetGrowth(w http.ResponseWriter, r *http.Request) {
+	query, err := parseGrowthQuery(r)
+	if err == nil {
+		err = query.Validate()
+	}
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	growth, err := h.service.GetGrowth(r.Context(), query)
+	if err != nil {
+		h.logger.Error("Failed to get catalog growth", "error", err)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve catalog growth")
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Catalog growth retrieved successfully", growth)
+}
+
+// ExportCatalog handles POST /api/v1/admin/export
+func (h *BookHandler) ExportCatalog(w http.ResponseWriter, r *http.Request) {
+	format := domain.ExportFormatJSON
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = domain.ParseExportFormat(value); err != nil {
+			h.respondError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if h.jobs != nil {
+		h.exportCatalogJob(w, r, format)
+		return
+	}
+
+	result, err := h.service.ExportCatalog(r.Context(), format)
+	h.respondExport(w, r, format, result, err)
+}
+
+// respondExport writes the outcome of a catalog export run in the request
+func (h *BookHandler) respondExport(w http.ResponseWriter, r *http.Request, format domain.ExportFormat, result *domain.ExportResult, err error) {
+	if errors.Is(err, service.ErrExportNotConfigured) {
+		h.respondError(w, r, http.StatusServiceUnavailable, "Catalog export is not configured")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to export catalog", "error", err, "format", format)
+		h.respondError(w, r, http.StatusInternalServerError, "Failed to export catalog")
+		return
+	}
+
+	h.logger.Info("Catalog exported", "key", result.Key, "size", result.Size)
+	h.respondSuccess(w, r, http.StatusCreated, "Catalog exported successfully", result)
 }
 
 // HealthCheck handles GET /health
 func (h *BookHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	h.respondSuccess(w, http.StatusOK, "Service is healthy", map[string]string{
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondSuccess(w, r, http.StatusOK, "Service is healthy", map[string]string{
 		"status": "ok",
 		"service": "library-management-api",
 	})
 }
 
+// Ready handles GET /ready. Database and pool details are only included when
+// no health token is configured or the request presents the matching token.
+func (h *BookHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	pingErr := h.db.PingContext(ctx)
+	errorRate, degraded := h.errorRateStatus()
+
+	if !h.healthAuthorized(r) {
+		if pingErr != nil || degraded {
+			h.respondError(w, r, http.StatusServiceUnavailable, "not ok")
+			return
+		}
+		h.respondSuccess(w, r, http.StatusOK, "Service is ready", map[string]string{"status": "ok"})
+		return
+	}
+
+	stats := h.db.Stats()
+	database := map[string]interface{}{
+		"status":           "up",
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+		"wait_count":       stats.WaitCount,
+		"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+		"max_open":         stats.MaxOpenConnections,
+	}
+
+	details := map[string]interface{}{"status": "ok", "database": database}
+	if errorRate != nil {
+		details["error_rate"] = errorRate
+	}
+	if h.dbQueue != nil {
+		details["db_queue"] = h.dbQueue.stats()
+	}
+
+	if pingErr != nil || degraded {
+		message := "Service is degraded"
+		details["status"] = "degraded"
+		if pingErr != nil {
+			h.logger.Error("Readiness check failed", "error", pingErr)
+			database["status"] = "down"
+			database["error"] = pingErr.Error()
+			message = "Service is not ready"
+			details["status"] = "not ok"
+		} else {
+			h.logger.Warn("Readiness degraded by error rate", "rate", errorRate["rate"])
+		}
+		h.respond(w, r, http.StatusServiceUnavailable, Response{
+			Status: "error",
+			Error:  message,
+			Data:   details,
+		})
+		return
+	}
+
+	h.respondSuccess(w, r, http.StatusOK, "Service is ready", details)
+}
+
+// errorRateStatus returns the readiness detail for the recent 5xx rate and
+// whether it exceeds the configured threshold. The detail is nil when error
+// rate tracking is disabled.
+func (h *BookHandler) errorRateStatus() (map[string]interface{}, bool) {
+	if h.errors == nil {
+		return nil, false
+	}
+
+	rate, requests := h.errors.rate()
+	degraded := requests >= errorRateMinRequests && rate > h.config.ErrorRateThreshold
+	status := "ok"
+	if degraded {
+		status = "degraded"
+	}
+
+	return map[string]interface{}{
+		"status":         status,
+		"rate":           rate,
+		"requests":       requests,
+		"threshold":      h.config.ErrorRateThreshold,
+		"window_seconds": len(h.errors.buckets),
+	}, degraded
+}
+
+// healthAuthorized reports whether the request may see detailed health output
+func (h *BookHandler) healthAuthorized(r *http.Request) bool {
+	if h.config == nil || h.config.HealthToken == "" {
+		return true
+	}
+
+	token := r.Header.Get("X-Health-Token")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.config.HealthToken)) == 1
+}
+
+// bookID resolves the {id} route parameter to the book's internal ID. With
+// PUBLIC_IDS enabled the parameter is the public ID; otherwise it is the
+// integer ID. It writes the error response and returns false on failure.
+func (h *BookHandler) bookID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	param := mux.Vars(r)["id"]
+
+	if h.config == nil || !h.config.PublicIDs {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			h.respondError(w, r, http.StatusBadRequest, "Invalid book ID")
+			return 0, false
+		}
+		return id, true
+	}
+
+	book, err := h.service.GetBookByPublicID(r.Context(), param)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book by public ID", err, "public_id", param)
+		return 0, false
+	}
+	return book.ID, true
+}
+
+// checkIfMatch enforces the If-Match precondition for a write to the given book.
+// It writes the error response and returns false when the request must not proceed.
+func (h *BookHandler) checkIfMatch(w http.ResponseWriter, r *http.Request, id int) bool {
+	ifMatch := strings.Join(r.Header.Values("If-Match"), ",")
+	if strings.TrimSpace(ifMatch) == "" {
+		if h.config != nil && h.config.RequireIfMatch {
+			h.respondError(w, r, http.StatusPreconditionRequired, "If-Match header is required")
+			return false
+		}
+		return true
+	}
+
+	// Lock the book in the request transaction, so a concurrent write with
+	// the same ETag waits for this one and then fails the check
+	book, err := h.service.GetBookForUpdate(r.Context(), id)
+	if err != nil {
+		h.respondLookupError(w, r, "Failed to get book for precondition check", err, "id", id)
+		return false
+	}
+
+	current := book.ETag()
+	for _, tag := range parseIfMatch(ifMatch) {
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+
+	w.Header().Set("ETag", current)
+	h.respondError(w, r, http.StatusPreconditionFailed, "Book has been modified; refetch and retry")
+	return false
+}
+
+// parseIfMatch returns the entity tags listed in an If-Match header value,
+// following RFC 9110: "*" or a comma-separated list of quoted tags, each
+// optionally marked weak with W/. Tags are returned quoted with the weak
+// marker dropped, so a tag weakened by a compressing proxy still matches.
+// Parsing stops at the first malformed element.
+func parseIfMatch(value string) []string {
+	var tags []string
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			return tags
+		}
+		if value[0] == '*' {
+			tags = append(tags, "*")
+			value = value[1:]
+			continue
+		}
+
+		value = strings.TrimPrefix(value, "W/")
+		if value == "" || value[0] != '"' {
+			return tags
+		}
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return tags
+		}
+		tags = append(tags, value[:end+2])
+		value = value[end+2:]
+		if rest := strings.TrimLeft(value, " \t"); rest != "" && rest[0] != ',' {
+			return tags
+		}
+	}
+}
+
+// presentBook prepares a book for output, converting timestamps to the
+// request's zone and filling in the description placeholder
+func (h *BookHandler) presentBook(r *http.Request, book *domain.Book) {
+	book.InLocation(h.outputLocation(r))
+	if h.config != nil {
+		if h.config.PublicIDs {
+			book.ID = 0 // the public ID is the only external key
+		}
+		if h.config.DescriptionPlaceholder != "" && strings.TrimSpace(book.Description) == "" {
+			book.Description = h.config.DescriptionPlaceholder
+		}
+	}
+}
+
+// presentBooks prepares a list of books for output, shortening long
+// descriptions when LIST_DESCRIPTION_LENGTH is set
+func (h *BookHandler) presentBooks(r *http.Request, books []*domain.Book) {
+	for _, book := range books {
+		h.presentBook(r, book)
+		if h.config != nil {
+			book.TruncateDescription(h.config.ListDescriptionLength)
+		}
+	}
+}
+
+// setContentHash sets X-Content-Hash for a list of books when
+// LIST_CONTENT_HASH is on. It must run before presentBooks, which can hide
+// the IDs the hash is built from.
+func (h *BookHandler) setContentHash(w http.ResponseWriter, books []*domain.Book) {
+	if h.config != nil && h.config.ListContentHash {
+		w.Header().Set("X-Content-Hash", domain.ContentHash(books))
+	}
+}
+
 // respondSuccess sends a success response
-func (h *BookHandler) respondSuccess(w http.ResponseWriter, statusCode int, message string, data interface{}) {
-	// Ensure JSON content type is set
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(statusCode)
-	
-	response := Response{
+func (h *BookHandler) respondSuccess(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}) {
+	h.respond(w, r, statusCode, Response{
 		Status:  "success",
 		Message: message,
 		Data:    data,
+	})
+}
+
+// respondError sends an error response, as RFC 7807 Problem Details when
+// configured or requested
+func (h *BookHandler) respondError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	h.respondFieldErrors(w, r, statusCode, message, nil)
+}
+
+// respondFieldErrors sends an error response for a request that failed
+// validation. Problem Details list every field violation in errors; the
+// envelope carries only the message.
+func (h *BookHandler) respondFieldErrors(w http.ResponseWriter, r *http.Request, statusCode int, message string, fieldErrors []domain.FieldError) {
+	recordSpanError(r, statusCode, message)
+	if h.wantsProblem(r) {
+		h.respondProblem(w, r, statusCode, message, fieldErrors)
+		return
 	}
-	
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		h.logger.Error("Failed to encode JSON response", "error", err)
-	}
+
+	h.respond(w, r, statusCode, Response{
+		Status: "error",
+		Error:  message,
+	})
 }
 
-// respondError sends an error response
-func (h *BookHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
-	// Ensure JSON content type is set
+// respond writes the response envelope as XML when the client prefers it, else JSON
+func (h *BookHandler) respond(w http.ResponseWriter, r *http.Request, statusCode int, response Response) {
+	h.setCacheControl(w, r, statusCode)
+
+	if prefersXML(r) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(statusCode)
+
+		if err := encodeXMLResponse(w, response); err != nil {
+			h.logger.Error("Failed to encode XML response", "error", err)
+		}
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Add("Vary", "Accept")
 	w.WriteHeader(statusCode)
-	
-	response := Response{
-		Status: "error",
-		Error:  message,
+
+	if err := h.encodeJSON(w, r, response); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
 	}
-	
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		h.logger.Error("Failed to encode JSON error response", "error", err)
+}
+
+// setCacheControl sets Cache-Control unless the handler already did.
+// Successful GETs may be cached for CACHE_MAX_AGE, privately when the request
+// carried credentials; everything else is no-store.
+func (h *BookHandler) setCacheControl(w http.ResponseWriter, r *http.Request, statusCode int) {
+	if w.Header().Get("Cache-Control") != "" {
+		return
+	}
+
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || statusCode >= http.StatusBadRequest {
+		w.Header().Set("Cache-Control", "no-store")
+		return
+	}
+
+	if h.config == nil || h.config.CacheMaxAge <= 0 {
+		w.Header().Set("Cache-Control", "no-cache")
+		return
+	}
+
+	scope := "private"
+	if h.config.CachePublic && !hasCredentials(r) {
+		scope = "public"
+	}
+	w.Header().Set("Cache-Control", scope+", max-age="+strconv.Itoa(int(h.config.CacheMaxAge.Seconds())))
+}
+
+// hasCredentials reports whether the request authenticates itself, so its
+// response must not be stored by shared caches
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != "" ||
+		r.Header.Get("X-Health-Token") != "" || r.URL.Query().Get("token") != ""
+}
+
+// prettyJSON reports whether to indent JSON output: the pretty query parameter
+// wins, otherwise PRETTY_JSON decides
+func (h *BookHandler) prettyJSON(r *http.Request) bool {
+	if pretty, err := strconv.ParseBool(r.URL.Query().Get("pretty")); err == nil {
+		return pretty
+	}
+	return h.config != nil && h.config.PrettyJSON
+}
+
+// applyDefaultAvailable limits filter to available books under
+// DEFAULT_AVAILABLE_ONLY. Patrons see only available books unless they ask
+// otherwise; admin keys keep the full catalog.
+func (h *BookHandler) applyDefaultAvailable(r *http.Request, filter *domain.BookFilter) {
+	if h.config != nil && h.config.DefaultAvailableOnly && !r.URL.Query().Has("available") && !h.isAdminRequest(r) {
+		available := true
+		filter.Available = &available
+	}
+}
+
+// parseBookFilter parses the book list filter and sort query parameters and
+// applies DEFAULT_AVAILABLE_ONLY, so every listing shares the same default
+func (h *BookHandler) parseBookFilter(r *http.Request) *domain.BookFilter {
+	filter := &domain.BookFilter{
+		Author:    r.URL.Query().Get("author"),
+		Genre:     r.URL.Query().Get("genre"),
+		Publisher: r.URL.Query().Get("publisher"),
+		Search:    r.URL.Query().Get("search"),
+		Sort:      r.URL.Query().Get("sort"),
+		Order:     strings.ToLower(r.URL.Query().Get("order")),
+	}
+
+	// Parse available filter
+	if availableStr := r.URL.Query().Get("available"); availableStr != "" {
+		if available, err := strconv.ParseBool(availableStr); err == nil {
+			filter.Available = &available
+		}
 	}
-}
\ No newline at end of file
+
+	h.applyDefaultAvailable(r, filter)
+	return filter
+}
+
+// growthDateLayout is the format of the growth from and to parameters
+const growthDateLayout = "2006-01-02"
+
+// parseGrowthQuery parses the interval, from and to query parameters. The
+// interval defaults to month, to defaults to today and from to a year
+// before to.
+func parseGrowthQuery(r *http.Request) (*domain.GrowthQuery, error) {
+	query := &domain.GrowthQuery{Interval: domain.GrowthIntervalMonth, To: time.Now().UTC()}
+
+	if value := r.URL.Query().Get("interval"); value != "" {
+		interval, err := domain.ParseGrowthInterval(value)
+		if err != nil {
+			return nil, err
+		}
+		query.Interval = interval
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		to, err := time.Parse(growthDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to %q: must be a date like 2024-01-31", value)
+		}
+		query.To = to
+	}
+	query.From = query.To.AddDate(-1, 0, 0)
+	if value := r.URL.Query().Get("from"); value != "" {
+		from, err := time.Parse(growthDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from %q: must be a date like 2024-01-01", value)
+		}
+		query.From = from
+	}
+
+	return query, nil
+}
+
+// validateFilter checks a parsed list filter, including the configured
+// minimum search length. An empty search is always allowed.
+func (h *BookHandler) validateFilter(filter *domain.BookFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if h.config != nil && filter.Search != "" && utf8.RuneCountInString(strings.TrimSpace(filter.Search)) < h.config.MinSearchLength {
+		return fmt.Errorf("search must be at least %d characters", h.config.MinSearchLength)
+	}
+	return nil
+}
+
+// parsePagination parses the limit and offset query parameters
+func parsePagination(r *http.Request) (*domain.Pagination, error) {
+	page := &domain.Pagination{}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, errors.New("limit must be a non-negative integer")
+		}
+		page.Limit = limit
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return nil, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// paginationMeta builds the meta object for paginated list responses
+func paginationMeta(total, count int, page *domain.Pagination) map[string]interface{} {
+	return map[string]interface{}{
+		"total":  total,
+		"count":  count,
+		"limit":  page.Limit,
+		"offset": page.Offset,
+	}
+}
@@ -0,0 +1,1704 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"library-management/internal/config"
+	"library-management/internal/domain"
+	"library-management/internal/service"
+	"library-management/pkg/logger"
+)
+
+// stubBookService implements service.BookService for handler tests.
+// Methods not overridden panic via the nil embedded interface.
+type stubBookService struct {
+	service.BookService
+	books     map[int]*domain.Book
+	exportErr error
+	updateErr error
+	// authorities holds the added authorities by kind and lower-cased name
+	authorities map[domain.AuthorityKind]map[string]*domain.Authority
+	// descriptionMaxLength is the description limit writes are validated with
+	descriptionMaxLength int
+}
+
+func newStubBookService(books ...*domain.Book) *stubBookService {
+	s := &stubBookService{books: make(map[int]*domain.Book), descriptionMaxLength: domain.DefaultDescriptionMaxLength}
+	for _, book := range books {
+		s.books[book.ID] = book
+	}
+	return s
+}
+
+func (s *stubBookService) GetBookByID(ctx context.Context, id int) (*domain.Book, error) {
+	book, ok := s.books[id]
+	if !ok {
+		return nil, fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
+	}
+	copied := *book
+	return &copied, nil
+}
+
+func (s *stubBookService) GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	return s.GetBookByID(ctx, id)
+}
+
+func (s *stubBookService) GetBookByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	for _, book := range s.books {
+		if book.PublicID == publicID {
+			copied := *book
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("book with public ID %s %w", publicID, domain.ErrBookNotFound)
+}
+
+func (s *stubBookService) GetAllBooks(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
+	books := make([]*domain.Book, 0, len(s.books))
+	for _, book := range s.books {
+		if book.ID <= filter.AfterID {
+			continue
+		}
+		if filter.Available != nil && book.Available != *filter.Available {
+			continue
+		}
+		copied := *book
+		books = append(books, &copied)
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	if filter.Limit > 0 && len(books) > filter.Limit {
+		books = books[:filter.Limit]
+	}
+	return books, nil
+}
+
+func (s *stubBookService) StreamBooks(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	books, err := s.GetAllBooks(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, book := range books {
+		if filter.Genre != "" && !strings.EqualFold(book.Genre, filter.Genre) {
+			continue
+		}
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubBookService) CheckISBNsExist(ctx context.Context, req *domain.ISBNExistsRequest) (map[string]bool, error) {
+	exists := make(map[string]bool, len(req.ISBNs))
+	for _, isbn := range req.ISBNs {
+		exists[isbn] = false
+		for _, book := range s.books {
+			if domain.NormalizeISBN(book.ISBN) == domain.NormalizeISBN(isbn) {
+				exists[isbn] = true
+			}
+		}
+	}
+	return exists, nil
+}
+
+func (s *stubBookService) GetRandomBook(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	for _, book := range s.books {
+		if filter.Genre != "" && !strings.EqualFold(book.Genre, filter.Genre) {
+			continue
+		}
+		if filter.Available != nil && book.Available != *filter.Available {
+			continue
+		}
+		copied := *book
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *stubBookService) GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, bool, error) {
+	return len(s.books), false, nil
+}
+
+func (s *stubBookService) GetBooksNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, int, error) {
+	results := []*domain.BookAttention{}
+	for _, book := range s.books {
+		copied := *book
+		results = append(results, &domain.BookAttention{Book: &copied, Reasons: []string{domain.ReasonMissingDescription}})
+	}
+	return results, len(results), nil
+}
+
+func (s *stubBookService) CreateBook(ctx context.Context, req *domain.CreateBookRequest) (*domain.Book, error) {
+	if err := req.Validate(domain.DefaultPublishYears(), s.descriptionMaxLength); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+	book := req.ToBook()
+	book.ID = len(s.books) + 1
+	s.books[book.ID] = book
+	copied := *book
+	return &copied, nil
+}
+
+func (s *stubBookService) FindDuplicateBooks(ctx context.Context, book *domain.Book) ([]*domain.Book, error) {
+	duplicates := []*domain.Book{}
+	for _, b := range s.books {
+		if b.ID != book.ID && strings.EqualFold(b.Title, book.Title) && strings.EqualFold(b.Author, book.Author) {
+			copied := *b
+			duplicates = append(duplicates, &copied)
+		}
+	}
+	return duplicates, nil
+}
+
+func (s *stubBookService) UpdateBook(ctx context.Context, id int, req *domain.UpdateBookRequest) (*domain.Book, error) {
+	if s.updateErr != nil {
+		return nil, s.updateErr
+	}
+	if err := req.Validate(domain.DefaultPublishYears(), s.descriptionMaxLength); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+	book, ok := s.books[id]
+	if !ok {
+		return nil, fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
+	}
+	req.ApplyTo(book)
+	copied := *book
+	return &copied, nil
+}
+
+func (s *stubBookService) DeleteBook(ctx context.Context, id int) error {
+	if _, ok := s.books[id]; !ok {
+		return fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
+	}
+	delete(s.books, id)
+	return nil
+}
+
+func (s *stubBookService) ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	if s.exportErr != nil {
+		return nil, s.exportErr
+	}
+	return &domain.ExportResult{Key: "catalog-test." + string(format), Size: 42, Format: format}, nil
+}
+
+func (s *stubBookService) BackupCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	return s.ExportCatalog(ctx, format)
+}
+
+func (s *stubBookService) BulkUpdateBooks(ctx context.Context, req *domain.BulkUpdateRequest) (*domain.BulkUpdateResult, error) {
+	return &domain.BulkUpdateResult{}, nil
+}
+
+func (s *stubBookService) ValidateBook(ctx context.Context, req *domain.CreateBookRequest) []domain.FieldError {
+	return req.FieldErrors(domain.DefaultPublishYears(), s.descriptionMaxLength)
+}
+
+func (s *stubBookService) CreateAuthority(ctx context.Context, kind domain.AuthorityKind, req *domain.CreateAuthorityRequest) (*domain.Authority, bool, error) {
+	if err := req.Validate(); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", service.ErrValidation, err)
+	}
+	if s.authorities == nil {
+		s.authorities = make(map[domain.AuthorityKind]map[string]*domain.Authority)
+	}
+	if s.authorities[kind] == nil {
+		s.authorities[kind] = make(map[string]*domain.Authority)
+	}
+	name := domain.NormalizeSpace(req.Name)
+	if existing, ok := s.authorities[kind][strings.ToLower(name)]; ok {
+		return existing, false, nil
+	}
+	authority := &domain.Authority{ID: len(s.authorities[kind]) + 1, Kind: kind, Name: name}
+	s.authorities[kind][strings.ToLower(name)] = authority
+	return authority, true, nil
+}
+
+func (s *stubBookService) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, int, error) {
+	counts := make(map[string]int)
+	for _, book := range s.books {
+		if filter.Genre != "" && !strings.EqualFold(book.Genre, filter.Genre) {
+			continue
+		}
+		value := map[string]string{
+			"genre":        book.Genre,
+			"author":       book.Author,
+			"publisher":    book.Publisher,
+			"publish_year": fmt.Sprint(book.PublishYear),
+		}[field]
+		counts[value]++
+	}
+	values := []*domain.DistinctValue{}
+	for value, count := range counts {
+		values = append(values, &domain.DistinctValue{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Value < values[j].Value })
+	return values, len(values), nil
+}
+
+// stubDatabase implements DatabaseChecker for handler tests; transactions
+// are served by the fake driver
+type stubDatabase struct {
+	*sql.DB
+	pingErr error
+}
+
+func (d *stubDatabase) PingContext(ctx context.Context) error { return d.pingErr }
+
+func (d *stubDatabase) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 25, OpenConnections: 3, InUse: 1, Idle: 2}
+}
+
+// newTestRouter builds a router with the given service and configuration
+func newTestRouter(svc service.BookService, cfg *config.Config) *mux.Router {
+	router := mux.NewRouter()
+	db, _ := newFakeDB()
+	SetupRoutes(router, NewHandlers(svc, &stubDatabase{DB: db}, logger.New(), cfg))
+	return router
+}
+
+func sampleBook() *domain.Book {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	return &domain.Book{
+		ID:          1,
+		PublicID:    "3f0c8a52-6d1e-4b8a-9c57-2e4f1a7b9d10",
+		Title:       "Clean Code",
+		Author:      "Robert C. Martin",
+		ISBN:        "978-0132350884",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+		Genre:       "Programming",
+		Pages:       464,
+		Available:   true,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+}
+
+func TestBookHandler_OutputTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{OutputLocation: loc})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var body struct {
+		Data struct {
+			CreatedAt string `json:"created_at"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.Data.CreatedAt != "2024-01-01T15:30:00+05:30" {
+		t.Errorf("got %s, want created_at in +05:30", body.Data.CreatedAt)
+	}
+}
+
+func TestBookHandler_RequestTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		target  string
+		status  int
+		created string
+	}{
+		{"tz overrides OUTPUT_TIMEZONE", &config.Config{OutputLocation: loc}, "/api/v1/books/1?tz=America/New_York", http.StatusOK, "2024-01-01T05:00:00-05:00"},
+		{"OUTPUT_TIMEZONE without tz", &config.Config{OutputLocation: loc}, "/api/v1/books/1", http.StatusOK, "2024-01-01T15:30:00+05:30"},
+		{"tz without config", nil, "/api/v1/books/1?tz=Asia/Kolkata", http.StatusOK, "2024-01-01T15:30:00+05:30"},
+		{"tz on lists", nil, "/api/v1/books?tz=Asia/Kolkata", http.StatusOK, "2024-01-01T15:30:00+05:30"},
+		{"unknown zone", nil, "/api/v1/books/1?tz=Mars/Olympus", http.StatusBadRequest, ""},
+		{"server local zone", nil, "/api/v1/books/1?tz=Local", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(newStubBookService(sampleBook()), tt.cfg)
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.created == "" {
+				return
+			}
+			if !strings.Contains(rec.Body.String(), `"created_at":"`+tt.created+`"`) {
+				t.Errorf("body = %s, want created_at %s", rec.Body.String(), tt.created)
+			}
+		})
+	}
+
+	t.Run("v2", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/books?tz=Mars/Olympus", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestBookHandler_ReadyHealthToken(t *testing.T) {
+	router := newTestRouter(newStubBookService(), &config.Config{HealthToken: "secret"})
+
+	ready := func(path string, header string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set("X-Health-Token", header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return body.Data
+	}
+
+	t.Run("unauthorized gets minimal output", func(t *testing.T) {
+		data := ready("/ready", "")
+		if _, ok := data["database"]; ok {
+			t.Error("want no database details without token")
+		}
+		if data["status"] != "ok" {
+			t.Errorf("got %v, want status ok", data["status"])
+		}
+	})
+
+	t.Run("wrong token gets minimal output", func(t *testing.T) {
+		data := ready("/ready", "wrong")
+		if _, ok := data["database"]; ok {
+			t.Error("want no database details with wrong token")
+		}
+	})
+
+	t.Run("header token gets details", func(t *testing.T) {
+		data := ready("/ready", "secret")
+		if _, ok := data["database"]; !ok {
+			t.Error("want database details with valid token")
+		}
+	})
+
+	t.Run("query token gets details", func(t *testing.T) {
+		data := ready("/ready?token=secret", "")
+		if _, ok := data["database"]; !ok {
+			t.Error("want database details with valid query token")
+		}
+	})
+
+	t.Run("liveness stays public", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	})
+}
+
+func TestBookHandler_IfMatch(t *testing.T) {
+	send := func(router *mux.Router, method, ifMatch string) *httptest.ResponseRecorder {
+		var body *strings.Reader
+		if method == http.MethodPut {
+			body = strings.NewReader(`{"title":"Clean Code 2nd Edition"}`)
+		} else {
+			body = strings.NewReader("")
+		}
+		req := httptest.NewRequest(method, "/api/v1/books/1", body)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("matching ETag updates", func(t *testing.T) {
+		book := sampleBook()
+		router := newTestRouter(newStubBookService(book), &config.Config{})
+
+		etag := book.ETag()
+		rec := send(router, http.MethodPut, etag)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if rec.Header().Get("ETag") == etag {
+			t.Error("want a new ETag after update")
+		}
+	})
+
+	t.Run("mismatching ETag fails", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			rec := send(router, method, `"stale"`)
+			if rec.Code != http.StatusPreconditionFailed {
+				t.Errorf("%s: status = %d, want 412", method, rec.Code)
+			}
+		}
+	})
+
+	t.Run("weak and listed ETags match", func(t *testing.T) {
+		book := sampleBook()
+		for _, ifMatch := range []string{"W/" + book.ETag(), `"stale", ` + book.ETag(), `"a,b" , W/` + book.ETag(), "*"} {
+			router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+			if rec := send(router, http.MethodPut, ifMatch); rec.Code != http.StatusOK {
+				t.Errorf("If-Match %s: status = %d, want 200", ifMatch, rec.Code)
+			}
+		}
+	})
+
+	t.Run("matching ETag deletes", func(t *testing.T) {
+		book := sampleBook()
+		router := newTestRouter(newStubBookService(book), &config.Config{})
+
+		rec := send(router, http.MethodDelete, book.ETag())
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("missing If-Match allowed by default", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+		rec := send(router, http.MethodPut, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("missing If-Match rejected when required", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{RequireIfMatch: true})
+
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			rec := send(router, method, "")
+			if rec.Code != http.StatusPreconditionRequired {
+				t.Errorf("%s: status = %d, want 428", method, rec.Code)
+			}
+		}
+	})
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{`"abc"`, []string{`"abc"`}},
+		{`W/"abc"`, []string{`"abc"`}},
+		{`"a", W/"b",,"c"`, []string{`"a"`, `"b"`, `"c"`}},
+		{`"a,b", "c"`, []string{`"a,b"`, `"c"`}},
+		{"*", []string{"*"}},
+		{`abc`, nil},
+		{`"a" junk, "b"`, []string{`"a"`}},
+		{`"unterminated`, nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseIfMatch(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseIfMatch(%q): got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBookHandler_ValidateBook(t *testing.T) {
+	router := newTestRouter(newStubBookService(), &config.Config{})
+
+	validate := func(payload string) (int, bool, []domain.FieldError) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/books/validate", strings.NewReader(payload))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var body struct {
+			Data struct {
+				Valid  bool                `json:"valid"`
+				Errors []domain.FieldError `json:"errors"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, body.Data.Valid, body.Data.Errors
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		code, valid, errs := validate(`{"title":"Clean Code","author":"Robert C. Martin","isbn":"978-0132350884",
+			"publisher":"Prentice Hall","publish_year":2008,"genre":"Programming","pages":464}`)
+		if code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+		if !valid || errs == nil || len(errs) != 0 {
+			t.Errorf("got valid=%v errors=%v, want valid with an empty error list", valid, errs)
+		}
+	})
+
+	t.Run("multiple errors", func(t *testing.T) {
+		code, valid, errs := validate(`{"title":"Clean Code","publish_year":2008,"pages":464}`)
+		if code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+		if valid {
+			t.Error("want payload to be invalid")
+		}
+		if len(errs) != 4 {
+			t.Errorf("got %v, want author, isbn, publisher and genre errors", errs)
+		}
+	})
+}
+
+func TestBookHandler_XMLNegotiation(t *testing.T) {
+	second := sampleBook()
+	second.ID = 2
+	second.Title = "Refactoring"
+	router := newTestRouter(newStubBookService(sampleBook(), second), &config.Config{})
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("single book", func(t *testing.T) {
+		rec := get("/api/v1/books/1", "application/xml")
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+			t.Fatalf("got %q, want XML content type", ct)
+		}
+
+		var body struct {
+			XMLName xml.Name    `xml:"response"`
+			Status  string      `xml:"status"`
+			Book    domain.Book `xml:"data"`
+		}
+		if err := xml.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode XML response: %v", err)
+		}
+		if body.Status != "success" || body.Book.Title != "Clean Code" || body.Book.PublishYear != 2008 {
+			t.Errorf("Unexpected XML book: %+v", body)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		rec := get("/api/v1/books", "application/xml")
+
+		var body struct {
+			Books []domain.Book `xml:"data>books>book"`
+			Total int           `xml:"data>meta>total"`
+		}
+		if err := xml.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode XML response: %v", err)
+		}
+		if len(body.Books) != 2 || body.Books[1].Title != "Refactoring" {
+			t.Errorf("got %+v, want both books in XML", body.Books)
+		}
+		if body.Total != 2 {
+			t.Errorf("total = %d, want 2", body.Total)
+		}
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		rec := get("/api/v1/books/99", "application/xml")
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", rec.Code)
+		}
+
+		var body struct {
+			Status string `xml:"status"`
+			Error  string `xml:"error"`
+		}
+		if err := xml.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode XML response: %v", err)
+		}
+		if body.Status != "error" || body.Error == "" {
+			t.Errorf("got %+v, want XML error envelope", body)
+		}
+	})
+
+	t.Run("JSON stays the default", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json, application/xml", "text/html,application/xml;q=0.9,*/*;q=0.8"} {
+			rec := get("/api/v1/books/1", accept)
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("Accept %q: got %q, want JSON content type", accept, ct)
+			}
+		}
+	})
+}
+
+func TestBookHandler_PrettyJSON(t *testing.T) {
+	get := func(cfg *config.Config, path string) string {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	t.Run("compact by default", func(t *testing.T) {
+		body := get(&config.Config{}, "/api/v1/books/1")
+		if !strings.HasPrefix(body, `{"status":"success",`) {
+			t.Errorf("got %s, want compact JSON", body)
+		}
+	})
+
+	t.Run("pretty query parameter", func(t *testing.T) {
+		body := get(&config.Config{}, "/api/v1/books/1?pretty=true")
+		if !strings.HasPrefix(body, "{\n  \"status\": \"success\",\n") {
+			t.Errorf("got %s, want indented JSON", body)
+		}
+		if !strings.Contains(body, "\n    \"title\": \"Clean Code\",\n") {
+			t.Errorf("got %s, want indented book fields", body)
+		}
+	})
+
+	t.Run("config enables and query disables", func(t *testing.T) {
+		if body := get(&config.Config{PrettyJSON: true}, "/api/v1/books/1"); !strings.HasPrefix(body, "{\n  ") {
+			t.Errorf("got %s, want indented JSON from config", body)
+		}
+		if body := get(&config.Config{PrettyJSON: true}, "/api/v1/books/1?pretty=false"); !strings.HasPrefix(body, `{"status"`) {
+			t.Errorf("got %s, want compact JSON with pretty=false", body)
+		}
+	})
+}
+
+func TestBookHandler_PublicIDs(t *testing.T) {
+	get := func(cfg *config.Config, path string) (int, map[string]interface{}) {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, body.Data
+	}
+
+	publicID := sampleBook().PublicID
+
+	t.Run("lookup by public ID when enabled", func(t *testing.T) {
+		code, data := get(&config.Config{PublicIDs: true}, "/api/v1/books/"+publicID)
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if data["public_id"] != publicID {
+			t.Errorf("public_id = %v, want %s", data["public_id"], publicID)
+		}
+		if _, ok := data["id"]; ok {
+			t.Errorf("got %v, want integer id to be hidden", data["id"])
+		}
+	})
+
+	t.Run("integer ID rejected when enabled", func(t *testing.T) {
+		if code, _ := get(&config.Config{PublicIDs: true}, "/api/v1/books/1"); code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", code)
+		}
+	})
+
+	t.Run("integer IDs unaffected when disabled", func(t *testing.T) {
+		code, data := get(&config.Config{}, "/api/v1/books/1")
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if data["id"] != float64(1) {
+			t.Errorf("id = %v, want 1", data["id"])
+		}
+		if code, _ := get(&config.Config{}, "/api/v1/books/"+publicID); code != http.StatusBadRequest {
+			t.Errorf("got %d, want status 400 for a public ID", code)
+		}
+	})
+}
+
+func TestBookHandler_ExportCatalog(t *testing.T) {
+	export := func(svc *stubBookService, query string) (int, domain.ExportResult) {
+		router := newTestRouter(svc, adminConfig())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/export"+query, nil)
+		req.Header.Set("X-API-Key", adminKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var body struct {
+			Data domain.ExportResult `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, body.Data
+	}
+
+	t.Run("defaults to json", func(t *testing.T) {
+		code, result := export(newStubBookService(), "")
+		if code != http.StatusCreated {
+			t.Errorf("status = %d, want 201", code)
+		}
+		if result.Key != "catalog-test.json" || result.Size != 42 {
+			t.Errorf("Unexpected result %+v", result)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		if _, result := export(newStubBookService(), "?format=csv"); result.Format != domain.ExportFormatCSV {
+			t.Errorf("got %q, want csv format", result.Format)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if code, _ := export(newStubBookService(), "?format=xlsx"); code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", code)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		svc := newStubBookService()
+		svc.exportErr = service.ErrExportNotConfigured
+		if code, _ := export(svc, ""); code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", code)
+		}
+	})
+}
+
+func TestBookHandler_UpdateBookImmutableField(t *testing.T) {
+	svc := newStubBookService(sampleBook())
+	svc.updateErr = fmt.Errorf("isbn %w", service.ErrImmutableField)
+	router := newTestRouter(svc, &config.Config{})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/books/1", strings.NewReader(`{"isbn":"978-0987654321"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", rec.Code, rec.Body.String())
+	}
+	var resp Response
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Error != "isbn is immutable and cannot be changed" {
+		t.Errorf("Unexpected error message %q", resp.Error)
+	}
+}
+
+func TestBookHandler_DefaultAvailableOnly(t *testing.T) {
+	checkedOut := sampleBook()
+	checkedOut.ID = 2
+	checkedOut.ISBN = "978-0201633610"
+	checkedOut.Available = false
+
+	cfg := &config.Config{
+		DefaultAvailableOnly: true,
+		APIKeys: []config.APIKey{
+			{Hash: hashAPIKey("editor-key")},
+			{Hash: hashAPIKey("admin-key"), Role: RoleAdmin},
+		},
+	}
+
+	list := func(cfg *config.Config, query, key string) []domain.Book {
+		router := newTestRouter(newStubBookService(sampleBook(), checkedOut), cfg)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/books"+query, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Data struct {
+				Books []domain.Book `json:"books"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return body.Data.Books
+	}
+
+	tests := []struct {
+		name  string
+		cfg   *config.Config
+		query string
+		key   string
+		want  int
+	}{
+		{"default on hides checked-out books", cfg, "", "", 1},
+		{"non-admin key still filtered", cfg, "", "editor-key", 1},
+		{"admin key sees everything", cfg, "", "admin-key", 2},
+		{"explicit false overrides", cfg, "?available=false", "", 1},
+		{"explicit empty lists everything", cfg, "?available=", "", 2},
+		{"explicit any lists everything", cfg, "?available=any", "", 2},
+		{"disabled lists everything", &config.Config{}, "", "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := list(tt.cfg, tt.query, tt.key)
+			if len(books) != tt.want {
+				t.Fatalf("got %d, want %d books", len(books), tt.want)
+			}
+		})
+	}
+
+	t.Run("explicit false returns checked-out books", func(t *testing.T) {
+		books := list(cfg, "?available=false", "")
+		if len(books) != 1 || books[0].Available {
+			t.Errorf("got %+v, want only the checked-out book", books)
+		}
+	})
+}
+
+func TestBookHandler_GetCitation(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	tests := []struct {
+		query       string
+		status      int
+		contentType string
+		prefix      string
+	}{
+		{"", http.StatusOK, "text/plain; charset=utf-8", "Martin, R. C. (2008). Clean Code."},
+		{"?style=mla", http.StatusOK, "text/plain; charset=utf-8", "Martin, Robert C. Clean Code."},
+		{"?style=bibtex", http.StatusOK, "application/x-bibtex; charset=utf-8", "@book{martin2008clean,"},
+		{"?style=chicago", http.StatusBadRequest, "application/json; charset=utf-8", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("style"+tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/books/1/citation"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.prefix) {
+				t.Errorf("got %q, want body to start with %q", rec.Body.String(), tt.prefix)
+			}
+		})
+	}
+
+	t.Run("unknown book", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/books/99/citation", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestBookHandler_CreateBookDuplicateWarning(t *testing.T) {
+	create := func(cfg *config.Config, title string) Response {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		body := `{"title":"` + title + `","author":"robert c. martin","isbn":"978-0201633610","publisher":"Prentice Hall","publish_year":2009,"genre":"Programming","pages":431}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body.String())
+		}
+		var resp Response
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	enabled := &config.Config{WarnDuplicateTitles: true}
+
+	t.Run("warns on same title and author", func(t *testing.T) {
+		resp := create(enabled, "Clean Code")
+		want := "Possible duplicate: book with ISBN 978-0132350884 has the same title and author"
+		if len(resp.Warnings) != 1 || resp.Warnings[0] != want {
+			t.Errorf("warning = %v, want %q", resp.Warnings, want)
+		}
+	})
+
+	t.Run("no warning for a different title", func(t *testing.T) {
+		if resp := create(enabled, "Clean Architecture"); len(resp.Warnings) != 0 {
+			t.Errorf("got %v, want no warnings", resp.Warnings)
+		}
+	})
+
+	t.Run("no warning when disabled", func(t *testing.T) {
+		if resp := create(&config.Config{}, "Clean Code"); len(resp.Warnings) != 0 {
+			t.Errorf("got %v, want no warnings", resp.Warnings)
+		}
+	})
+
+	t.Run("xml lists warnings only when present", func(t *testing.T) {
+		createXML := func(title string) string {
+			router := newTestRouter(newStubBookService(sampleBook()), enabled)
+			body := `{"title":"` + title + `","author":"robert c. martin","isbn":"978-0201633610","publisher":"Prentice Hall","publish_year":2009,"genre":"Programming","pages":431}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(body))
+			req.Header.Set("Accept", "application/xml")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body.String())
+			}
+			return rec.Body.String()
+		}
+
+		if body := createXML("Clean Code"); !strings.Contains(body, "<warnings><warning>Possible duplicate:") {
+			t.Errorf("got %s, want the warning in the XML", body)
+		}
+		if body := createXML("Clean Architecture"); strings.Contains(body, "<warnings") {
+			t.Errorf("got %s, want no warnings element", body)
+		}
+	})
+}
+
+func TestBookHandler_FuturePublishYearWarning(t *testing.T) {
+	nextYear := time.Now().Year() + 1
+	want := fmt.Sprintf("publish_year %d is after the current year %d; check for a typo", nextYear, nextYear-1)
+
+	send := func(cfg *config.Config, method, target, body string, status int) Response {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != status {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body.String())
+		}
+		var resp Response
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp
+	}
+	create := func(cfg *config.Config, year int) Response {
+		body := fmt.Sprintf(`{"title":"Upcoming","author":"Author","isbn":"978-0201633610","publisher":"Publisher","publish_year":%d,"genre":"Fiction","pages":100}`, year)
+		return send(cfg, http.MethodPost, "/api/v1/books", body, http.StatusCreated)
+	}
+	update := func(cfg *config.Config, body string) Response {
+		return send(cfg, http.MethodPut, "/api/v1/books/1", body, http.StatusOK)
+	}
+
+	enabled := &config.Config{WarnFuturePublishYear: true}
+
+	t.Run("create with a future year warns", func(t *testing.T) {
+		if resp := create(enabled, nextYear); len(resp.Warnings) != 1 || resp.Warnings[0] != want {
+			t.Errorf("warning = %v, want %q", resp.Warnings, want)
+		}
+	})
+
+	t.Run("create with the current year does not warn", func(t *testing.T) {
+		if resp := create(enabled, nextYear-1); len(resp.Warnings) != 0 {
+			t.Errorf("got %v, want no warnings", resp.Warnings)
+		}
+	})
+
+	t.Run("update setting a future year warns", func(t *testing.T) {
+		resp := update(enabled, fmt.Sprintf(`{"publish_year":%d}`, nextYear))
+		if len(resp.Warnings) != 1 || resp.Warnings[0] != want {
+			t.Errorf("warning = %v, want %q", resp.Warnings, want)
+		}
+	})
+
+	t.Run("update leaving the year alone does not warn", func(t *testing.T) {
+		if resp := update(enabled, `{"pages":500}`); len(resp.Warnings) != 0 {
+			t.Errorf("got %v, want no warnings", resp.Warnings)
+		}
+	})
+
+	t.Run("no warning when disabled", func(t *testing.T) {
+		if resp := create(&config.Config{}, nextYear); len(resp.Warnings) != 0 {
+			t.Errorf("got %v, want no warnings", resp.Warnings)
+		}
+	})
+}
+
+func TestBookHandler_GetBookSchema(t *testing.T) {
+	router := newTestRouter(newStubBookService(), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/schema", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Fields []domain.FieldSchema `json:"fields"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	fields := make(map[string]domain.FieldSchema)
+	for _, field := range body.Data.Fields {
+		fields[field.Name] = field
+	}
+
+	for _, name := range []string{"title", "author", "isbn", "publisher", "publish_year", "genre", "pages"} {
+		if !fields[name].Required {
+			t.Errorf("want %s to be required", name)
+		}
+	}
+	for _, name := range []string{"available", "description"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("want %s in schema", name)
+		} else if fields[name].Required {
+			t.Errorf("want %s to be optional", name)
+		}
+	}
+
+	title := fields["title"]
+	if title.Type != "string" || title.Min == nil || *title.Min != 1 || title.Max == nil || *title.Max != 255 {
+		t.Errorf("Unexpected title schema %+v", title)
+	}
+	if !title.Sortable || title.Filterable {
+		t.Errorf("got %+v, want title sortable but not filterable", title)
+	}
+
+	year := fields["publish_year"]
+	years := domain.DefaultPublishYears()
+	if year.Type != "integer" || year.Min == nil || *year.Min != years.Min || year.Max == nil || *year.Max != years.Max {
+		t.Errorf("Unexpected publish_year schema %+v", year)
+	}
+
+	if fields["isbn"].Format != "isbn" {
+		t.Errorf("got %q, want isbn format", fields["isbn"].Format)
+	}
+	if fields["available"].Type != "boolean" || !fields["available"].Filterable {
+		t.Errorf("Unexpected available schema %+v", fields["available"])
+	}
+	if fields["description"].Max == nil || *fields["description"].Max != 1000 {
+		t.Errorf("Unexpected description schema %+v", fields["description"])
+	}
+}
+
+func TestBookHandler_GetBooksNeedingAttention(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/attention?limit=5", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Books []domain.BookAttention `json:"books"`
+			Meta  struct {
+				Total int `json:"total"`
+				Limit int `json:"limit"`
+			} `json:"meta"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Data.Books) != 1 || body.Data.Books[0].Book.ID != 1 {
+		t.Fatalf("got %+v, want the flagged book", body.Data.Books)
+	}
+	if reasons := body.Data.Books[0].Reasons; len(reasons) != 1 || reasons[0] != domain.ReasonMissingDescription {
+		t.Errorf("got %v, want missing_description reason", reasons)
+	}
+	if body.Data.Meta.Total != 1 || body.Data.Meta.Limit != 5 {
+		t.Errorf("Unexpected meta %+v", body.Data.Meta)
+	}
+}
+
+func TestBookHandler_CacheControl(t *testing.T) {
+	cacheControl := func(cfg *config.Config, method, path string, header map[string]string) string {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"title":"Updated"}`))
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Header().Get("Cache-Control")
+	}
+
+	public := &config.Config{CacheMaxAge: time.Minute, CachePublic: true}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		method   string
+		path     string
+		header   map[string]string
+		expected string
+	}{
+		{"GET without max age", &config.Config{}, http.MethodGet, "/api/v1/books", nil, "no-cache"},
+		{"GET private by default", &config.Config{CacheMaxAge: time.Minute}, http.MethodGet, "/api/v1/books", nil, "private, max-age=60"},
+		{"GET public", public, http.MethodGet, "/api/v1/books/1", nil, "public, max-age=60"},
+		{"authenticated GET stays private", public, http.MethodGet, "/api/v1/books", map[string]string{"X-API-Key": "key"}, "private, max-age=60"},
+		{"GET error not cached", public, http.MethodGet, "/api/v1/books/999", nil, "no-store"},
+		{"POST not stored", public, http.MethodPost, "/api/v1/books/validate", nil, "no-store"},
+		{"PUT not stored", public, http.MethodPut, "/api/v1/books/1", nil, "no-store"},
+		{"health not stored", public, http.MethodGet, "/health", nil, "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheControl(tt.cfg, tt.method, tt.path, tt.header); got != tt.expected {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBookHandler_GetReadingTime(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{WordsPerPage: 300, WordsPerMinute: 200})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/1/reading-time", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var body struct {
+		Data domain.ReadingTime `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	book := sampleBook()
+	expected := (book.Pages*300 + 199) / 200
+	if body.Data.Minutes != expected || body.Data.WordsPerPage != 300 || body.Data.WordsPerMinute != 200 {
+		t.Errorf("got %+v, want %d minutes at 300 words/page and 200 wpm", body.Data, expected)
+	}
+}
+
+func TestBookHandler_GetBooksMaxResults(t *testing.T) {
+	var books []*domain.Book
+	for id := 1; id <= 5; id++ {
+		book := sampleBook()
+		book.ID = id
+		books = append(books, book)
+	}
+
+	getBooks := func(cfg *config.Config) (int, map[string]interface{}) {
+		router := newTestRouter(newStubBookService(books...), cfg)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+
+		var body struct {
+			Data struct {
+				Books []domain.Book          `json:"books"`
+				Meta  map[string]interface{} `json:"meta"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return len(body.Data.Books), body.Data.Meta
+	}
+
+	t.Run("over the cap is truncated", func(t *testing.T) {
+		count, meta := getBooks(&config.Config{MaxListResults: 3})
+		if count != 3 {
+			t.Errorf("got %d, want 3 books", count)
+		}
+		if meta["truncated"] != true {
+			t.Errorf("got %v, want meta.truncated", meta)
+		}
+	})
+
+	t.Run("at the cap is not truncated", func(t *testing.T) {
+		count, meta := getBooks(&config.Config{MaxListResults: 5})
+		if count != 5 {
+			t.Errorf("got %d, want 5 books", count)
+		}
+		if _, ok := meta["truncated"]; ok {
+			t.Errorf("got %v, want no truncated flag", meta)
+		}
+	})
+
+	t.Run("zero disables the cap", func(t *testing.T) {
+		if count, _ := getBooks(&config.Config{}); count != 5 {
+			t.Errorf("got %d, want 5 books", count)
+		}
+	})
+}
+
+func TestBookHandler_CheckISBNsExist(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	check := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/books/isbn/exists", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("mixed existing and missing", func(t *testing.T) {
+		rec := check(`{"isbns": ["978-0132350884", "9780132350884", "978-0000000000"]}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+
+		var body struct {
+			Data map[string]bool `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		want := map[string]bool{"978-0132350884": true, "9780132350884": true, "978-0000000000": false}
+		if len(body.Data) != len(want) {
+			t.Fatalf("got %v, want %v", body.Data, want)
+		}
+		for isbn, ok := range want {
+			if body.Data[isbn] != ok {
+				t.Errorf("got %v, want %s exists=%v", body.Data[isbn], isbn, ok)
+			}
+		}
+	})
+
+	t.Run("oversized batch", func(t *testing.T) {
+		isbns := make([]string, domain.MaxISBNExistsBatch+1)
+		for i := range isbns {
+			isbns[i] = fmt.Sprintf("978-%010d", i)
+		}
+		payload, _ := json.Marshal(map[string][]string{"isbns": isbns})
+		if rec := check(string(payload)); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		if rec := check(`{"isbns": []}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestBookHandler_DescriptionPlaceholder(t *testing.T) {
+	described := sampleBook()
+	described.ID = 2
+	described.Description = "A handbook of agile software craftsmanship"
+
+	getDescriptions := func(cfg *config.Config) map[int]string {
+		router := newTestRouter(newStubBookService(sampleBook(), described), cfg)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+
+		var body struct {
+			Data struct {
+				Books []domain.Book `json:"books"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		descriptions := make(map[int]string)
+		for _, book := range body.Data.Books {
+			descriptions[book.ID] = book.Description
+		}
+		return descriptions
+	}
+
+	t.Run("placeholder replaces a missing description", func(t *testing.T) {
+		descriptions := getDescriptions(&config.Config{DescriptionPlaceholder: "No description available"})
+		if descriptions[1] != "No description available" {
+			t.Errorf("got %q, want placeholder", descriptions[1])
+		}
+		if descriptions[2] != described.Description {
+			t.Errorf("got %q, want stored description kept", descriptions[2])
+		}
+	})
+
+	t.Run("unset keeps the empty description", func(t *testing.T) {
+		if descriptions := getDescriptions(&config.Config{}); descriptions[1] != "" {
+			t.Errorf("got %q, want empty description", descriptions[1])
+		}
+	})
+}
+
+func TestBookHandler_ListDescriptionLength(t *testing.T) {
+	book := sampleBook()
+	book.Description = "A handbook of agile software craftsmanship"
+	router := newTestRouter(newStubBookService(book), &config.Config{ListDescriptionLength: 20})
+
+	get := func(path string, out interface{}) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+
+	var list struct {
+		Data struct {
+			Books []domain.Book `json:"books"`
+		} `json:"data"`
+	}
+	get("/api/v1/books", &list)
+	if len(list.Data.Books) != 1 {
+		t.Fatalf("got %d, want 1 book", len(list.Data.Books))
+	}
+	if got := list.Data.Books[0]; got.Description != "A handbook of agile…" || !got.DescriptionTruncated {
+		t.Errorf("got %q (truncated %v), want a truncated description on the list", got.Description, got.DescriptionTruncated)
+	}
+
+	var single struct {
+		Data domain.Book `json:"data"`
+	}
+	get("/api/v1/books/1", &single)
+	if single.Data.Description != book.Description || single.Data.DescriptionTruncated {
+		t.Errorf("got %q (truncated %v), want the full description on a single book", single.Data.Description, single.Data.DescriptionTruncated)
+	}
+}
+
+func TestBookHandler_MinSearchLength(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{MinSearchLength: 2})
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"below minimum", "/api/v1/books?search=a", http.StatusBadRequest},
+		{"blank search", "/api/v1/books?search=%20%20", http.StatusBadRequest},
+		{"below minimum on v2", "/api/v2/books?search=a", http.StatusBadRequest},
+		{"at minimum", "/api/v1/books?search=go", http.StatusOK},
+		{"counts characters", "/api/v1/books?search=%C3%A9%C3%A9", http.StatusOK},
+		{"empty search", "/api/v1/books?search=", http.StatusOK},
+		{"no search", "/api/v1/books", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.expected == http.StatusBadRequest && !strings.Contains(rec.Body.String(), "at least 2 characters") {
+				t.Errorf("got %s, want the minimum in the error", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBookHandler_DescriptionMaxLength(t *testing.T) {
+	svc := newStubBookService(sampleBook())
+	svc.descriptionMaxLength = 10
+	router := newTestRouter(svc, &config.Config{DescriptionMaxLength: 10})
+
+	create := func(description string) string {
+		return `{"title":"Clean Code","author":"Robert C. Martin","isbn":"978-0132350884","publisher":"Prentice Hall",` +
+			`"publish_year":2008,"genre":"Programming","pages":464,"description":"` + description + `"}`
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"create at limit", http.MethodPost, "/api/v1/books", create("0123456789"), http.StatusCreated},
+		{"create over limit", http.MethodPost, "/api/v1/books", create("0123456789a"), http.StatusUnprocessableEntity},
+		{"create counts characters", http.MethodPost, "/api/v1/books", create("éééééééééé"), http.StatusCreated},
+		{"update at limit", http.MethodPut, "/api/v1/books/1", `{"description":"0123456789"}`, http.StatusOK},
+		{"update over limit", http.MethodPut, "/api/v1/books/1", `{"description":"0123456789a"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.expected == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), "at most 10 characters") {
+				t.Errorf("got %s, want the limit in the error", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBookHandler_ListContentHash(t *testing.T) {
+	second := sampleBook()
+	second.ID = 2
+	second.ISBN = "978-0201633610"
+	router := newTestRouter(newStubBookService(sampleBook(), second), &config.Config{ListContentHash: true, PublicIDs: true})
+
+	hash := func(path string) string {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		return rec.Header().Get("X-Content-Hash")
+	}
+
+	before := hash("/api/v1/books")
+	if before == "" {
+		t.Fatal("want an X-Content-Hash header")
+	}
+	if again := hash("/api/v1/books"); again != before {
+		t.Errorf("got %q and %q, want the same hash for unchanged books", before, again)
+	}
+	if page := hash("/api/v2/books?limit=1"); page == before {
+		t.Error("want a page with fewer books to hash differently")
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/v1/books/"+second.PublicID, strings.NewReader(`{"pages":400}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if after := hash("/api/v1/books"); after == before {
+		t.Error("want the hash to change after a book was updated")
+	}
+}
+
+func TestBookHandler_GetDistinctValues(t *testing.T) {
+	second := sampleBook()
+	second.ID = 2
+	second.ISBN = "978-0201633610"
+	second.Author = "Gang of Four"
+	second.PublishYear = 1994
+	third := sampleBook()
+	third.ID = 3
+	third.ISBN = "978-1491950357"
+	third.Publisher = "O'Reilly Media"
+	third.Genre = "Architecture"
+	router := newTestRouter(newStubBookService(sampleBook(), second, third), &config.Config{})
+
+	tests := []struct {
+		query string
+		want  []domain.DistinctValue
+	}{
+		{"field=genre", []domain.DistinctValue{{Value: "Architecture", Count: 1}, {Value: "Programming", Count: 2}}},
+		{"field=author", []domain.DistinctValue{{Value: "Gang of Four", Count: 1}, {Value: "Robert C. Martin", Count: 2}}},
+		{"field=publisher", []domain.DistinctValue{{Value: "O'Reilly Media", Count: 1}, {Value: "Prentice Hall", Count: 2}}},
+		{"field=publish_year", []domain.DistinctValue{{Value: "1994", Count: 1}, {Value: "2008", Count: 2}}},
+		{"field=publisher&genre=programming", []domain.DistinctValue{{Value: "Prentice Hall", Count: 2}}},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/distinct?"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", tt.query, rec.Code, rec.Body.String())
+		}
+
+		var response struct {
+			Data struct {
+				Field  string                 `json:"field"`
+				Values []domain.DistinctValue `json:"values"`
+				Meta   map[string]interface{} `json:"meta"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(response.Data.Values, tt.want) {
+			t.Errorf("%s: values = %+v, want %+v", tt.query, response.Data.Values, tt.want)
+		}
+		if response.Data.Meta["total"] != float64(len(tt.want)) {
+			t.Errorf("%s: total = %v, want %d", tt.query, response.Data.Meta["total"], len(tt.want))
+		}
+	}
+
+	for _, query := range []string{"", "field=title", "field=isbn", "field=GENRE"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/distinct?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestBookHandler_CreateAuthority(t *testing.T) {
+	router := newTestRouter(newStubBookService(), adminConfig())
+
+	send := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", adminKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send("/api/v1/admin/authorities/authors", `{"name":"Robert C. Martin"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Data domain.Authority `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Kind != domain.AuthorityAuthor || response.Data.Name != "Robert C. Martin" {
+		t.Errorf("Unexpected authority %+v", response.Data)
+	}
+
+	if rec := send("/api/v1/admin/authorities/authors", `{"name":"robert c. martin"}`); rec.Code != http.StatusOK {
+		t.Errorf("got %d, want status 200 for a known name: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send("/api/v1/admin/authorities/publishers", `{"name":"Robert C. Martin"}`); rec.Code != http.StatusCreated {
+		t.Errorf("got %d, want status 201 for a new publisher: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send("/api/v1/admin/authorities/authors", `{"name":"  "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want status 400 for a blank name", rec.Code)
+	}
+	if rec := send("/api/v1/admin/authorities/genres", `{"name":"Programming"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("got %d, want status 404 for an unknown kind", rec.Code)
+	}
+}
+
+func TestBookHandler_UnknownAuthority(t *testing.T) {
+	svc := newStubBookService(sampleBook())
+	svc.updateErr = fmt.Errorf("author %q %w", "Nobody", domain.ErrUnknownAuthority)
+	router := newTestRouter(svc, &config.Config{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/v1/books/1", strings.NewReader(`{"author":"Nobody"}`)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "is not a known authority") {
+		t.Errorf("got %s, want the unknown authority in the error", rec.Body.String())
+	}
+}
+
+func TestBookHandler_GetGrowthRejectsBadParameters(t *testing.T) {
+	router := newTestRouter(newStubBookService(), &config.Config{})
+
+	for _, query := range []string{
+		"interval=year",
+		"from=2024-13-01",
+		"to=yesterday",
+		"from=2024-02-01&to=2024-01-01",
+		"interval=day&from=1990-01-01&to=2024-01-01",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stats/growth?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestBookHandler_GetRandomBook(t *testing.T) {
+	fiction := sampleBook()
+	fiction.ID = 2
+	fiction.Genre = "Fiction"
+	checkedOut := sampleBook()
+	checkedOut.ID = 3
+	checkedOut.Genre = "Poetry"
+	checkedOut.Available = false
+	router := newTestRouter(newStubBookService(sampleBook(), fiction, checkedOut), &config.Config{})
+
+	random := func(query string) (int, *domain.Book) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/random"+query, nil))
+
+		var body struct {
+			Data *domain.Book `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, body.Data
+	}
+
+	t.Run("genre filter", func(t *testing.T) {
+		code, book := random("?genre=fiction")
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if book == nil || book.Genre != "Fiction" {
+			t.Errorf("got %+v, want a Fiction book", book)
+		}
+	})
+
+	t.Run("unavailable books excluded by default", func(t *testing.T) {
+		if code, _ := random("?genre=poetry"); code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", code)
+		}
+	})
+
+	t.Run("available override", func(t *testing.T) {
+		code, book := random("?genre=poetry&available=any")
+		if code != http.StatusOK || book == nil || book.ID != 3 {
+			t.Errorf("got %d %+v, want the checked-out Poetry book", code, book)
+		}
+	})
+
+	t.Run("invalid available", func(t *testing.T) {
+		if code, _ := random("?available=sometimes"); code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", code)
+		}
+	})
+
+	t.Run("empty catalog", func(t *testing.T) {
+		empty := newTestRouter(newStubBookService(), &config.Config{})
+		rec := httptest.NewRecorder()
+		empty.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/random", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
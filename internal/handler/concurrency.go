@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"library-management/pkg/realip"
+)
+
+// concurrencyLimiter caps how many requests are in flight at once, globally
+// and per client IP. A request that finds no free slot waits up to wait for
+// one, or is rejected immediately when wait is zero.
+type concurrencyLimiter struct {
+	global chan struct{} // nil when there is no global limit
+	perIP  int
+	wait   time.Duration
+	ips    *realip.Resolver
+
+	mu      sync.Mutex
+	clients map[string]*clientSlots
+}
+
+// clientSlots holds one client IP's semaphore and how many requests are
+// holding or waiting on it, so idle clients can be forgotten
+type clientSlots struct {
+	sem   chan struct{}
+	users int
+}
+
+// newConcurrencyLimiter creates a limiter; a zero limit disables that check
+func newConcurrencyLimiter(global, perIP int, wait time.Duration, ips *realip.Resolver) *concurrencyLimiter {
+	l := &concurrencyLimiter{
+		perIP:   perIP,
+		wait:    wait,
+		ips:     ips,
+		clients: make(map[string]*clientSlots),
+	}
+	if global > 0 {
+		l.global = make(chan struct{}, global)
+	}
+	return l
+}
+
+// acquire claims a per-IP and a global slot for the request, returning a
+// function that frees them, or false when none became free in time
+func (l *concurrencyLimiter) acquire(r *http.Request) (func(), bool) {
+	var timeout <-chan time.Time
+	if l.wait > 0 {
+		timer := time.NewTimer(l.wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	done := r.Context().Done()
+
+	var client *clientSlots
+	ip := l.ips.ClientIP(r)
+	if l.perIP > 0 {
+		client = l.claimClient(ip)
+		if !acquireSlot(client.sem, timeout, done) {
+			l.releaseClient(ip, false)
+			return nil, false
+		}
+	}
+
+	if l.global != nil && !acquireSlot(l.global, timeout, done) {
+		if client != nil {
+			l.releaseClient(ip, true)
+		}
+		return nil, false
+	}
+
+	return func() {
+		if l.global != nil {
+			<-l.global
+		}
+		if client != nil {
+			l.releaseClient(ip, true)
+		}
+	}, true
+}
+
+// claimClient returns the slots for ip, registering the caller as a user
+func (l *concurrencyLimiter) claimClient(ip string) *clientSlots {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientSlots{sem: make(chan struct{}, l.perIP)}
+		l.clients[ip] = client
+	}
+	client.users++
+	return client
+}
+
+// releaseClient unregisters a user of ip's slots, freeing its slot if it
+// held one, and forgets the client once nobody is using it
+func (l *concurrencyLimiter) releaseClient(ip string, held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client := l.clients[ip]
+	if held {
+		<-client.sem
+	}
+	client.users--
+	if client.users == 0 {
+		delete(l.clients, ip)
+	}
+}
+
+// acquireSlot takes a slot from sem, waiting until timeout or done when it
+// is full; a nil timeout means do not wait
+func acquireSlot(sem chan struct{}, timeout <-chan time.Time, done <-chan struct{}) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+	if timeout == nil {
+		return false
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-done:
+		return false
+	}
+}
+
+// concurrencyMiddleware rejects requests with 503 when the limiter has no
+// free slot. Health probes bypass the limiter so a busy instance still
+// reports itself alive.
+func concurrencyMiddleware(limiter *concurrencyLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || r.URL.Path == "/ready" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			release, ok := limiter.acquire(r)
+			if !ok {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"status":"error","error":"Server is busy, please retry"}` + "\n"))
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"library-management/pkg/realip"
+)
+
+// blockingHandler holds each request until release is closed, signalling
+// entered once it is in flight
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{entered: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.entered <- struct{}{}
+	<-b.release
+	w.WriteHeader(http.StatusOK)
+}
+
+func requestFrom(ip string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+	req.RemoteAddr = ip + ":1234"
+	return req
+}
+
+// serveInBackground starts a request and waits until it is in flight
+func serveInBackground(t *testing.T, handler http.Handler, blocking *blockingHandler, req *http.Request, wg *sync.WaitGroup) {
+	t.Helper()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	select {
+	case <-blocking.entered:
+	case <-time.After(time.Second):
+		t.Fatal("Request never reached the handler")
+	}
+}
+
+func TestConcurrencyMiddleware_GlobalLimit(t *testing.T) {
+	blocking := newBlockingHandler()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		blocking.ServeHTTP(w, r)
+	})
+	handler := concurrencyMiddleware(newConcurrencyLimiter(2, 0, 0, realip.New(nil)))(next)
+
+	var wg sync.WaitGroup
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.1"), &wg)
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.2"), &wg)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, requestFrom("192.0.2.3"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want status 503 when saturated", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("want a Retry-After header")
+	}
+
+	health := httptest.NewRecorder()
+	handler.ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if health.Code != http.StatusOK {
+		t.Errorf("got %d, want health probe to bypass the limiter", health.Code)
+	}
+
+	// Slots are freed once requests finish
+	close(blocking.release)
+	wg.Wait()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, requestFrom("192.0.2.3"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want status 200 after requests finished", rec.Code)
+	}
+}
+
+func TestConcurrencyMiddleware_PerIPLimit(t *testing.T) {
+	blocking := newBlockingHandler()
+	limiter := newConcurrencyLimiter(0, 1, 0, realip.New(nil))
+	handler := concurrencyMiddleware(limiter)(blocking)
+
+	var wg sync.WaitGroup
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.1"), &wg)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, requestFrom("192.0.2.1"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want status 503 for the same IP", rec.Code)
+	}
+
+	// Another client is unaffected
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.2"), &wg)
+
+	close(blocking.release)
+	wg.Wait()
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if len(limiter.clients) != 0 {
+		t.Errorf("got %d, want idle clients to be forgotten", len(limiter.clients))
+	}
+}
+
+func TestConcurrencyMiddleware_QueueTimeout(t *testing.T) {
+	blocking := newBlockingHandler()
+	handler := concurrencyMiddleware(newConcurrencyLimiter(1, 0, 50*time.Millisecond, realip.New(nil)))(blocking)
+
+	var wg sync.WaitGroup
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.1"), &wg)
+
+	t.Run("waits out the timeout then rejects", func(t *testing.T) {
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, requestFrom("192.0.2.2"))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", rec.Code)
+		}
+		if waited := time.Since(start); waited < 50*time.Millisecond {
+			t.Errorf("want to wait for the queue timeout, waited %v", waited)
+		}
+	})
+
+	t.Run("queued request runs once a slot frees", func(t *testing.T) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(blocking.release)
+		}()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, requestFrom("192.0.2.2"))
+		if rec.Code != http.StatusOK {
+			t.Errorf("got %d, want queued request to succeed", rec.Code)
+		}
+	})
+
+	wg.Wait()
+}
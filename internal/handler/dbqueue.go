@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dbQueueRetryAfter is the Retry-After hint, in seconds, sent when a
+// request cannot get a database slot
+const dbQueueRetryAfter = "1"
+
+// dbQueue admits as many API requests as the database pool has connections.
+// Up to maxWaiting more wait, each for at most wait; beyond that requests are
+// turned away at once, so clients get fast backpressure instead of blocking
+// on the pool until their context times out. It is safe for concurrent use.
+type dbQueue struct {
+	slots      chan struct{}
+	maxWaiting int
+	wait       time.Duration
+
+	mu       sync.Mutex
+	waiting  int
+	rejected int64
+	timedOut int64
+}
+
+// newDBQueue creates a queue for a pool of size connections
+func newDBQueue(size, maxWaiting int, wait time.Duration) *dbQueue {
+	return &dbQueue{
+		slots:      make(chan struct{}, size),
+		maxWaiting: maxWaiting,
+		wait:       wait,
+	}
+}
+
+// acquire claims a slot for the request, returning a function that frees
+// it, or false when the queue is full or no slot frees in time
+func (q *dbQueue) acquire(r *http.Request) (func(), bool) {
+	release := func() { <-q.slots }
+	select {
+	case q.slots <- struct{}{}:
+		return release, true
+	default:
+	}
+
+	q.mu.Lock()
+	if q.waiting >= q.maxWaiting {
+		q.rejected++
+		q.mu.Unlock()
+		return nil, false
+	}
+	q.waiting++
+	q.mu.Unlock()
+
+	timer := time.NewTimer(q.wait)
+	defer timer.Stop()
+
+	acquired, expired := false, false
+	select {
+	case q.slots <- struct{}{}:
+		acquired = true
+	case <-timer.C:
+		expired = true
+	case <-r.Context().Done():
+	}
+
+	q.mu.Lock()
+	q.waiting--
+	if expired {
+		q.timedOut++
+	}
+	q.mu.Unlock()
+
+	if !acquired {
+		return nil, false
+	}
+	return release, true
+}
+
+// stats returns the queue depth and rejection counts for /ready
+func (q *dbQueue) stats() map[string]interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return map[string]interface{}{
+		"in_use":      len(q.slots),
+		"capacity":    cap(q.slots),
+		"waiting":     q.waiting,
+		"max_waiting": q.maxWaiting,
+		"rejected":    q.rejected,
+		"timed_out":   q.timedOut,
+	}
+}
+
+// write renders the queue depth and rejection counts in the Prometheus text
+// exposition format for /metrics
+func (q *dbQueue) write(w *strings.Builder) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, metric := range []struct {
+		name, kind, help string
+		value            int64
+	}{
+		{"db_queue_in_use", "gauge", "Database slots held by API requests.", int64(len(q.slots))},
+		{"db_queue_capacity", "gauge", "Database slots available to API requests.", int64(cap(q.slots))},
+		{"db_queue_waiting", "gauge", "API requests waiting for a database slot.", int64(q.waiting)},
+		{"db_queue_max_waiting", "gauge", "API requests that may wait for a database slot.", int64(q.maxWaiting)},
+		{"db_queue_rejected_total", "counter", "API requests turned away because the queue was full.", q.rejected},
+		{"db_queue_timed_out_total", "counter", "API requests that gave up waiting for a database slot.", q.timedOut},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		fmt.Fprintf(w, "%s %d\n", metric.name, metric.value)
+	}
+}
+
+// dbQueueMiddleware runs API requests through queue, answering 503 with
+// Retry-After through respond when no database slot is available. Health
+// probes and the web UI bypass it.
+func dbQueueMiddleware(queue *dbQueue, respond func(http.ResponseWriter, *http.Request, int, string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			release, ok := queue.acquire(r)
+			if !ok {
+				w.Header().Set("Retry-After", dbQueueRetryAfter)
+				respond(w, r, http.StatusServiceUnavailable, "Database is busy, please retry")
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"library-management/internal/config"
+	"library-management/pkg/logger"
+)
+
+// testHandler answers for the middleware under test, as SetupRoutes wires it
+var testHandler = &BookHandler{logger: logger.New()}
+
+func TestDBQueueMiddleware_Backpressure(t *testing.T) {
+	blocking := newBlockingHandler()
+	queue := newDBQueue(2, 1, 5*time.Second)
+	handler := dbQueueMiddleware(queue, testHandler.respondError)(blocking)
+
+	// Saturate the pool
+	var wg sync.WaitGroup
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.1"), &wg)
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.2"), &wg)
+
+	// The next request waits in the queue
+	queued := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(queued, requestFrom("192.0.2.3"))
+	}()
+	deadline := time.Now().Add(time.Second)
+	for queue.stats()["waiting"] != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Request never joined the queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// With the queue full, further requests are turned away at once
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, requestFrom("192.0.2.4"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want status 503 with the queue full", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("want a fast rejection, took %v", elapsed)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("want a Retry-After header")
+	}
+
+	// Rejections are negotiated like any other error
+	problem := requestFrom("192.0.2.5")
+	problem.Header.Set("Accept", "application/problem+json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, problem)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Errorf("Content-Type = %q, want application/problem+json", ct)
+	}
+
+	stats := queue.stats()
+	if stats["in_use"] != 2 || stats["capacity"] != 2 || stats["rejected"] != int64(2) {
+		t.Errorf("Unexpected queue stats %v", stats)
+	}
+
+	// Freeing a slot lets the queued request through
+	blocking.release <- struct{}{}
+	select {
+	case <-blocking.entered:
+	case <-time.After(time.Second):
+		t.Fatal("Queued request never reached the handler")
+	}
+	close(blocking.release)
+	wg.Wait()
+	if queued.Code != http.StatusOK {
+		t.Errorf("got %d, want the queued request to succeed", queued.Code)
+	}
+
+	// The web UI is not queued
+	ui := httptest.NewRecorder()
+	dbQueueMiddleware(newDBQueue(1, 0, 0), testHandler.respondError)(http.NotFoundHandler()).ServeHTTP(ui, httptest.NewRequest(http.MethodGet, "/", nil))
+	if ui.Code != http.StatusNotFound {
+		t.Errorf("got %d, want the web UI to bypass the queue", ui.Code)
+	}
+}
+
+func TestDBQueueMiddleware_WaitTimeout(t *testing.T) {
+	blocking := newBlockingHandler()
+	queue := newDBQueue(1, 5, 20*time.Millisecond)
+	handler := dbQueueMiddleware(queue, testHandler.respondError)(blocking)
+
+	var wg sync.WaitGroup
+	serveInBackground(t, handler, blocking, requestFrom("192.0.2.1"), &wg)
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, requestFrom("192.0.2.2"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want status 503 after the queue wait", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("want to wait about 20ms, took %v", elapsed)
+	}
+	if stats := queue.stats(); stats["timed_out"] != int64(1) || stats["waiting"] != 0 {
+		t.Errorf("Unexpected queue stats %v", stats)
+	}
+
+	close(blocking.release)
+	wg.Wait()
+}
+
+func TestBookHandler_DBQueueMetrics(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{
+		DatabaseMaxOpenConns: 4,
+		DatabaseQueueSize:    8,
+		DatabaseQueueTimeout: time.Second,
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE db_queue_waiting gauge",
+		"db_queue_in_use 0",
+		"db_queue_capacity 4",
+		"db_queue_waiting 0",
+		"db_queue_max_waiting 8",
+		"# TYPE db_queue_rejected_total counter",
+		"db_queue_rejected_total 0",
+		"db_queue_timed_out_total 0",
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "http_response_size_bytes") {
+		t.Errorf("metrics include the disabled size histogram:\n%s", body)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"library-management/internal/domain"
+	"library-management/internal/service"
+)
+
+// isClientError reports whether err was caused by the request rather than by
+// the server, judged by the sentinel errors the domain and service return
+func isClientError(err error) bool {
+	return errors.Is(err, domain.ErrBookNotFound) ||
+		errors.Is(err, domain.ErrDescriptionTooLong) ||
+		errors.Is(err, domain.ErrUnknownAuthority) ||
+		errors.Is(err, service.ErrValidation) ||
+		errors.Is(err, service.ErrDuplicateISBN) ||
+		errors.Is(err, service.ErrConfirmRequired) ||
+		errors.Is(err, service.ErrImmutableField)
+}
+
+// logRequestError logs a failed request with its error and class. Client
+// errors are logged at CLIENT_ERROR_LOG_LEVEL, warn by default, so expected
+// failures such as a missing book stay out of error-rate alerting; anything
+// else is a server error and logged at error.
+func (h *BookHandler) logRequestError(msg string, err error, args ...interface{}) {
+	if !isClientError(err) {
+		h.logger.Error(msg, append([]interface{}{"error", err, "error_class", "server"}, args...)...)
+		return
+	}
+
+	args = append([]interface{}{"error", err, "error_class", "client"}, args...)
+	level := ""
+	if h.config != nil {
+		level = strings.ToLower(h.config.ClientErrorLogLevel)
+	}
+	switch level {
+	case "debug":
+		h.logger.Debug(msg, args...)
+	case "info":
+		h.logger.Info(msg, args...)
+	case "error":
+		h.logger.Error(msg, args...)
+	default:
+		h.logger.Warn(msg, args...)
+	}
+}
+
+// respondLookupError answers a failed book lookup: 400 for a malformed key,
+// 404 when no book matched and 500 when the lookup itself failed
+func (h *BookHandler) respondLookupError(w http.ResponseWriter, r *http.Request, msg string, err error, args ...interface{}) {
+	h.logRequestError(msg, err, args...)
+	if errors.Is(err, service.ErrValidation) {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrBookNotFound) {
+		h.respondError(w, r, http.StatusNotFound, "Book not found")
+		return
+	}
+	h.respondError(w, r, http.StatusInternalServerError, "Failed to retrieve book")
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"library-management/internal/config"
+	"library-management/internal/service"
+	"library-management/pkg/logger"
+)
+
+func TestBookHandler_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		method    string
+		path      string
+		updateErr error
+		status    int
+		logged    string
+		class     string
+	}{
+		{"not found logs at warn", "", http.MethodGet, "/api/v1/books/99", nil, http.StatusNotFound, "WARN", "client"},
+		{"client level is configurable", "info", http.MethodGet, "/api/v1/books/99", nil, http.StatusNotFound, "INFO", "client"},
+		{"validation failure logs at warn", "", http.MethodPut, "/api/v1/books/1", nil, http.StatusBadRequest, "WARN", "client"},
+		{"server failure logs at error", "info", http.MethodPut, "/api/v1/books/1", errors.New("connection refused"), http.StatusInternalServerError, "ERROR", "server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			svc := newStubBookService(sampleBook())
+			svc.updateErr = tt.updateErr
+			router := mux.NewRouter()
+			db, _ := newFakeDB()
+			log := logger.NewWithOptions(logger.Options{Output: &buf})
+			SetupRoutes(router, NewHandlers(svc, &stubDatabase{DB: db}, log, &config.Config{ClientErrorLogLevel: tt.level}))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"title":"  "}`)))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			var entry string
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if strings.Contains(line, `"error_class"`) {
+					entry = line
+				}
+			}
+			if !strings.Contains(entry, `"level":"`+tt.logged+`"`) || !strings.Contains(entry, `"error_class":"`+tt.class+`"`) {
+				t.Errorf("got %q, want a %s entry classed %s", entry, tt.logged, tt.class)
+			}
+		})
+	}
+}
+
+func TestBookHandler_InvalidBookID(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicIDs bool
+		method    string
+		path      string
+	}{
+		{"zero", false, http.MethodGet, "/api/v1/books/0"},
+		{"negative", false, http.MethodGet, "/api/v1/books/-3"},
+		{"negative delete", false, http.MethodDelete, "/api/v1/books/-3"},
+		{"sequential ID under public IDs", true, http.MethodGet, "/api/v1/books/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			svc := service.NewBookService(&bookByIDRepository{book: sampleBook()})
+			router := mux.NewRouter()
+			db, _ := newFakeDB()
+			log := logger.NewWithOptions(logger.Options{Output: &buf})
+			SetupRoutes(router, NewHandlers(svc, &stubDatabase{DB: db}, log, &config.Config{PublicIDs: tt.publicIDs}))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			if strings.Contains(buf.String(), `"level":"ERROR"`) {
+				t.Errorf("got %q, want no error-level log", buf.String())
+			}
+		})
+	}
+}
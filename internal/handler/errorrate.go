@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// errorRateMinRequests is how many requests the window must hold before its
+// 5xx rate can mark the service degraded, so a single early failure does not
+// take an idle instance out of rotation
+const errorRateMinRequests = 10
+
+// errorRateBucket counts the requests seen during one second
+type errorRateBucket struct {
+	second int64
+	total  int
+	errors int
+}
+
+// errorRate tracks the 5xx rate over a sliding window of one-second buckets.
+// It is safe for concurrent use.
+type errorRate struct {
+	mu      sync.Mutex
+	buckets []errorRateBucket
+	now     func() time.Time
+}
+
+// newErrorRate returns a tracker covering the given window, rounded up to
+// whole seconds
+func newErrorRate(window time.Duration) *errorRate {
+	seconds := int((window + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &errorRate{buckets: make([]errorRateBucket, seconds), now: time.Now}
+}
+
+// record counts a response with the given status
+func (e *errorRate) record(status int) {
+	second := e.now().Unix()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// A bucket left over from an earlier pass around the ring is reset
+	bucket := &e.buckets[second%int64(len(e.buckets))]
+	if bucket.second != second {
+		*bucket = errorRateBucket{second: second}
+	}
+	bucket.total++
+	if status >= http.StatusInternalServerError {
+		bucket.errors++
+	}
+}
+
+// rate returns the fraction of requests in the window that failed with a
+// 5xx status, and how many requests the window holds
+func (e *errorRate) rate() (float64, int) {
+	oldest := e.now().Unix() - int64(len(e.buckets)) + 1
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var total, errors int
+	for _, bucket := range e.buckets {
+		if bucket.second >= oldest {
+			total += bucket.total
+			errors += bucket.errors
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(errors) / float64(total), total
+}
+
+// errorRateMiddleware records every response status in tracker. Health
+// probes are skipped so a degraded /ready does not feed its own error rate.
+func errorRateMiddleware(tracker *errorRate) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || r.URL.Path == "/ready" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			tracker.record(wrapped.statusCode)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"library-management/internal/config"
+	"library-management/pkg/logger"
+)
+
+func TestErrorRate_SlidingWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newErrorRate(10 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		tracker.record(http.StatusOK)
+	}
+	tracker.record(http.StatusInternalServerError)
+	tracker.record(http.StatusNotFound)
+
+	if rate, requests := tracker.rate(); requests != 5 || rate != 0.2 {
+		t.Errorf("got rate %v over %d, want 1 error in 5 requests", rate, requests)
+	}
+
+	now = now.Add(5 * time.Second)
+	tracker.record(http.StatusServiceUnavailable)
+	if rate, requests := tracker.rate(); requests != 6 || rate != 2.0/6 {
+		t.Errorf("got rate %v over %d, want 2 errors in 6 requests", rate, requests)
+	}
+
+	// The first second has left the window
+	now = now.Add(5 * time.Second)
+	if rate, requests := tracker.rate(); requests != 1 || rate != 1 {
+		t.Errorf("got rate %v over %d, want only the later error in the window", rate, requests)
+	}
+
+	// Reusing a bucket from an earlier pass starts it afresh
+	tracker.record(http.StatusOK)
+	now = now.Add(time.Minute)
+	tracker.record(http.StatusOK)
+	if rate, requests := tracker.rate(); requests != 1 || rate != 0 {
+		t.Errorf("got rate %v over %d, want one fresh request", rate, requests)
+	}
+}
+
+func TestErrorRateMiddleware(t *testing.T) {
+	tracker := newErrorRate(time.Minute)
+	handler := errorRateMiddleware(tracker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	for _, path := range []string{"/api/v1/books", "/ready", "/health"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if rate, requests := tracker.rate(); requests != 1 || rate != 1 {
+		t.Errorf("got rate %v over %d, want only the API request to be recorded", rate, requests)
+	}
+}
+
+func TestBookHandler_ReadyErrorRate(t *testing.T) {
+	setup := func() (*mux.Router, *errorRate) {
+		db, _ := newFakeDB()
+		handlers := NewHandlers(newStubBookService(), &stubDatabase{DB: db}, logger.New(), &config.Config{
+			ErrorRateThreshold: 0.5,
+			ErrorRateWindow:    time.Minute,
+		})
+		router := mux.NewRouter()
+		SetupRoutes(router, handlers)
+		return router, handlers.Book.errors
+	}
+
+	ready := func(router *mux.Router) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		errorRate, _ := body.Data["error_rate"].(map[string]interface{})
+		return rec.Code, errorRate
+	}
+
+	t.Run("below threshold is ready", func(t *testing.T) {
+		router, tracker := setup()
+		for i := 0; i < 20; i++ {
+			status := http.StatusOK
+			if i%4 == 0 {
+				status = http.StatusInternalServerError
+			}
+			tracker.record(status)
+		}
+
+		code, errorRate := ready(router)
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if errorRate["status"] != "ok" || errorRate["rate"] != 0.25 {
+			t.Errorf("Unexpected error rate detail %v", errorRate)
+		}
+	})
+
+	t.Run("above threshold is degraded", func(t *testing.T) {
+		router, tracker := setup()
+		for i := 0; i < 20; i++ {
+			status := http.StatusInternalServerError
+			if i%4 == 0 {
+				status = http.StatusOK
+			}
+			tracker.record(status)
+		}
+
+		code, errorRate := ready(router)
+		if code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", code)
+		}
+		if errorRate["status"] != "degraded" || errorRate["rate"] != 0.75 {
+			t.Errorf("Unexpected error rate detail %v", errorRate)
+		}
+
+		// The degraded /ready response is not itself counted
+		if _, requests := tracker.rate(); requests != 20 {
+			t.Errorf("got %d, want 20 recorded requests", requests)
+		}
+	})
+
+	t.Run("degraded response is negotiated", func(t *testing.T) {
+		router, tracker := setup()
+		for i := 0; i < 20; i++ {
+			tracker.record(http.StatusInternalServerError)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+		req.Header.Set("Accept", "application/xml")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/xml") {
+			t.Errorf("Content-Type = %q, want application/xml", got)
+		}
+	})
+
+	t.Run("too few requests is ready", func(t *testing.T) {
+		router, tracker := setup()
+		tracker.record(http.StatusInternalServerError)
+
+		if code, _ := ready(router); code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"library-management/internal/domain"
+	"library-management/internal/jobs"
+)
+
+// jobRetryAfter is the Retry-After hint, in seconds, sent with queued and
+// rejected jobs
+const jobRetryAfter = "5"
+
+// exportCatalogJob runs a catalog export through the job limiter. With a
+// slot free it completes in the request as usual; otherwise the export is
+// queued and the client gets 202 with the job to poll, or 503 once the
+// queue is full too.
+func (h *BookHandler) exportCatalogJob(w http.ResponseWriter, r *http.Request, format domain.ExportFormat) {
+	job, err := h.jobs.Run(r.Context(), "export", func(ctx context.Context) (interface{}, error) {
+		return h.service.ExportCatalog(ctx, format)
+	})
+	if errors.Is(err, jobs.ErrBusy) || errors.Is(err, jobs.ErrShuttingDown) {
+		w.Header().Set("Retry-After", jobRetryAfter)
+		h.respondError(w, r, http.StatusServiceUnavailable, "Too many jobs running, retry later")
+		return
+	}
+
+	if job.Status == jobs.StatusQueued {
+		w.Header().Set("Location", "/api/v1/admin/jobs/"+strconv.Itoa(job.ID))
+		w.Header().Set("Retry-After", jobRetryAfter)
+		h.respondSuccess(w, r, http.StatusAccepted, "Catalog export queued", job)
+		return
+	}
+
+	result, _ := job.Result.(*domain.ExportResult)
+	h.respondExport(w, r, format, result, err)
+}
+
+// GetJobs handles GET /api/v1/admin/jobs, listing running, queued and
+// recently finished jobs, newest first
+func (h *BookHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	list := []jobs.Job{}
+	if h.jobs != nil {
+		list = h.jobs.List()
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondSuccess(w, r, http.StatusOK, "Jobs retrieved successfully", map[string]interface{}{
+		"jobs": list,
+	})
+}
+
+// GetJob handles GET /api/v1/admin/jobs/{id}
+func (h *BookHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	var job jobs.Job
+	found := false
+	if h.jobs != nil {
+		job, found = h.jobs.Get(id)
+	}
+	if !found {
+		h.respondError(w, r, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondSuccess(w, r, http.StatusOK, "Job retrieved successfully", job)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"library-management/internal/jobs"
+	"library-management/pkg/logger"
+)
+
+func TestBookHandler_ExportCatalogJobLimit(t *testing.T) {
+	limiter := jobs.NewLimiter(1, 1)
+	router := mux.NewRouter()
+	db, _ := newFakeDB()
+	SetupRoutes(router, NewHandlers(newStubBookService(), &stubDatabase{DB: db}, logger.New(), adminConfig(), WithJobs(limiter)))
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", adminKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// With a slot free the export completes in the request
+	if rec := send(http.MethodPost, "/api/v1/admin/export"); rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+
+	// Saturate the limiter with a job that holds its slot
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go limiter.Run(context.Background(), "export", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+
+	queued := send(http.MethodPost, "/api/v1/admin/export?format=csv")
+	if queued.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", queued.Code, queued.Body.String())
+	}
+	if queued.Header().Get("Retry-After") == "" {
+		t.Error("want a Retry-After hint on a queued export")
+	}
+	location := queued.Header().Get("Location")
+	if location != "/api/v1/admin/jobs/3" {
+		t.Errorf("got %q, want Location /api/v1/admin/jobs/3", location)
+	}
+
+	rejected := send(http.MethodPost, "/api/v1/admin/export")
+	if rejected.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503: %s", rejected.Code, rejected.Body.String())
+	}
+	if rejected.Header().Get("Retry-After") == "" {
+		t.Error("want a Retry-After hint on a rejected export")
+	}
+
+	var list struct {
+		Data struct {
+			Jobs []jobs.Job `json:"jobs"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(send(http.MethodGet, "/api/v1/admin/jobs").Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.Data.Jobs) != 3 || list.Data.Jobs[0].Status != jobs.StatusQueued || list.Data.Jobs[1].Status != jobs.StatusRunning {
+		t.Errorf("Unexpected jobs %+v", list.Data.Jobs)
+	}
+
+	// Once the slot frees, the queued export runs and reports its result
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	var job struct {
+		Data struct {
+			Status jobs.Status `json:"status"`
+			Result struct {
+				Key string `json:"key"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	for time.Now().Before(deadline) {
+		rec := send(http.MethodGet, location)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&job); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if job.Data.Status == jobs.StatusSucceeded {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if job.Data.Status != jobs.StatusSucceeded || job.Data.Result.Key != "catalog-test.csv" {
+		t.Errorf("got %+v, want the queued export to succeed", job.Data)
+	}
+
+	if rec := send(http.MethodGet, "/api/v1/admin/jobs/99"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
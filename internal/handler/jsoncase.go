@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// JSON keys are snake_case. Responses switch to camelCase when the request
+// sets case=camel, and request bodies are accepted in either casing. Both
+// directions rewrite keys on the encoded JSON, so the domain types keep a
+// single set of struct tags.
+
+// wantsCamelCase reports whether the request asked for camelCase JSON keys
+func wantsCamelCase(r *http.Request) bool {
+	return strings.EqualFold(r.URL.Query().Get("case"), "camel")
+}
+
+// encodeJSON writes v as JSON, camel-casing keys and indenting as the
+// request asks
+func (h *BookHandler) encodeJSON(w io.Writer, r *http.Request, v interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+
+	body := buf.Bytes()
+	if wantsCamelCase(r) {
+		renamed, err := renameJSONKeys(body, snakeToCamel)
+		if err != nil {
+			return err
+		}
+		body = append(renamed, '\n')
+	}
+
+	if h.prettyJSON(r) {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err != nil {
+			return err
+		}
+		body = indented.Bytes()
+	}
+
+	_, err := w.Write(body)
+	return err
+}
+
+// errEmptyBody is returned when a request that needs a JSON body has none
+var errEmptyBody = errors.New("request body is required")
+
+// decodeRequest decodes the JSON request body into v. When the body is
+// missing or malformed it writes a 400, with a distinct message for each
+// case, and returns false. With strict set, unknown fields are rejected and
+// the decoder's error is included in the message.
+func (h *BookHandler) decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}, strict bool) bool {
+	err := decodeJSONBody(r, v, strict)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errEmptyBody):
+		h.respondError(w, r, http.StatusBadRequest, "Request body is required")
+	case strict:
+		h.respondError(w, r, http.StatusBadRequest, "Invalid JSON payload: "+err.Error())
+	default:
+		h.respondError(w, r, http.StatusBadRequest, "Invalid JSON payload")
+	}
+	return false
+}
+
+// decodeJSONBody decodes the request body into v, accepting camelCase keys
+// for snake_case fields. With strict set, unknown fields are rejected. An
+// empty or whitespace-only body returns errEmptyBody.
+func decodeJSONBody(r *http.Request, v interface{}, strict bool) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return errEmptyBody
+	}
+
+	if renamed, err := renameJSONKeys(body, camelToSnake); err == nil {
+		body = renamed
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+// jsonFrame tracks the position within an object or array being rewritten
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	count     int
+}
+
+// renameJSONKeys returns the JSON document with every object key passed
+// through rename. Key order and values are preserved.
+func renameJSONKeys(data []byte, rename func(string) string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	var stack []*jsonFrame
+	top := func() *jsonFrame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	valueDone := func() {
+		if frame := top(); frame != nil {
+			frame.count++
+			frame.expectKey = frame.object
+		}
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		closing := isDelim && (delim == '}' || delim == ']')
+		if frame := top(); frame != nil && !closing {
+			switch {
+			case frame.object && !frame.expectKey:
+				out.WriteByte(':')
+			case frame.count > 0:
+				out.WriteByte(',')
+			}
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			out.WriteRune(rune(t))
+			if closing {
+				stack = stack[:len(stack)-1]
+				valueDone()
+			} else {
+				stack = append(stack, &jsonFrame{object: t == '{', expectKey: t == '{'})
+			}
+			continue
+		case string:
+			if frame := top(); frame != nil && frame.object && frame.expectKey {
+				t = rename(t)
+				frame.expectKey = false
+				encoded, _ := json.Marshal(t)
+				out.Write(encoded)
+				continue
+			}
+		}
+
+		encoded, err := json.Marshal(tok)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(encoded)
+		valueDone()
+	}
+
+	return out.Bytes(), nil
+}
+
+// snakeToCamel converts publish_year to publishYear
+func snakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// camelToSnake converts publishYear to publish_year; keys that are already
+// snake_case are returned unchanged
+func camelToSnake(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			// Start a new word at a lower-to-upper change, or at the last
+			// capital of an acronym followed by a lowercase letter
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) && runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
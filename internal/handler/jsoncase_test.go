@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+	"library-management/internal/domain"
+)
+
+func TestKeyCaseConversion(t *testing.T) {
+	tests := []struct {
+		snake, camel string
+	}{
+		{"title", "title"},
+		{"publish_year", "publishYear"},
+		{"public_id", "publicId"},
+		{"words_per_minute", "wordsPerMinute"},
+	}
+	for _, tt := range tests {
+		if got := snakeToCamel(tt.snake); got != tt.camel {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", tt.snake, got, tt.camel)
+		}
+		if got := camelToSnake(tt.camel); got != tt.snake {
+			t.Errorf("camelToSnake(%q) = %q, want %q", tt.camel, got, tt.snake)
+		}
+		if got := camelToSnake(tt.snake); got != tt.snake {
+			t.Errorf("camelToSnake(%q) = %q, want it unchanged", tt.snake, got)
+		}
+	}
+
+	if got := camelToSnake("publicID"); got != "public_id" {
+		t.Errorf("got %q, want acronym to stay one word", got)
+	}
+}
+
+func TestRenameJSONKeys(t *testing.T) {
+	in := `{"publish_year":2008,"books":[{"created_at":"2024","tags":["a_b"]},{}],"meta":{"is_ok":true,"note":null},"price":1.50}`
+	want := `{"publishYear":2008,"books":[{"createdAt":"2024","tags":["a_b"]},{}],"meta":{"isOk":true,"note":null},"price":1.50}`
+
+	got, err := renameJSONKeys([]byte(in), snakeToCamel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Unexpected rewrite:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestBookHandler_JSONCase(t *testing.T) {
+	t.Run("snake_case output by default", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil))
+
+		if body := rec.Body.String(); !strings.Contains(body, `"publish_year":2008`) || strings.Contains(body, "publishYear") {
+			t.Errorf("got %s, want snake_case keys", body)
+		}
+	})
+
+	t.Run("camelCase output on request", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/1?case=camel", nil))
+
+		body := rec.Body.String()
+		for _, key := range []string{`"publishYear":2008`, `"createdAt"`, `"publicId"`} {
+			if !strings.Contains(body, key) {
+				t.Errorf("want %s in %s", key, body)
+			}
+		}
+		if strings.Contains(body, "publish_year") {
+			t.Errorf("got %s, want no snake_case keys", body)
+		}
+	})
+
+	for name, payload := range map[string]string{
+		"snake_case input": `{"publish_year": 2010, "title": "Clean Code 2"}`,
+		"camelCase input":  `{"publishYear": 2010, "title": "Clean Code 2"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/books/1", strings.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+			var body struct {
+				Data domain.Book `json:"data"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body.Data.PublishYear != 2010 || body.Data.Title != "Clean Code 2" {
+				t.Errorf("got year %d title %q, want update applied", body.Data.PublishYear, body.Data.Title)
+			}
+		})
+	}
+
+	t.Run("camelCase create payload validates", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(), &config.Config{})
+		payload := `{"title": "T", "author": "A", "isbn": "978-0000000001", "publisher": "P", "publishYear": 2020, "genre": "G", "pages": 10}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/books/validate", strings.NewReader(payload))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if strings.Contains(rec.Body.String(), "publish_year") {
+			t.Errorf("got %s, want publishYear to be accepted", rec.Body.String())
+		}
+	})
+}
+
+func TestBookHandler_EmptyBody(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	endpoints := []struct {
+		method, path string
+	}{
+		{http.MethodPost, "/api/v1/books"},
+		{http.MethodPut, "/api/v1/books/1"},
+		{http.MethodPost, "/api/v1/books/validate"},
+		{http.MethodPost, "/api/v1/books/isbn/exists"},
+	}
+	bodies := []struct {
+		name, body, message string
+	}{
+		{"empty", "", "Request body is required"},
+		{"whitespace", " \n", "Request body is required"},
+		{"malformed", `{"title":`, "Invalid JSON payload"},
+	}
+
+	for _, endpoint := range endpoints {
+		for _, body := range bodies {
+			t.Run(endpoint.method+" "+endpoint.path+" "+body.name, func(t *testing.T) {
+				req := httptest.NewRequest(endpoint.method, endpoint.path, strings.NewReader(body.body))
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				if rec.Code != http.StatusBadRequest {
+					t.Fatalf("status = %d, want 400", rec.Code)
+				}
+				var resp Response
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Error != body.message {
+					t.Errorf("error = %q, want %q", resp.Error, body.message)
+				}
+			})
+		}
+	}
+}
@@ -1,26 +1,160 @@
 package handler
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"mime"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
-)
 
-// corsMiddleware handles CORS headers
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	"library-management/internal/database"
+	"library-management/pkg/logger"
+	"library-management/pkg/realip"
+)
+
+// corsMiddleware handles CORS headers. With "*" among allowedOrigins any
+// origin is allowed; otherwise only a listed Origin gets CORS headers, so
+// browsers block the rest. Every response then varies on Origin so a shared
+// cache never replays one origin's answer to another.
+func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowAny := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
 		}
+		allowed[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if !allowAny {
+				w.Header().Add("Vary", "Origin")
+			}
+			switch {
+			case allowAny:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case origin != "" && allowed[strings.ToLower(origin)]:
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if w.Header().Get("Access-Control-Allow-Origin") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-API-Key")
+				w.Header().Set("Access-Control-Expose-Headers", "ETag, Link, X-Content-Hash")
+			}
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// canonicalHostSkipPaths are served on any host so probes can reach each instance directly
+var canonicalHostSkipPaths = map[string]bool{
+	"/health":  true,
+	"/ready":   true,
+	"/metrics": true,
+}
+
+// canonicalHostMiddleware redirects requests for any other host to host,
+// keeping the path and query. GET and HEAD get a 301; other methods get a
+// 308 so clients resend the same method and body. The scheme is kept too,
+// taking X-Forwarded-Proto only from proxies ips trusts.
+func canonicalHostMiddleware(host string, ips *realip.Resolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Host, host) || canonicalHostSkipPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := url.URL{Scheme: ips.Scheme(r), Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, target.String(), status)
+		})
+	}
+}
+
+// spanErrorKey holds the message of the error response a handler sent, set
+// by recordSpanError for tracingMiddleware to use as the span status
+type spanErrorKey struct{}
+
+// tracingMiddleware starts a server span per request, continuing any trace
+// propagated in the request headers. Spans are named after the route template
+// so IDs do not create one span name per book. Server errors set the span
+// status, described by the handler's error message when it sent one.
+func tracingMiddleware(tp trace.TracerProvider) func(http.Handler) http.Handler {
+	tracer := tp.Tracer("library-management/handler")
+	propagator := otel.GetTextMapPropagator()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			defer span.End()
+
+			var message string
+			ctx = context.WithValue(ctx, spanErrorKey{}, &message)
+
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", wrapped.statusCode))
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				if message == "" {
+					message = http.StatusText(wrapped.statusCode)
+				}
+				span.SetStatus(codes.Error, message)
+			}
+		})
+	}
+}
+
+// recordSpanError adds the error response a handler is sending to the
+// request's span as an "error" event, and keeps its message for the span
+// status
+func recordSpanError(r *http.Request, statusCode int, message string) {
+	trace.SpanFromContext(r.Context()).AddEvent("error", trace.WithAttributes(
+		attribute.Int("http.response.status_code", statusCode),
+		attribute.String("error.message", message),
+	))
+	if holder, ok := r.Context().Value(spanErrorKey{}).(*string); ok {
+		*holder = message
+	}
 }
 
 // jsonMiddleware sets JSON content type for API routes only
@@ -34,21 +168,126 @@ func jsonMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// loggingMiddleware logs all HTTP requests
-func loggingMiddleware(next http.Handler) http.Handler {
+// requireJSONContentType rejects requests that carry a body with a
+// Content-Type other than application/json with 415. Parameters such as
+// charset are ignored, bodiless requests pass, and multipart/form-data is
+// left for upload routes to handle.
+func (h *BookHandler) requireJSONContentType(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		
-		// Create a wrapped response writer to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-		
-		next.ServeHTTP(wrapped, r)
-		
-		duration := time.Since(start)
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
+		if r.ContentLength == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil && (mediaType == "application/json" || mediaType == "multipart/form-data") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.respondError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
 	})
 }
 
+// sampleFloat returns a value in [0, 1) for request log sampling; replaced in tests
+var sampleFloat = rand.Float64
+
+// loggingMiddleware logs HTTP requests. Responses with status 400 and above
+// are always logged; others are logged with probability sampleRate. Query
+// parameters named in redactFields are masked before logging, and the client
+// IP is resolved through ips.
+func loggingMiddleware(log logger.Logger, ips *realip.Resolver, redactFields []string, sampleRate float64) func(http.Handler) http.Handler {
+	redact := make(map[string]bool, len(redactFields))
+	for _, field := range redactFields {
+		redact[strings.ToLower(strings.TrimSpace(field))] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Create a wrapped response writer to capture status code
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.statusCode < http.StatusBadRequest && sampleFloat() >= sampleRate {
+				return
+			}
+
+			log.Info("HTTP request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"query", redactQuery(r.URL.Query(), redact),
+				"status", wrapped.statusCode,
+				"duration", time.Since(start).String(),
+				"remote_addr", r.RemoteAddr,
+				"client_ip", ips.ClientIP(r),
+			)
+		})
+	}
+}
+
+// latencyBudgetMiddleware logs a warning for requests that take longer than
+// their route's budget. Budgets are looked up by "METHOD /route", then
+// "METHOD", then fall back to defaultBudget; a zero budget is not checked.
+func latencyBudgetMiddleware(log logger.Logger, budgets map[string]time.Duration, defaultBudget time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			duration := time.Since(start)
+
+			route := routeTemplate(r)
+			budget, ok := budgets[r.Method+" "+route]
+			if !ok {
+				if budget, ok = budgets[r.Method]; !ok {
+					budget = defaultBudget
+				}
+			}
+			if budget <= 0 || duration <= budget {
+				return
+			}
+
+			log.Warn("Request exceeded latency budget",
+				"method", r.Method,
+				"route", route,
+				"path", r.URL.Path,
+				"status", wrapped.statusCode,
+				"duration", duration.String(),
+				"budget", budget.String(),
+			)
+		})
+	}
+}
+
+// routeVariablePattern matches the regular expression in a mux route
+// variable, as in {id:[0-9]+}
+var routeVariablePattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// routeTemplate identifies the mux route serving r by its path template with
+// variable patterns removed, e.g. /api/v1/books/{id}. Without a matched
+// route the request path is used.
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return routeVariablePattern.ReplaceAllString(template, "{$1}")
+		}
+	}
+	return r.URL.Path
+}
+
+// redactQuery encodes query parameters, masking the values of redacted names
+func redactQuery(query url.Values, redact map[string]bool) string {
+	for key := range query {
+		if redact[strings.ToLower(key)] {
+			query[key] = []string{logger.RedactedValue}
+		}
+	}
+	return query.Encode()
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -58,4 +297,85 @@ type responseWriter struct {
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
-}
\ No newline at end of file
+}
+
+// TxBeginner starts database transactions
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// transactionMiddleware runs the wrapped handler inside a database transaction
+// stored in the request context. The transaction is committed when the handler
+// responds with a 2xx status and rolled back on any other status or a panic.
+// The response is buffered so a failed commit can still be reported as a 500
+// through respond.
+func transactionMiddleware(db TxBeginner, log logger.Logger, respond func(http.ResponseWriter, *http.Request, int, string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tx, err := db.BeginTx(r.Context(), nil)
+			if err != nil {
+				log.Error("Failed to begin transaction", "error", err)
+				respond(w, r, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			defer func() {
+				if p := recover(); p != nil {
+					tx.Rollback()
+					panic(p)
+				}
+			}()
+
+			buffered := newBufferedResponseWriter()
+			next.ServeHTTP(buffered, r.WithContext(database.ContextWithTx(r.Context(), tx)))
+
+			if buffered.statusCode < 200 || buffered.statusCode >= 300 {
+				if err := tx.Rollback(); err != nil {
+					log.Error("Failed to roll back transaction", "error", err)
+				}
+				buffered.flushTo(w)
+				return
+			}
+
+			if err := tx.Commit(); err != nil {
+				log.Error("Failed to commit transaction", "error", err)
+				respond(w, r, http.StatusInternalServerError, "Failed to commit transaction")
+				return
+			}
+
+			buffered.flushTo(w)
+		})
+	}
+}
+
+// bufferedResponseWriter holds a response in memory until it is flushed
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(code int) {
+	b.statusCode = code
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// flushTo copies the buffered headers, status and body to w
+func (b *bufferedResponseWriter) flushTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.statusCode)
+	w.Write(b.body.Bytes())
+}
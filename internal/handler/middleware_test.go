@@ -0,0 +1,575 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"library-management/internal/config"
+	"library-management/internal/database"
+	"library-management/internal/domain"
+	"library-management/internal/repository"
+	"library-management/internal/service"
+	"library-management/pkg/logger"
+	"library-management/pkg/realip"
+)
+
+// fakeDriver is a minimal database/sql driver that records transaction calls
+type fakeDriver struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (d *fakeDriver) record(call string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+func (d *fakeDriver) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.calls...)
+}
+
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+func (d *fakeDriver) Driver() driver.Driver                            { return d }
+func (d *fakeDriver) Open(name string) (driver.Conn, error)            { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.d.record("begin")
+	return &fakeTx{d: c.d}, nil
+}
+
+type fakeTx struct{ d *fakeDriver }
+
+func (t *fakeTx) Commit() error   { t.d.record("commit"); return nil }
+func (t *fakeTx) Rollback() error { t.d.record("rollback"); return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record("exec " + s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported by the fake driver")
+}
+
+// newFakeDB returns a *sql.DB backed by a fresh fakeDriver
+func newFakeDB() (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{}
+	return sql.OpenDB(d), d
+}
+
+// failingTxBeginner fails every BeginTx
+type failingTxBeginner struct{}
+
+func (failingTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTransactionMiddleware(t *testing.T) {
+	// writeThen performs a partial write in the request transaction and then responds with status
+	writeThen := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tx, ok := database.TxFromContext(r.Context())
+			if !ok {
+				t.Fatal("want a transaction in the request context")
+			}
+			if _, err := tx.ExecContext(r.Context(), "INSERT INTO books"); err != nil {
+				t.Fatalf("Unexpected exec error: %v", err)
+			}
+			w.WriteHeader(status)
+		})
+	}
+
+	t.Run("commits on success", func(t *testing.T) {
+		db, d := newFakeDB()
+		rec := httptest.NewRecorder()
+		transactionMiddleware(db, testHandler.logger, testHandler.respondError)(writeThen(http.StatusCreated)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("status = %d, want 201", rec.Code)
+		}
+		if want := []string{"begin", "exec INSERT INTO books", "commit"}; !reflect.DeepEqual(d.Calls(), want) {
+			t.Errorf("calls = %v, want %v", d.Calls(), want)
+		}
+	})
+
+	t.Run("rolls back on error after partial write", func(t *testing.T) {
+		db, d := newFakeDB()
+		rec := httptest.NewRecorder()
+		transactionMiddleware(db, testHandler.logger, testHandler.respondError)(writeThen(http.StatusBadRequest)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+		if want := []string{"begin", "exec INSERT INTO books", "rollback"}; !reflect.DeepEqual(d.Calls(), want) {
+			t.Errorf("calls = %v, want %v", d.Calls(), want)
+		}
+	})
+
+	t.Run("responds through respond when begin fails", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		transactionMiddleware(failingTxBeginner{}, testHandler.logger, testHandler.respondError)(writeThen(http.StatusCreated)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", rec.Code)
+		}
+		if !strings.Contains(rec.Header().Get("Content-Type"), "application/json") || !strings.Contains(rec.Body.String(), `"Internal server error"`) {
+			t.Errorf("got %q with body %s, want a JSON error response", rec.Header().Get("Content-Type"), rec.Body.String())
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		db, d := newFakeDB()
+		panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tx, _ := database.TxFromContext(r.Context())
+			tx.ExecContext(r.Context(), "INSERT INTO books")
+			panic("boom")
+		})
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("want panic to propagate")
+				}
+			}()
+			transactionMiddleware(db, testHandler.logger, testHandler.respondError)(panicking).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+		}()
+
+		if want := []string{"begin", "exec INSERT INTO books", "rollback"}; !reflect.DeepEqual(d.Calls(), want) {
+			t.Errorf("calls = %v, want %v", d.Calls(), want)
+		}
+	})
+}
+
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	respond := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+	}
+
+	// logged serves a request with the given status and reports whether it was logged
+	logged := func(sampleRate float64, status int) bool {
+		var buf bytes.Buffer
+		log := logger.NewWithOptions(logger.Options{Output: &buf})
+		handler := loggingMiddleware(log, nil, nil, sampleRate)(respond(status))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/books", nil))
+		return buf.Len() > 0
+	}
+
+	defer func(orig func() float64) { sampleFloat = orig }(sampleFloat)
+	sampleFloat = func() float64 { return 0.5 }
+
+	tests := []struct {
+		name       string
+		sampleRate float64
+		status     int
+		expected   bool
+	}{
+		{"success sampled in", 0.6, http.StatusOK, true},
+		{"success sampled out", 0.4, http.StatusOK, false},
+		{"success never logged at zero rate", 0, http.StatusOK, false},
+		{"client error always logged", 0, http.StatusNotFound, true},
+		{"server error always logged", 0, http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logged(tt.sampleRate, tt.status); got != tt.expected {
+				t.Errorf("got %v, want logged=%v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetupRoutes_LogSampleRate(t *testing.T) {
+	zero, half := 0.0, 0.5
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want bool
+	}{
+		{"unset logs every request", &config.Config{}, true},
+		{"explicit zero logs no successes", &config.Config{LogSampleRate: &zero}, false},
+		{"rate kept when set", &config.Config{LogSampleRate: &half}, false},
+	}
+
+	defer func(orig func() float64) { sampleFloat = orig }(sampleFloat)
+	sampleFloat = func() float64 { return 0.5 }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.NewWithOptions(logger.Options{Output: &buf})
+			db, _ := newFakeDB()
+			router := mux.NewRouter()
+			SetupRoutes(router, NewHandlers(newStubBookService(sampleBook()), &stubDatabase{DB: db}, log, tt.cfg))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := strings.Contains(buf.String(), "HTTP request"); got != tt.want {
+				t.Errorf("logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    string
+		vary    string
+	}{
+		{"any origin", []string{"*"}, "https://elsewhere.example.com", "*", ""},
+		{"listed origin echoed", []string{"https://library.example.com/"}, "https://Library.example.com", "https://Library.example.com", "Origin"},
+		{"unlisted origin", []string{"https://library.example.com"}, "https://elsewhere.example.com", "", "Origin"},
+		{"no origin header", []string{"https://library.example.com"}, "", "", "Origin"},
+		{"none allowed", nil, "https://library.example.com", "", "Origin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			corsMiddleware(tt.allowed)(next).ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+			if methods := rec.Header().Get("Access-Control-Allow-Methods"); (methods != "") != (tt.want != "") {
+				t.Errorf("got methods %q, want CORS headers only for allowed origins", methods)
+			}
+			if got := rec.Header().Get("Vary"); got != tt.vary {
+				t.Errorf("Vary = %q, want %q", got, tt.vary)
+			}
+		})
+	}
+}
+
+func TestLatencyBudgetMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithOptions(logger.Options{Output: &buf})
+
+	router := mux.NewRouter()
+	router.Use(latencyBudgetMiddleware(log, map[string]time.Duration{
+		"GET /books/{id}": 10 * time.Millisecond,
+		"GET":             time.Minute,
+	}, 0))
+	router.HandleFunc("/books/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}).Methods("GET")
+	router.HandleFunc("/books", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}).Methods("GET", "POST")
+
+	serve := func(method, target string) string {
+		buf.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+		return buf.String()
+	}
+
+	t.Run("slow route over its budget warns", func(t *testing.T) {
+		out := serve(http.MethodGet, "/books/42")
+		if !strings.Contains(out, "Request exceeded latency budget") {
+			t.Fatalf("got %q, want a latency warning", out)
+		}
+		if !strings.Contains(out, `"route":"/books/{id}"`) || !strings.Contains(out, `"budget":"10ms"`) {
+			t.Errorf("got %s, want route and budget in the warning", out)
+		}
+	})
+
+	t.Run("method budget applies to other routes", func(t *testing.T) {
+		if out := serve(http.MethodGet, "/books"); out != "" {
+			t.Errorf("got %s, want no warning within the GET budget", out)
+		}
+	})
+
+	t.Run("no budget means no check", func(t *testing.T) {
+		if out := serve(http.MethodPost, "/books"); out != "" {
+			t.Errorf("got %s, want no warning without a budget", out)
+		}
+	})
+}
+
+func TestLoggingMiddlewareClientIP(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithOptions(logger.Options{Output: &buf})
+	ips := realip.New([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	handler := loggingMiddleware(log, ips, nil, 1)(http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+	req.RemoteAddr = "10.0.0.2:41000"
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 198.51.100.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.Contains(buf.String(), `"client_ip":"198.51.100.9"`) {
+		t.Errorf("got %s, want client_ip from the proxy chain", buf.String())
+	}
+}
+
+func TestCanonicalHostMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	trusted, err := realip.ParsePrefixes([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("ParsePrefixes() error = %v", err)
+	}
+	handler := canonicalHostMiddleware("library.example.com", realip.New(trusted))(next)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		remote   string
+		proto    string
+		status   int
+		location string
+	}{
+		{"canonical host passes", http.MethodGet, "http://library.example.com/books", "", "", http.StatusNoContent, ""},
+		{"host match ignores case", http.MethodGet, "http://Library.Example.com/", "", "", http.StatusNoContent, ""},
+		{"other host redirected", http.MethodGet, "http://www.library.example.com/api/v1/books?genre=Fiction&limit=5", "", "",
+			http.StatusMovedPermanently, "http://library.example.com/api/v1/books?genre=Fiction&limit=5"},
+		{"forwarded https kept", http.MethodGet, "http://old.example.com/", "10.0.0.2:5000", "https", http.StatusMovedPermanently, "https://library.example.com/"},
+		{"forwarded https from untrusted peer ignored", http.MethodGet, "http://old.example.com/", "203.0.113.7:5000", "https", http.StatusMovedPermanently, "http://library.example.com/"},
+		{"writes keep their method", http.MethodPost, "http://old.example.com/api/v1/books", "", "", http.StatusPermanentRedirect, "http://library.example.com/api/v1/books"},
+		{"health skipped", http.MethodGet, "http://10.0.0.5:8080/health", "", "", http.StatusNoContent, ""},
+		{"ready skipped", http.MethodGet, "http://10.0.0.5:8080/ready", "", "", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.remote != "" {
+				req.RemoteAddr = tt.remote
+			}
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if location := rec.Header().Get("Location"); location != tt.location {
+				t.Errorf("Location = %q, want %q", location, tt.location)
+			}
+		})
+	}
+}
+
+func TestRequireJSONContentType(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{RequireJSONContentType: true})
+	body := `{"title":"Updated Title"}`
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"missing", http.MethodPut, "", body, http.StatusUnsupportedMediaType},
+		{"wrong", http.MethodPut, "text/plain", body, http.StatusUnsupportedMediaType},
+		{"form encoded", http.MethodPut, "application/x-www-form-urlencoded", body, http.StatusUnsupportedMediaType},
+		{"json", http.MethodPut, "application/json", body, http.StatusOK},
+		{"json with charset", http.MethodPut, "application/json; charset=utf-8", body, http.StatusOK},
+		{"json ignores case", http.MethodPut, "Application/JSON", body, http.StatusOK},
+		{"bodiless request", http.MethodDelete, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/books/1", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/books/1", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	})
+}
+
+// bookByIDRepository serves a single book from GetByID; other methods are unused
+type bookByIDRepository struct {
+	repository.BookRepository
+	book *domain.Book
+	err  error
+}
+
+func (r *bookByIDRepository) GetByID(ctx context.Context, id int) (*domain.Book, error) {
+	return r.book, r.err
+}
+
+func TestTracingMiddlewareSpanTree(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func(orig trace.TracerProvider) { otel.SetTracerProvider(orig) }(otel.GetTracerProvider())
+	otel.SetTracerProvider(tp)
+
+	repo := repository.NewTracingRepository(&bookByIDRepository{book: sampleBook()}, tp)
+	svc := service.NewTracingService(service.NewBookService(repo), tp)
+	router := newTestRouter(svc, &config.Config{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	httpSpan, ok := spans["HTTP GET /api/v1/books/{id:[0-9A-Za-z-]+}"]
+	if !ok {
+		t.Fatalf("got %v, want an HTTP span named after the route", exporter.GetSpans())
+	}
+	serviceSpan, ok := spans["BookService.GetBookByID"]
+	if !ok {
+		t.Fatal("want a service span")
+	}
+	repoSpan, ok := spans["BookRepository.GetByID"]
+	if !ok {
+		t.Fatal("want a repository span")
+	}
+
+	if serviceSpan.Parent.SpanID() != httpSpan.SpanContext.SpanID() {
+		t.Error("want the service span to be a child of the HTTP span")
+	}
+	if repoSpan.Parent.SpanID() != serviceSpan.SpanContext.SpanID() {
+		t.Error("want the repository span to be a child of the service span")
+	}
+	if repoSpan.SpanContext.TraceID() != httpSpan.SpanContext.TraceID() {
+		t.Error("want all spans to share one trace")
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range repoSpan.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["db.operation.name"].AsString() != "GetByID" || attrs["db.rows"].AsInt64() != 1 {
+		t.Errorf("Unexpected repository span attributes %v", repoSpan.Attributes)
+	}
+	if len(attrs) != 3 {
+		t.Errorf("got %v, want only db.system, db.operation.name and db.rows", repoSpan.Attributes)
+	}
+}
+
+func TestTracingMiddlewareErrorResponses(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func(orig trace.TracerProvider) { otel.SetTracerProvider(orig) }(otel.GetTracerProvider())
+	otel.SetTracerProvider(tp)
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    codes.Code
+		message string
+	}{
+		{"server error", errors.New("connection refused"), http.StatusInternalServerError, codes.Error, "Failed to retrieve book"},
+		{"client error", fmt.Errorf("book with ID 1 %w", domain.ErrBookNotFound), http.StatusNotFound, codes.Unset, "Book not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			svc := service.NewBookService(&bookByIDRepository{err: tt.err})
+			router := newTestRouter(svc, &config.Config{})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Status.Code != tt.code {
+				t.Errorf("span status = %v, want %v", span.Status.Code, tt.code)
+			}
+			if tt.code == codes.Error && span.Status.Description != tt.message {
+				t.Errorf("span status description = %q, want %q", span.Status.Description, tt.message)
+			}
+
+			if len(span.Events) != 1 || span.Events[0].Name != "error" {
+				t.Fatalf("span events = %v, want one error event", span.Events)
+			}
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Events[0].Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			if got := attrs["error.message"].AsString(); got != tt.message {
+				t.Errorf("error.message = %q, want %q", got, tt.message)
+			}
+			if got := attrs["http.response.status_code"].AsInt64(); got != int64(tt.status) {
+				t.Errorf("event status code = %d, want %d", got, tt.status)
+			}
+		})
+	}
+}
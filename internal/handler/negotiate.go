@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// prefersXML reports whether XML is the client's most preferred media type
+// in the Accept header. JSON remains the default, including on ties, so
+// browsers that list XML below text/html keep getting JSON.
+func prefersXML(r *http.Request) bool {
+	var xmlQ, otherQ float64
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case "application/xml", "text/xml":
+			xmlQ = max(xmlQ, q)
+		default:
+			otherQ = max(otherQ, q)
+		}
+	}
+
+	return xmlQ > 0 && xmlQ > otherQ
+}
+
+// envelopeMediaTypes are the media types of the response envelope and of
+// error bodies, which every API route can send
+var envelopeMediaTypes = []string{"application/json", "application/xml", "text/xml", problemJSON, "application/problem+xml"}
+
+// v2MediaTypes are what API v2 sends; its bare bodies are JSON only
+var v2MediaTypes = []string{"application/json", problemJSON}
+
+// routeMediaTypes lists what routes that answer with something other than the
+// envelope send on success, keyed by the end of their path template
+var routeMediaTypes = map[string][]string{
+	"/archive":  {"application/zip"},
+	"/citation": {"text/plain", "application/x-bibtex"},
+}
+
+// requireAcceptable answers 406 through respond when the Accept header allows
+// none of the media types the route can send, by default offers. A missing
+// Accept header accepts anything, so it and */* keep getting JSON.
+func requireAcceptable(offers []string, respond func(http.ResponseWriter, *http.Request, int, string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			offered := offers
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ := route.GetPathTemplate()
+				for suffix, types := range routeMediaTypes {
+					if strings.HasSuffix(template, suffix) {
+						offered = types
+					}
+				}
+			}
+
+			if acceptable(r.Header.Get("Accept"), offered) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			respond(w, r, http.StatusNotAcceptable, "Not acceptable: supported media types are "+strings.Join(offered, ", "))
+		})
+	}
+}
+
+// acceptable reports whether the Accept header allows any of offers. Each
+// offer takes the q value of the most specific range that matches it, so
+// "application/json;q=0, */*" rules JSON out but allows the rest.
+func acceptable(accept string, offers []string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer, "/")
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			rangeType, rangeSubtype, _ := strings.Cut(mediaType, "/")
+			var s int
+			switch {
+			case mediaType == offer:
+				s = 2
+			case rangeSubtype == "*" && rangeType == offerType:
+				s = 1
+			case mediaType == "*/*":
+				s = 0
+			default:
+				continue
+			}
+			if s <= specificity {
+				continue
+			}
+
+			specificity, q = s, 1.0
+			if qs, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(qs, 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlEnvelope renders a Response as XML, encoding Data with xmlValue since
+// encoding/xml cannot marshal the maps used for list responses. Warnings go
+// through a pointer so responses without any omit the <warnings> element,
+// which a warnings>warning tag would still write.
+type xmlEnvelope struct {
+	Response
+	Data     *xmlValue    `xml:"data,omitempty"`
+	Warnings *xmlWarnings `xml:"warnings,omitempty"`
+}
+
+// xmlWarnings lists a response's warnings as repeated <warning> elements
+type xmlWarnings struct {
+	Warning []string `xml:"warning"`
+}
+
+// encodeXMLResponse writes the response envelope as an XML document
+func encodeXMLResponse(w io.Writer, response Response) error {
+	envelope := xmlEnvelope{Response: response}
+	if response.Data != nil {
+		envelope.Data = &xmlValue{v: reflect.ValueOf(response.Data)}
+	}
+	if len(response.Warnings) > 0 {
+		envelope.Warnings = &xmlWarnings{Warning: response.Warnings}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := enc.Encode(envelope); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xmlValue marshals arbitrary response data: maps become elements named by
+// their keys, slices repeat an element named after the item type, and
+// structs use their xml tags
+type xmlValue struct {
+	v reflect.Value
+}
+
+var (
+	xmlMarshalerType  = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// MarshalXML implements xml.Marshaler
+func (x *xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeXMLValue(e, start, x.v)
+}
+
+func encodeXMLValue(e *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	t := v.Type()
+	if t.Implements(xmlMarshalerType) || t.Implements(textMarshalerType) || t.Kind() == reflect.Struct {
+		return e.EncodeElement(v.Interface(), start)
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			child := xml.StartElement{Name: xml.Name{Local: fmt.Sprint(key.Interface())}}
+			if err := encodeXMLValue(e, child, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return e.EncodeElement(v.Interface(), start)
+		}
+
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		child := xml.StartElement{Name: xml.Name{Local: xmlItemName(t.Elem())}}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeXMLValue(e, child, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+
+	return e.EncodeElement(v.Interface(), start)
+}
+
+// xmlItemName names slice elements after their struct type, e.g. GenreStats
+// becomes genre_stats, falling back to item
+func xmlItemName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return "item"
+	}
+
+	var b strings.Builder
+	for i, r := range t.Name() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
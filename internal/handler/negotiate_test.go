@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+)
+
+func TestAcceptable(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", true},
+		{"*/*", true},
+		{"application/json", true},
+		{"application/*", true},
+		{"text/html, application/xml;q=0.9", true},
+		{"application/pdf", false},
+		{"image/*", false},
+		{"application/json;q=0", false},
+		{"application/json;q=0, */*", true},
+		{"*/*;q=0", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptable(tt.accept, []string{"application/json", "application/xml"}); got != tt.want {
+			t.Errorf("acceptable(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestBookHandler_StrictAccept(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{StrictAccept: true})
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"absent defaults to json", "/api/v1/books/1", "", http.StatusOK, "application/json"},
+		{"wildcard defaults to json", "/api/v1/books/1", "*/*", http.StatusOK, "application/json"},
+		{"json", "/api/v1/books/1", "application/json", http.StatusOK, "application/json"},
+		{"xml", "/api/v1/books/1", "application/xml", http.StatusOK, "application/xml"},
+		{"unsupported", "/api/v1/books/1", "application/pdf", http.StatusNotAcceptable, "application/json"},
+		{"citation text", "/api/v1/books/1/citation", "text/plain", http.StatusOK, "text/plain"},
+		{"citation as json", "/api/v1/books/1/citation", "application/json", http.StatusNotAcceptable, "application/json"},
+		{"v2 json", "/api/v2/books", "application/json", http.StatusOK, "application/json"},
+		{"v2 xml", "/api/v2/books", "application/xml", http.StatusNotAcceptable, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %s", got, tt.contentType)
+			}
+		})
+	}
+
+	// Without STRICT_ACCEPT an unsupported Accept still gets JSON
+	lenient := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/1", nil)
+	req.Header.Set("Accept", "application/pdf")
+	rec := httptest.NewRecorder()
+	lenient.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("got %d, want status 200 without STRICT_ACCEPT", rec.Code)
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"library-management/internal/domain"
+)
+
+// problemJSON is the RFC 7807 Problem Details media type
+const problemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 Problem Details error body. Type is always
+// about:blank, so Title is the status text and Detail says what went wrong.
+type Problem struct {
+	XMLName  xml.Name            `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string              `json:"type" xml:"type"`
+	Title    string              `json:"title" xml:"title"`
+	Status   int                 `json:"status" xml:"status"`
+	Detail   string              `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty" xml:"instance,omitempty"`
+	Errors   []domain.FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// acceptsProblemJSON reports whether the Accept header asks for
+// application/problem+json
+func acceptsProblemJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != problemJSON {
+			continue
+		}
+		if qs, ok := params["q"]; ok {
+			if q, err := strconv.ParseFloat(qs, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// wantsProblem reports whether errors for the request are sent as Problem
+// Details: always under PROBLEM_DETAILS, otherwise when the client asks
+func (h *BookHandler) wantsProblem(r *http.Request) bool {
+	return (h.config != nil && h.config.ProblemDetails) || acceptsProblemJSON(r)
+}
+
+// respondProblem writes an RFC 7807 error body, as application/problem+xml
+// when the client prefers XML
+func (h *BookHandler) respondProblem(w http.ResponseWriter, r *http.Request, statusCode int, message string, fieldErrors []domain.FieldError) {
+	h.setCacheControl(w, r, statusCode)
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   message,
+		Instance: r.URL.Path,
+		Errors:   fieldErrors,
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if prefersXML(r) {
+		w.Header().Set("Content-Type", "application/problem+xml; charset=utf-8")
+		w.WriteHeader(statusCode)
+
+		io.WriteString(w, xml.Header)
+		if err := xml.NewEncoder(w).Encode(problem); err != nil {
+			h.logger.Error("Failed to encode XML problem", "error", err)
+		}
+		io.WriteString(w, "\n")
+		return
+	}
+
+	w.Header().Set("Content-Type", problemJSON+"; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := h.encodeJSON(w, r, problem); err != nil {
+		h.logger.Error("Failed to encode problem", "error", err)
+	}
+}
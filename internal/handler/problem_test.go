@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+	"library-management/internal/domain"
+)
+
+func TestBookHandler_ProblemDetails(t *testing.T) {
+	send := func(cfg *config.Config, method, path, body, accept string) *httptest.ResponseRecorder {
+		router := newTestRouter(newStubBookService(sampleBook()), cfg)
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+		t.Helper()
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json; charset=utf-8" {
+			t.Fatalf("got %q, want application/problem+json", ct)
+		}
+		var problem Problem
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("Failed to decode problem: %v", err)
+		}
+		return problem
+	}
+
+	t.Run("field violations on request", func(t *testing.T) {
+		rec := send(&config.Config{}, http.MethodPost, "/api/v1/books", `{"title":"Untitled","pages":0}`, "application/problem+json")
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+		}
+		problem := decode(t, rec)
+		if problem.Type != "about:blank" || problem.Title != "Bad Request" || problem.Status != http.StatusBadRequest || problem.Instance != "/api/v1/books" {
+			t.Errorf("Unexpected problem %+v", problem)
+		}
+		if !strings.HasPrefix(problem.Detail, "validation error:") {
+			t.Errorf("got %q, want the validation message as detail", problem.Detail)
+		}
+		if len(problem.Errors) < 2 || problem.Errors[0] != (domain.FieldError{Field: "author", Message: "author is required"}) {
+			t.Errorf("got %+v, want every field violation", problem.Errors)
+		}
+	})
+
+	t.Run("enabled by config", func(t *testing.T) {
+		rec := send(&config.Config{ProblemDetails: true}, http.MethodGet, "/api/v1/books/999", "", "")
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", rec.Code)
+		}
+		problem := decode(t, rec)
+		if problem.Title != "Not Found" || problem.Status != http.StatusNotFound || problem.Detail != "Book not found" || problem.Errors != nil {
+			t.Errorf("Unexpected problem %+v", problem)
+		}
+	})
+
+	t.Run("envelope by default", func(t *testing.T) {
+		rec := send(&config.Config{}, http.MethodGet, "/api/v1/books/999", "", "application/json")
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("got %q, want the JSON envelope", ct)
+		}
+		var resp Response
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Status != "error" || resp.Error != "Book not found" {
+			t.Errorf("Unexpected envelope %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("declined with zero quality", func(t *testing.T) {
+		rec := send(&config.Config{}, http.MethodGet, "/api/v1/books/999", "", "application/problem+json;q=0, application/json")
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("got %q, want the JSON envelope", ct)
+		}
+	})
+}
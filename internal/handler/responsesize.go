@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// responseSizeMetric is the name of the response size histogram
+const responseSizeMetric = "http_response_size_bytes"
+
+// responseSizeKey identifies one histogram series
+type responseSizeKey struct {
+	method string
+	route  string
+}
+
+// responseSizeSeries holds the cumulative bucket counts, sum and count of one
+// series; counts[i] is the number of responses no larger than bounds[i]
+type responseSizeSeries struct {
+	counts []int64
+	sum    int64
+	count  int64
+}
+
+// responseSizes is a histogram of response body sizes by method and route.
+// It is safe for concurrent use.
+type responseSizes struct {
+	bounds []int
+
+	mu     sync.Mutex
+	series map[responseSizeKey]*responseSizeSeries
+}
+
+// newResponseSizes returns a histogram with the given ascending bucket
+// upper bounds, in bytes
+func newResponseSizes(bounds []int) *responseSizes {
+	return &responseSizes{bounds: bounds, series: make(map[responseSizeKey]*responseSizeSeries)}
+}
+
+// observe records a response of size bytes
+func (s *responseSizes) observe(method, route string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := responseSizeKey{method: method, route: route}
+	series, ok := s.series[key]
+	if !ok {
+		series = &responseSizeSeries{counts: make([]int64, len(s.bounds))}
+		s.series[key] = series
+	}
+	for i, bound := range s.bounds {
+		if size <= int64(bound) {
+			series.counts[i]++
+		}
+	}
+	series.sum += size
+	series.count++
+}
+
+// labelEscaper escapes label values for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// write renders the histogram in the Prometheus text exposition format,
+// series ordered by route and method
+func (s *responseSizes) write(w *strings.Builder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]responseSizeKey, 0, len(s.series))
+	for key := range s.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	fmt.Fprintf(w, "# HELP %s Response body bytes written by handlers, before any compression.\n", responseSizeMetric)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", responseSizeMetric)
+	for _, key := range keys {
+		series := s.series[key]
+		labels := fmt.Sprintf(`method="%s",route="%s"`, labelEscaper.Replace(key.method), labelEscaper.Replace(key.route))
+		for i, bound := range s.bounds {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%d\"} %d\n", responseSizeMetric, labels, bound, series.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", responseSizeMetric, labels, series.count)
+		fmt.Fprintf(w, "%s_sum{%s} %d\n", responseSizeMetric, labels, series.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", responseSizeMetric, labels, series.count)
+	}
+}
+
+// countingResponseWriter counts the body bytes written through it
+type countingResponseWriter struct {
+	http.ResponseWriter
+	bytes int64
+}
+
+func (cw *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(b)
+	cw.bytes += int64(n)
+	return n, err
+}
+
+// responseSizeMiddleware records the size of each response body in sizes,
+// by method and route template. It counts the bytes handlers write, so any
+// compression applied further out, by this server or a proxy, is not
+// reflected. As router middleware it runs only for requests that match a
+// route, so mux's own 404 and 405 responses are not recorded, and neither is
+// /metrics itself.
+func responseSizeMiddleware(sizes *responseSizes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/metrics" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			counted := &countingResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(counted, r)
+
+			sizes.observe(r.Method, routeTemplate(r), counted.bytes)
+		})
+	}
+}
+
+// Metrics handles GET /metrics, serving the response size histogram and
+// database queue depth, whichever are enabled, in the Prometheus text
+// exposition format
+func (h *BookHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	var body strings.Builder
+	if h.sizes != nil {
+		h.sizes.write(&body)
+	}
+	if h.dbQueue != nil {
+		h.dbQueue.write(&body)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body.String()))
+}
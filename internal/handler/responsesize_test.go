@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+)
+
+func TestBookHandler_ResponseSizeMetrics(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{
+		ResponseSizeMetrics: true,
+		ResponseSizeBuckets: []int{100, 1000},
+	})
+
+	send := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	found := send("/api/v1/books/1")
+	if found.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", found.Code, found.Body.String())
+	}
+	missing := send("/api/v1/books/99")
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", missing.Code, missing.Body.String())
+	}
+	if found.Body.Len() <= 100 || found.Body.Len() > 1000 || missing.Body.Len() > 100 {
+		t.Fatalf("Unexpected body sizes %d and %d for the chosen buckets", found.Body.Len(), missing.Body.Len())
+	}
+
+	metrics := send("/metrics")
+	if metrics.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", metrics.Code)
+	}
+	if ct := metrics.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("got %q, want a text/plain content type", ct)
+	}
+	body := send("/metrics").Body.String()
+
+	labels := `method="GET",route="/api/v1/books/{id}"`
+	for _, want := range []string{
+		"# TYPE http_response_size_bytes histogram",
+		fmt.Sprintf(`http_response_size_bytes_bucket{%s,le="100"} 1`, labels),
+		fmt.Sprintf(`http_response_size_bytes_bucket{%s,le="1000"} 2`, labels),
+		fmt.Sprintf(`http_response_size_bytes_bucket{%s,le="+Inf"} 2`, labels),
+		fmt.Sprintf(`http_response_size_bytes_sum{%s} %d`, labels, found.Body.Len()+missing.Body.Len()),
+		fmt.Sprintf(`http_response_size_bytes_count{%s} 2`, labels),
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("want metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `route="/metrics"`) {
+		t.Errorf("want /metrics not to record itself, got:\n%s", body)
+	}
+
+	// mux answers unrouted requests itself, bypassing router middleware
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/nowhere", nil))
+	if rec.Code != http.StatusMethodNotAllowed && rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 or 405", rec.Code)
+	}
+	if body := send("/metrics").Body.String(); strings.Contains(body, `method="POST"`) {
+		t.Errorf("metrics record an unrouted request:\n%s", body)
+	}
+}
+
+func TestBookHandler_ResponseSizeMetricsDisabled(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "http_response_size_bytes") {
+		t.Errorf("got %q, want no metrics when disabled", rec.Body.String())
+	}
+}
@@ -2,31 +2,134 @@ package handler
 
 import (
 	"net/http"
+	"net/netip"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"library-management/pkg/realip"
 )
 
 // SetupRoutes configures all application routes
 func SetupRoutes(router *mux.Router, handlers *Handlers) {
-	// Add CORS and logging middleware
-	router.Use(corsMiddleware)
-	router.Use(loggingMiddleware)
+	// Client addresses and schemes are taken from trusted proxies' headers
+	var trustedProxies []netip.Prefix
+	if cfg := handlers.Book.config; cfg != nil {
+		trustedProxies = cfg.TrustedProxies
+	}
+	ips := realip.New(trustedProxies)
+
+	// Redirect other hostnames to the canonical host, if configured
+	if cfg := handlers.Book.config; cfg != nil && cfg.CanonicalHost != "" {
+		router.Use(canonicalHostMiddleware(cfg.CanonicalHost, ips))
+	}
+
+	// Add CORS and logging middleware; without a config any origin is allowed
+	corsOrigins := []string{"*"}
+	if cfg := handlers.Book.config; cfg != nil {
+		corsOrigins = cfg.CORSAllowedOrigins
+	}
+	router.Use(corsMiddleware(corsOrigins))
+	var redactFields []string
+	sampleRate := 1.0
+	if cfg := handlers.Book.config; cfg != nil {
+		redactFields = cfg.LogRedactFields
+		sampleRate = cfg.LogSampleFraction()
+	}
+	router.Use(tracingMiddleware(otel.GetTracerProvider()))
+	router.Use(loggingMiddleware(handlers.Book.logger, ips, redactFields, sampleRate))
+	if cfg := handlers.Book.config; cfg != nil && (len(cfg.LatencyBudgets) > 0 || cfg.LatencyBudgetDefault > 0) {
+		router.Use(latencyBudgetMiddleware(handlers.Book.logger, cfg.LatencyBudgets, cfg.LatencyBudgetDefault))
+	}
+	if handlers.Book.errors != nil {
+		router.Use(errorRateMiddleware(handlers.Book.errors))
+	}
+	if cfg := handlers.Book.config; cfg != nil && (cfg.MaxInFlight > 0 || cfg.MaxInFlightPerIP > 0) {
+		router.Use(concurrencyMiddleware(newConcurrencyLimiter(cfg.MaxInFlight, cfg.MaxInFlightPerIP, cfg.InFlightQueueTimeout, ips)))
+	}
+	if handlers.Book.dbQueue != nil {
+		router.Use(dbQueueMiddleware(handlers.Book.dbQueue, handlers.Book.respondError))
+	}
+	// Registered last so it sees exactly the bytes handlers write
+	if handlers.Book.sizes != nil {
+		router.Use(responseSizeMiddleware(handlers.Book.sizes))
+	}
 
 	// Health check endpoint
 	router.HandleFunc("/health", handlers.Book.HealthCheck).Methods("GET")
+	router.HandleFunc("/ready", handlers.Book.Ready).Methods("GET")
+	if handlers.Book.sizes != nil || handlers.Book.dbQueue != nil {
+		router.HandleFunc("/metrics", handlers.Book.Metrics).Methods("GET")
+	}
 
 	// API routes - ensure these are registered first
 	api := router.PathPrefix("/api/v1").Subrouter()
 	api.Use(jsonMiddleware)
+	api.Use(outputTimezone(handlers.Book.respondError))
+	if cfg := handlers.Book.config; cfg != nil && cfg.RequireJSONContentType {
+		api.Use(handlers.Book.requireJSONContentType)
+	}
+	if cfg := handlers.Book.config; cfg != nil && cfg.StrictAccept {
+		api.Use(requireAcceptable(envelopeMediaTypes, handlers.Book.respondError))
+	}
 
 	// Book API routes
+	// Routes that read before writing run in a request-scoped transaction
+	tx := transactionMiddleware(handlers.Book.db, handlers.Book.logger, handlers.Book.respondError)
+
+	// Writes require an API key when API_KEYS is set; bulk updates and
+	// admin routes need the admin role
+	write := func(h http.HandlerFunc) http.Handler {
+		return handlers.Book.requireAPIKey(tx(h))
+	}
+	admin := func(h http.Handler) http.Handler {
+		return handlers.Book.requireAPIKey(handlers.Book.requireRole(RoleAdmin, h))
+	}
+
 	books := api.PathPrefix("/books").Subrouter()
-	books.HandleFunc("", handlers.Book.CreateBook).Methods("POST")
+	books.Handle("", write(handlers.Book.CreateBook)).Methods("POST")
 	books.HandleFunc("", handlers.Book.GetBooks).Methods("GET")
-	books.HandleFunc("/{id:[0-9]+}", handlers.Book.GetBook).Methods("GET")
-	books.HandleFunc("/{id:[0-9]+}", handlers.Book.UpdateBook).Methods("PUT")
-	books.HandleFunc("/{id:[0-9]+}", handlers.Book.DeleteBook).Methods("DELETE")
+	books.HandleFunc("/validate", handlers.Book.ValidateBook).Methods("POST")
+	books.HandleFunc("/attention", handlers.Book.GetBooksNeedingAttention).Methods("GET")
+	books.HandleFunc("/random", handlers.Book.GetRandomBook).Methods("GET")
+	books.HandleFunc("/schema", handlers.Book.GetBookSchema).Methods("GET")
+	books.HandleFunc("/archive", handlers.Book.GetBooksArchive).Methods("GET")
+	books.HandleFunc("/distinct", handlers.Book.GetDistinctValues).Methods("GET")
+	// Bulk updates commit batch by batch, so they run outside a request transaction
+	books.Handle("/bulk-update", admin(http.HandlerFunc(handlers.Book.BulkUpdateBooks))).Methods("POST")
+	books.HandleFunc("/{id:[0-9A-Za-z-]+}", handlers.Book.GetBook).Methods("GET")
+	books.HandleFunc("/{id:[0-9A-Za-z-]+}/related", handlers.Book.GetRelatedBooks).Methods("GET")
+	books.HandleFunc("/{id:[0-9A-Za-z-]+}/reading-time", handlers.Book.GetReadingTime).Methods("GET")
+	books.HandleFunc("/{id:[0-9A-Za-z-]+}/citation", handlers.Book.GetCitation).Methods("GET")
+	books.Handle("/{id:[0-9A-Za-z-]+}", write(handlers.Book.UpdateBook)).Methods("PUT")
+	books.Handle("/{id:[0-9A-Za-z-]+}", write(handlers.Book.DeleteBook)).Methods("DELETE")
+	books.HandleFunc("/isbn/exists", handlers.Book.CheckISBNsExist).Methods("POST")
 	books.HandleFunc("/isbn/{isbn}", handlers.Book.GetBookByISBN).Methods("GET")
+	books.HandleFunc("/accession/{number:[0-9]{4}-[0-9]+}", handlers.Book.GetBookByAccessionNumber).Methods("GET")
+
+	// Browse routes
+	api.HandleFunc("/authors", handlers.Book.GetAuthors).Methods("GET")
+	api.HandleFunc("/genres", handlers.Book.GetGenres).Methods("GET")
+	api.HandleFunc("/genres/stats", handlers.Book.GetGenreStats).Methods("GET")
+	api.HandleFunc("/publishers", handlers.Book.GetPublishers).Methods("GET")
+	api.HandleFunc("/stats/pages", handlers.Book.GetPageStats).Methods("GET")
+	api.HandleFunc("/stats/growth", handlers.Book.GetGrowth).Methods("GET")
+	api.HandleFunc("/stats/top-searches", handlers.Book.GetTopSearches).Methods("GET")
+
+	// Admin routes
+	api.Handle("/admin/export", admin(http.HandlerFunc(handlers.Book.ExportCatalog))).Methods("POST")
+	api.Handle("/admin/isbn-backfill", admin(http.HandlerFunc(handlers.Book.BackfillISBN13))).Methods("POST")
+	api.Handle("/admin/jobs", admin(http.HandlerFunc(handlers.Book.GetJobs))).Methods("GET")
+	api.Handle("/admin/jobs/{id:[0-9]+}", admin(http.HandlerFunc(handlers.Book.GetJob))).Methods("GET")
+	api.Handle("/admin/authorities/{kind}", admin(http.HandlerFunc(handlers.Book.CreateAuthority))).Methods("POST")
+
+	// API v2: same service, bare-resource bodies and cursor pagination
+	v2 := router.PathPrefix("/api/v2").Subrouter()
+	v2.Use(jsonMiddleware)
+	v2.Use(outputTimezone(handlers.Book.respondBareError))
+	if cfg := handlers.Book.config; cfg != nil && cfg.StrictAccept {
+		v2.Use(requireAcceptable(v2MediaTypes, handlers.Book.respondBareError))
+	}
+	v2.HandleFunc("/books", handlers.Book.GetBooksV2).Methods("GET")
 
 	// Web UI routes - these should come last to not interfere with API
 	router.HandleFunc("/", serveWebUI).Methods("GET")
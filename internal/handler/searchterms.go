@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"container/list"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultTopSearches is how many terms GET /stats/top-searches returns
+// without a limit
+const defaultTopSearches = 10
+
+// SearchTermCount is how often a search term was used
+type SearchTermCount struct {
+	Term  string `json:"term" xml:"term"`
+	Count int    `json:"count" xml:"count"`
+}
+
+// searchTerms counts book list search terms in memory. Once it holds its
+// maximum number of terms, recording a new one evicts the least recently
+// searched. It is safe for concurrent use.
+type searchTerms struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List
+	terms map[string]*list.Element
+}
+
+// newSearchTerms returns a counter keeping up to max terms
+func newSearchTerms(max int) *searchTerms {
+	return &searchTerms{
+		max:   max,
+		order: list.New(),
+		terms: make(map[string]*list.Element),
+	}
+}
+
+// normalizeSearchTerm lower-cases term and collapses its whitespace, so
+// "Go  Programming" and "go programming" count together
+func normalizeSearchTerm(term string) string {
+	return strings.ToLower(strings.Join(strings.Fields(term), " "))
+}
+
+// record counts one search for term; blank terms are ignored
+func (s *searchTerms) record(term string) {
+	term = normalizeSearchTerm(term)
+	if term == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.terms[term]; ok {
+		elem.Value.(*SearchTermCount).Count++
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	if s.order.Len() >= s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.terms, oldest.Value.(*SearchTermCount).Term)
+	}
+	s.terms[term] = s.order.PushFront(&SearchTermCount{Term: term, Count: 1})
+}
+
+// top returns up to limit terms, most searched first, ties broken
+// alphabetically
+func (s *searchTerms) top(limit int) []SearchTermCount {
+	s.mu.Lock()
+	counts := make([]SearchTermCount, 0, s.order.Len())
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		counts = append(counts, *elem.Value.(*SearchTermCount))
+	}
+	s.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Term < counts[j].Term
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
+
+// recordSearch counts the request's search term when recording is enabled
+func (h *BookHandler) recordSearch(term string) {
+	if h.searches != nil {
+		h.searches.record(term)
+	}
+}
+
+// GetTopSearches handles GET /api/v1/stats/top-searches, returning the most
+// common book list search terms since startup
+func (h *BookHandler) GetTopSearches(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTopSearches
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			h.respondError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+	}
+
+	terms := []SearchTermCount{}
+	if h.searches != nil {
+		terms = h.searches.top(limit)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondSuccess(w, r, http.StatusOK, "Top searches retrieved successfully", map[string]interface{}{
+		"recording": h.searches != nil,
+		"terms":     terms,
+	})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"library-management/internal/config"
+	"library-management/pkg/logger"
+)
+
+func TestSearchTerms_Eviction(t *testing.T) {
+	terms := newSearchTerms(2)
+
+	terms.record("Go")
+	terms.record("rust")
+	terms.record("  GO ") // refreshes go, leaving rust least recent
+	terms.record("python")
+
+	got := terms.top(10)
+	if len(got) != 2 || got[0] != (SearchTermCount{Term: "go", Count: 2}) || got[1] != (SearchTermCount{Term: "python", Count: 1}) {
+		t.Errorf("got %+v, want go twice and python once", got)
+	}
+
+	// An evicted term starts counting afresh, evicting go in turn
+	terms.record("rust")
+	got = terms.top(10)
+	if len(got) != 2 || got[0] != (SearchTermCount{Term: "python", Count: 1}) || got[1] != (SearchTermCount{Term: "rust", Count: 1}) {
+		t.Errorf("got %+v, want python and rust once each", got)
+	}
+}
+
+func TestBookHandler_GetTopSearches(t *testing.T) {
+	newRouter := func(cfg *config.Config) *mux.Router {
+		router := mux.NewRouter()
+		SetupRoutes(router, NewHandlers(newStubBookService(), &stubDatabase{}, logger.New(), cfg))
+		return router
+	}
+	send := func(router *mux.Router, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	var resp struct {
+		Data struct {
+			Recording bool              `json:"recording"`
+			Terms     []SearchTermCount `json:"terms"`
+		} `json:"data"`
+	}
+
+	router := newRouter(&config.Config{RecordSearchTerms: true, SearchTermsMax: 10})
+	for _, path := range []string{
+		"/api/v1/books?search=Golang",
+		"/api/v1/books?search=golang",
+		"/api/v1/books?search=Clean+Code",
+		"/api/v1/books?search=golang",
+		"/api/v1/books",
+		"/api/v2/books?search=clean%20%20code",
+	} {
+		if rec := send(router, path); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", path, rec.Code)
+		}
+	}
+
+	rec := send(router, "/api/v1/stats/top-searches?limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !resp.Data.Recording || len(resp.Data.Terms) != 1 || resp.Data.Terms[0] != (SearchTermCount{Term: "golang", Count: 3}) {
+		t.Errorf("got %+v, want golang searched 3 times", resp.Data)
+	}
+
+	if rec := send(router, "/api/v1/stats/top-searches?limit=0"); rec.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want status 400 for a zero limit", rec.Code)
+	}
+
+	// With recording off nothing is kept
+	router = newRouter(&config.Config{})
+	send(router, "/api/v1/books?search=golang")
+	resp.Data.Terms = nil
+	if err := json.NewDecoder(send(router, "/api/v1/stats/top-searches").Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data.Recording || len(resp.Data.Terms) != 0 {
+		t.Errorf("got %+v, want no terms with recording off", resp.Data)
+	}
+}
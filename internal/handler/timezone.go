@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type outputLocationKey struct{}
+
+// outputTimezone reads the optional tz query parameter, an IANA zone name
+// such as "Europe/Paris", and stores the location in the request context
+// for presentBook. Unknown zones are rejected with 400 through respond.
+func outputTimezone(respond func(http.ResponseWriter, *http.Request, int, string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := r.URL.Query().Get("tz")
+			if name == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// LoadLocation treats "" and "Local" as the server's zone,
+			// which clients have no business asking for
+			loc, err := time.LoadLocation(name)
+			if err != nil || name == "Local" {
+				respond(w, r, http.StatusBadRequest, "Invalid tz: must be an IANA time zone name")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), outputLocationKey{}, loc)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// outputLocation returns the zone timestamps are written in: the request's
+// tz parameter if given, otherwise OUTPUT_TIMEZONE
+func (h *BookHandler) outputLocation(r *http.Request) *time.Location {
+	if loc, ok := r.Context().Value(outputLocationKey{}).(*time.Location); ok {
+		return loc
+	}
+	if h.config != nil {
+		return h.config.OutputLocation
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"library-management/internal/domain"
+)
+
+// API v2 shares the v1 service but serializes differently: list responses are
+// the bare resource rather than the status/message/data envelope, and lists
+// use cursor instead of offset pagination.
+
+// GetBooksV2 handles GET /api/v2/books. It returns a JSON array of books in
+// ID order. When more books follow, the Link header points at the next page.
+func (h *BookHandler) GetBooksV2(w http.ResponseWriter, r *http.Request) {
+	filter := h.parseBookFilter(r)
+	if filter.Sort != "" || filter.Order != "" {
+		h.respondBareError(w, r, http.StatusBadRequest, "sort and order are not supported with cursor pagination")
+		return
+	}
+	filter.Sort = "id"
+	if err := h.validateFilter(filter); err != nil {
+		h.respondBareError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := domain.DefaultPageLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			h.respondBareError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if limit > domain.MaxPageLimit {
+			limit = domain.MaxPageLimit
+		}
+	}
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		afterID, err := decodeCursor(cursor)
+		if err != nil {
+			h.respondBareError(w, r, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		filter.AfterID = afterID
+	} else {
+		// Later pages continue the same search, so only the first is counted
+		h.recordSearch(filter.Search)
+	}
+
+	// Fetch one extra book to learn whether there is a next page
+	filter.Limit = limit + 1
+	books, err := h.service.GetAllBooks(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get books", "error", err)
+		h.respondBareError(w, r, http.StatusInternalServerError, "Failed to retrieve books")
+		return
+	}
+
+	if len(books) > limit {
+		books = books[:limit]
+		next := *r.URL
+		query := next.Query()
+		query.Set("cursor", encodeCursor(books[len(books)-1].ID))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+
+	h.setContentHash(w, books)
+	h.presentBooks(r, books)
+	h.respondBare(w, r, http.StatusOK, books)
+}
+
+// encodeCursor returns an opaque cursor for the page after the given book ID
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// decodeCursor returns the book ID a cursor continues after
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return id, nil
+}
+
+// respondBare writes v as the whole JSON response body
+func (h *BookHandler) respondBare(w http.ResponseWriter, r *http.Request, statusCode int, v interface{}) {
+	h.setCacheControl(w, r, statusCode)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if err := h.encodeJSON(w, r, v); err != nil {
+		h.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+// respondBareError writes a v2 error body
+func (h *BookHandler) respondBareError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	recordSpanError(r, statusCode, message)
+	h.respondBare(w, r, statusCode, map[string]string{"error": message})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"library-management/internal/config"
+	"library-management/internal/domain"
+)
+
+func TestGetBooks_VersionEnvelopes(t *testing.T) {
+	second := sampleBook()
+	second.ID = 2
+	router := newTestRouter(newStubBookService(sampleBook(), second), &config.Config{})
+
+	t.Run("v1 wraps books in the response envelope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/books", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Status string `json:"status"`
+			Data   struct {
+				Books []domain.Book `json:"books"`
+				Meta  struct {
+					Total int `json:"total"`
+				} `json:"meta"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.Status != "success" || len(body.Data.Books) != 2 || body.Data.Meta.Total != 2 {
+			t.Errorf("Unexpected v1 body %+v", body)
+		}
+	})
+
+	t.Run("v2 returns a bare array", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/books", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		var books []domain.Book
+		if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+			t.Fatalf("want a JSON array: %v", err)
+		}
+		if len(books) != 2 {
+			t.Errorf("got %d, want 2 books", len(books))
+		}
+		if link := rec.Header().Get("Link"); link != "" {
+			t.Errorf("got %q, want no Link header on the last page", link)
+		}
+	})
+}
+
+func TestGetBooksV2_CursorPagination(t *testing.T) {
+	var books []*domain.Book
+	for id := 1; id <= 5; id++ {
+		book := sampleBook()
+		book.ID = id
+		books = append(books, book)
+	}
+	router := newTestRouter(newStubBookService(books...), &config.Config{})
+
+	var seen []int
+	url := "/api/v2/books?limit=2&genre=Programming"
+	for pages := 0; url != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("Pagination did not terminate")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d, want status 200 for %s: %s", rec.Code, url, rec.Body.String())
+		}
+
+		var page []domain.Book
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		for _, book := range page {
+			seen = append(seen, book.ID)
+		}
+
+		url = ""
+		if link := rec.Header().Get("Link"); link != "" {
+			if !strings.HasSuffix(link, `>; rel="next"`) || !strings.Contains(link, "genre=Programming") {
+				t.Fatalf("Unexpected Link header %q", link)
+			}
+			url = strings.TrimPrefix(strings.TrimSuffix(link, `>; rel="next"`), "<")
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("got %v, want all 5 books across pages", seen)
+	}
+	for i, id := range seen {
+		if id != i+1 {
+			t.Fatalf("got %v, want books in ID order", seen)
+		}
+	}
+}
+
+func TestGetBooksV2_Errors(t *testing.T) {
+	router := newTestRouter(newStubBookService(sampleBook()), &config.Config{})
+
+	for _, query := range []string{"?cursor=not-a-cursor", "?limit=0", "?sort=title"} {
+		t.Run(query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/books"+query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["error"] == "" {
+				t.Errorf("got %s, want a bare error body", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetBooksV2_DefaultAvailableOnly(t *testing.T) {
+	checkedOut := sampleBook()
+	checkedOut.ID = 2
+	checkedOut.Available = false
+	cfg := adminConfig()
+	cfg.DefaultAvailableOnly = true
+	router := newTestRouter(newStubBookService(sampleBook(), checkedOut), cfg)
+
+	list := func(query, key string) []domain.Book {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/books"+query, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		var books []domain.Book
+		if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+			t.Fatalf("want a JSON array: %v", err)
+		}
+		return books
+	}
+
+	if books := list("", ""); len(books) != 1 || !books[0].Available {
+		t.Errorf("got %+v, want only the available book", books)
+	}
+	if books := list("?available=any", ""); len(books) != 2 {
+		t.Errorf("got %d books, want an explicit parameter to list everything", len(books))
+	}
+	if books := list("", adminKey); len(books) != 2 {
+		t.Errorf("got %d books, want an admin key to list everything", len(books))
+	}
+}
@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the state of a job
+type Status string
+
+// Job statuses; a queued job is canceled if shutdown starts before it runs
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// retainFinished is how many finished jobs are kept for status lookups
+const retainFinished = 100
+
+var (
+	// ErrBusy is returned when every slot is taken and the queue is full
+	ErrBusy = errors.New("too many jobs running")
+	// ErrShuttingDown is returned for jobs submitted after Shutdown
+	ErrShuttingDown = errors.New("job limiter is shutting down")
+)
+
+// Func is the work of a job; its result is kept on the job once it succeeds
+type Func func(ctx context.Context) (interface{}, error)
+
+// Job is a snapshot of a heavy operation tracked by a Limiter
+type Job struct {
+	ID         int         `json:"id" xml:"id"`
+	Kind       string      `json:"kind" xml:"kind"`
+	Status     Status      `json:"status" xml:"status"`
+	Result     interface{} `json:"result,omitempty" xml:"-"`
+	Error      string      `json:"error,omitempty" xml:"error,omitempty"`
+	QueuedAt   time.Time   `json:"queued_at" xml:"queued_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty" xml:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty" xml:"finished_at,omitempty"`
+}
+
+// Limiter runs heavy operations such as catalog exports, at most a fixed
+// number at a time, and keeps their status. Job contexts are canceled by
+// Shutdown.
+type Limiter struct {
+	slots     chan struct{}
+	maxQueued int
+
+	// queue is canceled as soon as Shutdown starts, dropping waiting jobs;
+	// ctx is canceled once running jobs are out of time
+	queue     context.Context
+	stopQueue context.CancelFunc
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	mu     sync.Mutex
+	nextID int
+	jobs   map[int]*Job
+	order  []int
+	queued int
+	closed bool
+}
+
+// NewLimiter creates a limiter running up to maxRunning jobs at once, with
+// up to maxQueued more waiting for a slot
+func NewLimiter(maxRunning, maxQueued int) *Limiter {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue, stopQueue := context.WithCancel(ctx)
+	return &Limiter{
+		slots:     make(chan struct{}, maxRunning),
+		maxQueued: maxQueued,
+		queue:     queue,
+		stopQueue: stopQueue,
+		ctx:       ctx,
+		cancel:    cancel,
+		jobs:      make(map[int]*Job),
+	}
+}
+
+// Run runs fn as a job of kind. When a slot is free fn runs in the caller
+// and Run returns the finished job with fn's error. Otherwise the job is
+// queued to run in the background once a slot frees, and Run returns it
+// still queued; with the queue full it returns ErrBusy.
+func (l *Limiter) Run(ctx context.Context, kind string, fn Func) (Job, error) {
+	select {
+	case l.slots <- struct{}{}:
+		job, err := l.add(kind)
+		if err != nil {
+			<-l.slots
+			return Job{}, err
+		}
+		return l.execute(ctx, job, fn)
+	default:
+	}
+
+	l.mu.Lock()
+	if l.queued >= l.maxQueued {
+		l.mu.Unlock()
+		return Job{}, ErrBusy
+	}
+	l.queued++
+	l.mu.Unlock()
+
+	job, err := l.add(kind)
+	if err != nil {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+		return Job{}, err
+	}
+	snapshot := l.snapshot(job)
+
+	go func() {
+		select {
+		case l.slots <- struct{}{}:
+			l.mu.Lock()
+			l.queued--
+			l.mu.Unlock()
+			l.execute(l.ctx, job, fn)
+		case <-l.queue.Done():
+			l.mu.Lock()
+			l.queued--
+			l.mu.Unlock()
+			l.finish(job, nil, ErrShuttingDown, StatusCanceled)
+		}
+	}()
+	return snapshot, nil
+}
+
+// Do runs fn as a job of kind in the caller, waiting for a free slot
+// however busy the limiter is. It suits background callers such as
+// scheduled backups.
+func (l *Limiter) Do(ctx context.Context, kind string, fn Func) (Job, error) {
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	case <-l.queue.Done():
+		return Job{}, ErrShuttingDown
+	}
+
+	job, err := l.add(kind)
+	if err != nil {
+		<-l.slots
+		return Job{}, err
+	}
+	return l.execute(ctx, job, fn)
+}
+
+// Get returns the job with the given ID
+func (l *Limiter) Get(id int) (Job, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	job, ok := l.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// List returns the tracked jobs, newest first
+func (l *Limiter) List() []Job {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	jobs := make([]Job, 0, len(l.jobs))
+	for _, job := range l.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// Shutdown stops accepting jobs, cancels queued ones and waits for running
+// jobs to finish. If ctx expires first their contexts are canceled too.
+func (l *Limiter) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	l.stopQueue()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		l.cancel()
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// add registers a new job, or fails once the limiter is shut down
+func (l *Limiter) add(kind string) (*Job, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, ErrShuttingDown
+	}
+	l.nextID++
+	job := &Job{ID: l.nextID, Kind: kind, Status: StatusQueued, QueuedAt: time.Now().UTC()}
+	l.jobs[job.ID] = job
+	l.order = append(l.order, job.ID)
+	l.wg.Add(1)
+	return job, nil
+}
+
+// execute runs fn for job in the slot the caller holds, then frees it
+func (l *Limiter) execute(ctx context.Context, job *Job, fn Func) (Job, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(l.ctx, cancel)
+	defer func() {
+		stop()
+		cancel()
+		<-l.slots
+	}()
+
+	l.mu.Lock()
+	now := time.Now().UTC()
+	job.Status = StatusRunning
+	job.StartedAt = &now
+	l.mu.Unlock()
+
+	result, err := fn(ctx)
+	status := StatusSucceeded
+	if err != nil {
+		status = StatusFailed
+	}
+	return l.finish(job, result, err, status), err
+}
+
+// finish records the outcome of job and forgets the oldest finished jobs
+// beyond retainFinished
+func (l *Limiter) finish(job *Job, result interface{}, err error, status Status) Job {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.wg.Done()
+
+	now := time.Now().UTC()
+	job.Status = status
+	job.FinishedAt = &now
+	if err != nil {
+		job.Error = err.Error()
+	} else {
+		job.Result = result
+	}
+
+	finished := 0
+	for _, id := range l.order {
+		if l.jobs[id].FinishedAt != nil {
+			finished++
+		}
+	}
+	kept := l.order[:0]
+	for _, id := range l.order {
+		if finished > retainFinished && l.jobs[id].FinishedAt != nil {
+			delete(l.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	l.order = kept
+
+	return *job
+}
+
+// snapshot copies job under the lock
+func (l *Limiter) snapshot(job *Job) Job {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *job
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingJob returns a job function that signals started and then waits
+// for release or its context to end
+func blockingJob(started chan<- struct{}, release <-chan struct{}) Func {
+	return func(ctx context.Context) (interface{}, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return "done", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// waitForStatus polls until job id reaches status
+func waitForStatus(t *testing.T, l *Limiter, id int, status Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := l.Get(id); ok && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := l.Get(id)
+	t.Fatalf("Job %d did not reach %s, last status %s", id, status, job.Status)
+	return job
+}
+
+func TestLimiter_Saturation(t *testing.T) {
+	l := NewLimiter(1, 1)
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	// The first job takes the only slot and runs in its caller
+	firstDone := make(chan Job, 1)
+	go func() {
+		job, _ := l.Run(ctx, "export", blockingJob(started, release))
+		firstDone <- job
+	}()
+	<-started
+
+	// The second waits in the queue
+	queued, err := l.Run(ctx, "export", blockingJob(started, release))
+	if err != nil {
+		t.Fatalf("got %v, want the second job to be queued", err)
+	}
+	if queued.Status != StatusQueued {
+		t.Errorf("status = %s, want %s", queued.Status, StatusQueued)
+	}
+
+	// The third finds the queue full
+	if _, err := l.Run(ctx, "export", blockingJob(started, release)); !errors.Is(err, ErrBusy) {
+		t.Fatalf("got %v, want ErrBusy", err)
+	}
+
+	if jobs := l.List(); len(jobs) != 2 || jobs[0].ID != queued.ID {
+		t.Errorf("got %+v, want two jobs newest first", jobs)
+	}
+
+	release <- struct{}{}
+	if first := <-firstDone; first.Status != StatusSucceeded || first.Result != "done" {
+		t.Errorf("got %+v, want first job to succeed", first)
+	}
+
+	// The queued job starts once the slot frees
+	<-started
+	waitForStatus(t, l, queued.ID, StatusRunning)
+	release <- struct{}{}
+	job := waitForStatus(t, l, queued.ID, StatusSucceeded)
+	if job.StartedAt == nil || job.FinishedAt == nil {
+		t.Errorf("got %+v, want start and finish times", job)
+	}
+
+	// With the slot free again, jobs run at once
+	job, err = l.Run(ctx, "export", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("storage unavailable")
+	})
+	if err == nil || job.Status != StatusFailed || job.Error != "storage unavailable" {
+		t.Errorf("got %+v, %v, want failed job with error", job, err)
+	}
+}
+
+func TestLimiter_DoWaitsForSlot(t *testing.T) {
+	l := NewLimiter(1, 0)
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	go l.Run(ctx, "export", blockingJob(started, release))
+	<-started
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Do(ctx, "backup", blockingJob(started, release))
+		done <- err
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("want Do to wait while the slot is taken")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release <- struct{}{}
+	<-started
+	release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLimiter_Shutdown(t *testing.T) {
+	l := NewLimiter(1, 1)
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	running := make(chan error, 1)
+	go func() {
+		_, err := l.Run(ctx, "export", blockingJob(started, release))
+		running <- err
+	}()
+	<-started
+
+	queued, err := l.Run(ctx, "export", blockingJob(started, release))
+	if err != nil {
+		t.Fatalf("got %v, want the second job to be queued", err)
+	}
+
+	// Running jobs are canceled once the shutdown deadline passes
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := l.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+	if err := <-running; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the running job to be canceled", err)
+	}
+
+	if job, _ := l.Get(queued.ID); job.Status != StatusCanceled {
+		t.Errorf("got %s, want the queued job to be canceled", job.Status)
+	}
+	if _, err := l.Run(ctx, "export", blockingJob(started, release)); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("got %v, want ErrShuttingDown", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package publicid generates the opaque public IDs books are addressed by
+// when PUBLIC_IDS is on. The format is chosen at startup; every generator
+// produces IDs accepted by domain.IsPublicID and the {id} route pattern.
+package publicid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"library-management/internal/domain"
+)
+
+// Format names a public ID generator
+type Format string
+
+const (
+	// FormatUUID generates random version 4 UUIDs
+	FormatUUID Format = "uuid"
+	// FormatNanoID generates short random IDs of letters and digits
+	FormatNanoID Format = "nanoid"
+	// FormatSlug derives readable IDs from the book title
+	FormatSlug Format = "slug"
+)
+
+// ParseFormat parses a public ID format name
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatUUID, FormatNanoID, FormatSlug:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid public ID format %q: must be uuid, nanoid or slug", value)
+	}
+}
+
+// TakenFunc reports whether a public ID already belongs to a book
+type TakenFunc func(ctx context.Context, id string) bool
+
+// Generator creates the public ID of a new book
+type Generator interface {
+	// Generate returns a public ID for book. Generators whose IDs can
+	// collide use taken to find one that is free.
+	Generate(ctx context.Context, book *domain.Book, taken TakenFunc) (string, error)
+}
+
+// New returns the generator for format
+func New(format Format) (Generator, error) {
+	switch format {
+	case FormatUUID:
+		return uuidGenerator{}, nil
+	case FormatNanoID:
+		return nanoIDGenerator{}, nil
+	case FormatSlug:
+		return slugGenerator{}, nil
+	default:
+		return nil, fmt.Errorf("invalid public ID format %q: must be uuid, nanoid or slug", format)
+	}
+}
+
+type uuidGenerator struct{}
+
+// Generate returns a random version 4 UUID
+func (uuidGenerator) Generate(ctx context.Context, book *domain.Book, taken TakenFunc) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate public ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// nanoIDAlphabet leaves out the "_" and "-" of the usual nano ID alphabet
+// so IDs stay within the {id} route pattern
+const nanoIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// nanoIDLength gives about 125 bits of randomness
+const nanoIDLength = 21
+
+type nanoIDGenerator struct{}
+
+// Generate returns a random nano ID. Bytes that would bias the alphabet are
+// discarded, and the all-digit IDs that read as sequential IDs are redrawn.
+func (nanoIDGenerator) Generate(ctx context.Context, book *domain.Book, taken TakenFunc) (string, error) {
+	const unbiased = 256 - 256%len(nanoIDAlphabet)
+
+	for {
+		id := make([]byte, 0, nanoIDLength)
+		buf := make([]byte, nanoIDLength*2)
+		for len(id) < nanoIDLength {
+			if _, err := rand.Read(buf); err != nil {
+				return "", fmt.Errorf("failed to generate public ID: %w", err)
+			}
+			for _, b := range buf {
+				if int(b) < unbiased && len(id) < nanoIDLength {
+					id = append(id, nanoIDAlphabet[int(b)%len(nanoIDAlphabet)])
+				}
+			}
+		}
+		if domain.IsPublicID(string(id)) {
+			return string(id), nil
+		}
+	}
+}
+
+// maxSlugLength bounds the title part of a slug, before any counter
+const maxSlugLength = 60
+
+// maxSlugAttempts bounds the counters tried on collision
+const maxSlugAttempts = 1000
+
+type slugGenerator struct{}
+
+// Generate returns the slug of the book's title, appending -2, -3 and so on
+// while the slug is taken
+func (slugGenerator) Generate(ctx context.Context, book *domain.Book, taken TakenFunc) (string, error) {
+	base := Slugify(book.Title)
+	for n := 1; n <= maxSlugAttempts; n++ {
+		id := base
+		if n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+		if taken == nil || !taken(ctx, id) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate public ID: slug %q is taken %d times", base, maxSlugAttempts)
+}
+
+// Slugify lower-cases title and joins its ASCII letters and digits with
+// hyphens, e.g. "The Pragmatic Programmer (2nd Ed.)" becomes
+// "the-pragmatic-programmer-2nd-ed". Titles that give no slug, or only
+// digits, are prefixed with "book" so the slug cannot pass for a sequential ID.
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" {
+		return "book"
+	}
+	if !domain.IsPublicID(slug) {
+		return "book-" + slug
+	}
+	return slug
+}
@@ -0,0 +1,110 @@
+package publicid
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"library-management/internal/domain"
+)
+
+// routeIDPattern is the {id} pattern book routes are registered with
+var routeIDPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+func TestGenerators(t *testing.T) {
+	ctx := context.Background()
+	book := &domain.Book{Title: "The Pragmatic Programmer (2nd Ed.)"}
+
+	tests := []struct {
+		format  Format
+		pattern *regexp.Regexp
+	}{
+		{FormatUUID, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{FormatNanoID, regexp.MustCompile(`^[0-9A-Za-z]{21}$`)},
+		{FormatSlug, regexp.MustCompile(`^the-pragmatic-programmer-2nd-ed$`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			g, err := New(tt.format)
+			if err != nil {
+				t.Fatalf("New(%q) returned error %v", tt.format, err)
+			}
+
+			seen := make(map[string]bool)
+			for i := 0; i < 50; i++ {
+				id, err := g.Generate(ctx, book, nil)
+				if err != nil {
+					t.Fatalf("Generate returned error %v", err)
+				}
+				if !tt.pattern.MatchString(id) {
+					t.Fatalf("Unexpected %s ID %q", tt.format, id)
+				}
+				if !domain.IsPublicID(id) || !routeIDPattern.MatchString(id) {
+					t.Fatalf("ID %q is not accepted as a public ID by the routes", id)
+				}
+				seen[id] = true
+			}
+			if tt.format != FormatSlug && len(seen) != 50 {
+				t.Errorf("got %d, want 50 distinct IDs", len(seen))
+			}
+		})
+	}
+}
+
+func TestSlugGenerator_Collisions(t *testing.T) {
+	ctx := context.Background()
+	g, _ := New(FormatSlug)
+
+	taken := map[string]bool{"clean-code": true, "clean-code-2": true}
+	isTaken := func(ctx context.Context, id string) bool { return taken[id] }
+
+	id, err := g.Generate(ctx, &domain.Book{Title: "Clean Code"}, isTaken)
+	if err != nil {
+		t.Fatalf("Generate returned error %v", err)
+	}
+	if id != "clean-code-3" {
+		t.Errorf("got %q, want clean-code-3", id)
+	}
+
+	// Every candidate taken
+	always := func(ctx context.Context, id string) bool { return true }
+	if _, err := g.Generate(ctx, &domain.Book{Title: "Clean Code"}, always); err == nil {
+		t.Error("want error once every counter is taken")
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Clean Code", "clean-code"},
+		{"  Go -- The  Language!  ", "go-the-language"},
+		{"Café Olé", "caf-ol"},
+		{"1984", "book-1984"},
+		{"???", "book"},
+		{"", "book"},
+		{"An extraordinarily long title that keeps going well past the limit set for slugs", "an-extraordinarily-long-title-that-keeps-going-well-past-the"},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+		if !domain.IsPublicID(Slugify(tt.title)) {
+			t.Errorf("Slugify(%q) is not a public ID", tt.title)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"uuid", "NanoID", "slug"} {
+		if _, err := ParseFormat(value); err != nil {
+			t.Errorf("ParseFormat(%q) returned error %v", value, err)
+		}
+	}
+	if _, err := ParseFormat("ulid"); err == nil {
+		t.Error("want error for unsupported format")
+	}
+}
@@ -13,9 +13,24 @@ type BookRepository interface {
 	// GetByID retrieves a book by its ID
 	GetByID(ctx context.Context, id int) (*domain.Book, error)
 	
+	// GetByIDForUpdate retrieves a book by its ID and locks its row until the
+	// transaction in ctx ends, so a check on the book holds until the write
+	GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error)
+	
+	// GetByPublicID retrieves a book by its public ID
+	GetByPublicID(ctx context.Context, publicID string) (*domain.Book, error)
+	
 	// GetAll retrieves all books with optional filtering
 	GetAll(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error)
 	
+	// GetRandom returns a random book matching the filter, or nil when none match
+	GetRandom(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error)
+	
+	// ForEach calls fn for every book matching filter (all books when nil) in
+	// ID order, streaming rows rather than loading them all, and stops at the
+	// first error fn returns. The filter's sort and limit are ignored.
+	ForEach(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error
+	
 	// Update updates an existing book
 	Update(ctx context.Context, book *domain.Book) (*domain.Book, error)
 	
@@ -25,6 +40,93 @@ type BookRepository interface {
 	// GetByISBN retrieves a book by its ISBN
 	GetByISBN(ctx context.Context, isbn string) (*domain.Book, error)
 	
+	// GetByAccessionNumber retrieves a book by its accession number
+	GetByAccessionNumber(ctx context.Context, number string) (*domain.Book, error)
+	
+	// ExistingISBNs returns which of the given normalized ISBNs belong to a
+	// book, comparing against each book's normalized ISBN
+	ExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error)
+	
 	// Count returns the total number of books with optional filtering
 	Count(ctx context.Context, filter *domain.BookFilter) (int, error)
-}
\ No newline at end of file
+	
+	// GetAuthors returns distinct authors with their book counts, paginated
+	GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, error)
+	
+	// CountAuthors returns the number of distinct authors
+	CountAuthors(ctx context.Context) (int, error)
+	
+	// GetGenres returns distinct genres with their book counts, paginated
+	GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, error)
+	
+	// CountGenres returns the number of distinct genres
+	CountGenres(ctx context.Context) (int, error)
+	
+	// GetPublishers returns distinct publishers with their book counts,
+	// paginated; a non-empty prefix keeps publishers starting with it, ignoring case
+	GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, error)
+	
+	// CountPublishers returns the number of distinct publishers starting with prefix
+	CountPublishers(ctx context.Context, prefix string) (int, error)
+	
+	// GetDistinctValues returns the distinct values of field among the books
+	// matching the filter, with their book counts, paginated in value order.
+	// The field must be one of domain.DistinctFields.
+	GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, error)
+	
+	// CountDistinctValues returns the number of distinct values of field among
+	// the books matching the filter
+	CountDistinctValues(ctx context.Context, field string, filter *domain.BookFilter) (int, error)
+	
+	// BulkUpdate applies the changes to the books matching the filter, or to the
+	// first filter.Limit of them in ID order when Limit is set, and returns the
+	// number affected and the highest ID updated
+	BulkUpdate(ctx context.Context, filter *domain.BookFilter, changes *domain.BulkBookChanges) (int, int, error)
+	
+	// ConvertISBNs replaces each book's ISBN with its converted form in one
+	// transaction. With keepOriginal set, the replaced ISBN is kept in the
+	// book's original_isbn.
+	ConvertISBNs(ctx context.Context, changes []domain.ISBNChange, keepOriginal bool) error
+	
+	// GetNeedingAttention returns books with data-quality issues and their
+	// reasons, ordered by ID and paginated
+	GetNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, error)
+	
+	// CountNeedingAttention returns the number of books with data-quality issues
+	CountNeedingAttention(ctx context.Context) (int, error)
+	
+	// GetGenreStats returns each genre with its total, available and checked-out counts
+	GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error)
+	
+	// GetPageStats returns the count, total, min, max, average and median
+	// pages of the books matching the filter
+	GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error)
+	
+	// GetGrowth returns the number of books created per bucket of the query,
+	// leaving out buckets without books, oldest first
+	GetGrowth(ctx context.Context, query *domain.GrowthQuery) ([]*domain.GrowthBucket, error)
+	
+	// EstimateCount returns the planner's estimate of the number of books matching the filter
+	EstimateCount(ctx context.Context, filter *domain.BookFilter) (int, error)
+	
+	// Upsert inserts the book, or updates the existing book with the same ISBN,
+	// and reports whether a new book was created
+	Upsert(ctx context.Context, book *domain.Book) (*domain.Book, bool, error)
+	
+	// FindByTitleAuthor returns the books whose title and author match,
+	// ignoring case and surrounding whitespace, in ID order
+	FindByTitleAuthor(ctx context.Context, title, author string) ([]*domain.Book, error)
+	
+	// GetRelated returns up to limit other books sharing the book's author or genre,
+	// same-author books first
+	GetRelated(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error)
+	
+	// AuthorityExists reports whether name is in the reference table of kind,
+	// ignoring case
+	AuthorityExists(ctx context.Context, kind domain.AuthorityKind, name string) (bool, error)
+	
+	// CreateAuthority adds the authority to the reference table of its kind and
+	// reports whether it was added; a name already there, ignoring case, is
+	// returned as stored
+	CreateAuthority(ctx context.Context, authority *domain.Authority) (*domain.Authority, bool, error)
+}
@@ -3,62 +3,205 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/lib/pq"
+	"library-management/internal/database"
 	"library-management/internal/domain"
 	"library-management/internal/repository"
 )
 
 type bookRepository struct {
 	db *sql.DB
+
+	// replica, when set, serves reads outside of the lag window after a write
+	replica   *sql.DB
+	lagWindow time.Duration
+	lastWrite atomic.Int64
+	now       func() time.Time
+
+	// maxRetries and retryBackoff control retries of idempotent writes
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// DefaultReplicaLagWindow is how long reads stay on the primary after a write
+const DefaultReplicaLagWindow = 5 * time.Second
+
+// Option configures a book repository
+type Option func(*bookRepository)
+
+// WithReadReplica routes read queries to the given read-only pool
+func WithReadReplica(replica *sql.DB) Option {
+	return func(r *bookRepository) {
+		r.replica = replica
+	}
+}
+
+// WithReplicaLagWindow sets how long reads stay on the primary after a write,
+// so a just-written book is visible before the replica catches up
+func WithReplicaLagWindow(window time.Duration) Option {
+	return func(r *bookRepository) {
+		r.lagWindow = window
+	}
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the request-scoped transaction from ctx when present, else the pool
+func (r *bookRepository) conn(ctx context.Context) querier {
+	if tx, ok := database.TxFromContext(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// readConn is like conn but prefers the replica, unless none is configured
+// or a write happened within the lag window
+func (r *bookRepository) readConn(ctx context.Context) querier {
+	if tx, ok := database.TxFromContext(ctx); ok {
+		return tx
+	}
+	if r.replica == nil {
+		return r.db
+	}
+	if r.now().Sub(time.Unix(0, r.lastWrite.Load())) < r.lagWindow {
+		return r.db
+	}
+	return r.replica
+}
+
+// markWrite records that the primary was just written to
+func (r *bookRepository) markWrite() {
+	r.lastWrite.Store(r.now().UnixNano())
 }
 
 // NewBookRepository creates a new PostgreSQL book repository
-func NewBookRepository(db *sql.DB) repository.BookRepository {
-	return &bookRepository{db: db}
+func NewBookRepository(db *sql.DB, opts ...Option) repository.BookRepository {
+	r := &bookRepository{
+		db:           db,
+		lagWindow:    DefaultReplicaLagWindow,
+		now:          time.Now,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// Create creates a new book
+// Create creates a new book. A book without a public ID is given a random UUID.
 func (r *bookRepository) Create(ctx context.Context, book *domain.Book) (*domain.Book, error) {
 	query := `
-		INSERT INTO books (title, author, isbn, publisher, publish_year, genre, pages, available, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO books (title, author, isbn, publisher, publish_year, genre, pages, available, description, created_at, updated_at, public_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE(NULLIF($12, ''), gen_random_uuid()::text))
+		RETURNING id, public_id, accession_number, created_at, updated_at`
 
-	err := r.db.QueryRowContext(
+	err := r.conn(ctx).QueryRowContext(
 		ctx, query,
 		book.Title, book.Author, book.ISBN, book.Publisher,
 		book.PublishYear, book.Genre, book.Pages, book.Available,
-		book.Description, book.CreatedAt, book.UpdatedAt,
-	).Scan(&book.ID, &book.CreatedAt, &book.UpdatedAt)
+		book.Description, book.CreatedAt, book.UpdatedAt, book.PublicID,
+	).Scan(&book.ID, &book.PublicID, &book.AccessionNumber, &book.CreatedAt, &book.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create book: %w", err)
 	}
+	r.markWrite()
 
 	return book, nil
 }
 
+// Upsert inserts the book, or updates the existing book with the same ISBN.
+// The existing row keeps its ID and created_at.
+func (r *bookRepository) Upsert(ctx context.Context, book *domain.Book) (*domain.Book, bool, error) {
+	query := `
+		INSERT INTO books (title, author, isbn, publisher, publish_year, genre, pages, available, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (isbn) DO UPDATE
+		SET title = EXCLUDED.title, author = EXCLUDED.author, publisher = EXCLUDED.publisher,
+		    publish_year = EXCLUDED.publish_year, genre = EXCLUDED.genre, pages = EXCLUDED.pages,
+		    available = EXCLUDED.available, description = EXCLUDED.description,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING id, public_id, accession_number, created_at, updated_at, (xmax = 0) AS created`
+
+	var created bool
+	err := r.withRetry(ctx, func() error {
+		return r.conn(ctx).QueryRowContext(
+			ctx, query,
+			book.Title, book.Author, book.ISBN, book.Publisher,
+			book.PublishYear, book.Genre, book.Pages, book.Available,
+			book.Description, book.CreatedAt, book.UpdatedAt,
+		).Scan(&book.ID, &book.PublicID, &book.AccessionNumber, &book.CreatedAt, &book.UpdatedAt, &created)
+	})
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to upsert book: %w", err)
+	}
+	r.markWrite()
+
+	return book, created, nil
+}
+
 // GetByID retrieves a book by its ID
 func (r *bookRepository) GetByID(ctx context.Context, id int) (*domain.Book, error) {
 	query := `
-		SELECT id, title, author, isbn, publisher, publish_year, genre, 
-		       pages, available, description, created_at, updated_at
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre, 
+		       pages, available, description, accession_number, created_at, updated_at
 		FROM books 
 		WHERE id = $1`
 
 	book := &domain.Book{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&book.ID, &book.Title, &book.Author, &book.ISBN,
+	err := r.readConn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+		&book.Publisher, &book.PublishYear, &book.Genre,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+		&book.CreatedAt, &book.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	return book, nil
+}
+
+// GetByIDForUpdate retrieves a book by its ID with SELECT ... FOR UPDATE on
+// the primary, so the row stays locked until the transaction in ctx ends.
+// A concurrent caller waits for that transaction and then reads the book as
+// it left it. Without a transaction the lock is released at once.
+func (r *bookRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books
+		WHERE id = $1
+		FOR UPDATE`
+
+	book := &domain.Book{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
 		&book.Publisher, &book.PublishYear, &book.Genre,
-		&book.Pages, &book.Available, &book.Description,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
 		&book.CreatedAt, &book.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("book with ID %d not found", id)
+			return nil, fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
 		}
 		return nil, fmt.Errorf("failed to get book: %w", err)
 	}
@@ -69,54 +212,203 @@ func (r *bookRepository) GetByID(ctx context.Context, id int) (*domain.Book, err
 // GetAll retrieves all books with optional filtering
 func (r *bookRepository) GetAll(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
 	query := `
-		SELECT id, title, author, isbn, publisher, publish_year, genre, 
-		       pages, available, description, created_at, updated_at
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre, 
+		       pages, available, description, accession_number, created_at, updated_at
 		FROM books`
 
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+	where, args := buildWhereClause(filter, 1)
+	query += where
 
-	if filter != nil {
-		if filter.Author != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(author) LIKE LOWER($%d)", argIndex))
-			args = append(args, "%"+filter.Author+"%")
-			argIndex++
-		}
+	orderBy, orderArgs := buildOrderBy(filter, len(args)+1)
+	query += orderBy
+	args = append(args, orderArgs...)
+
+	if filter != nil && filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query books: %w", err)
+	}
+	defer rows.Close()
 
-		if filter.Genre != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(genre) = LOWER($%d)", argIndex))
-			args = append(args, filter.Genre)
-			argIndex++
+	var books []*domain.Book
+	for rows.Next() {
+		book := &domain.Book{}
+		err := rows.Scan(
+			&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+			&book.Publisher, &book.PublishYear, &book.Genre,
+			&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+			&book.CreatedAt, &book.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan book: %w", err)
 		}
+		books = append(books, book)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return books, nil
+}
+
+// ForEach calls fn for every book matching filter in ID order while iterating
+// the result rows
+func (r *bookRepository) ForEach(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books`
+
+	where, args := buildWhereClause(filter, 1)
+	query += where + " ORDER BY id"
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query books: %w", err)
+	}
+	defer rows.Close()
 
-		if filter.Available != nil {
-			conditions = append(conditions, fmt.Sprintf("available = $%d", argIndex))
-			args = append(args, *filter.Available)
-			argIndex++
+	for rows.Next() {
+		book := &domain.Book{}
+		err := rows.Scan(
+			&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+			&book.Publisher, &book.PublishYear, &book.Genre,
+			&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+			&book.CreatedAt, &book.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan book: %w", err)
+		}
+		if err := fn(book); err != nil {
+			return err
 		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
 
-		if filter.Search != "" {
-			searchCondition := fmt.Sprintf(`(
-				LOWER(title) LIKE LOWER($%d) OR 
-				LOWER(author) LIKE LOWER($%d) OR 
-				LOWER(description) LIKE LOWER($%d)
-			)`, argIndex, argIndex, argIndex)
-			conditions = append(conditions, searchCondition)
-			args = append(args, "%"+filter.Search+"%")
-			argIndex++
+// normalizedISBN is the SQL form of domain.NormalizeISBN, matching the
+// idx_books_isbn_normalized expression index
+const normalizedISBN = `UPPER(REPLACE(REPLACE(isbn, '-', ''), ' ', ''))`
+
+// ExistingISBNs returns which of the given normalized ISBNs belong to a book,
+// in a single query
+func (r *bookRepository) ExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error) {
+	query := `SELECT ` + normalizedISBN + ` FROM books WHERE ` + normalizedISBN + ` = ANY($1)`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, pq.Array(isbns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ISBNs: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var isbn string
+		if err := rows.Scan(&isbn); err != nil {
+			return nil, fmt.Errorf("failed to scan ISBN: %w", err)
 		}
+		existing[isbn] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ISBNs: %w", err)
+	}
+
+	return existing, nil
+}
+
+// GetRandom returns a random book matching the filter, or nil when none match.
+// It skips a random number of the matching rows rather than loading them all.
+func (r *bookRepository) GetRandom(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	where, args := buildWhereClause(filter, 1)
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, COALESCE(description, ''), created_at, updated_at
+		FROM books` + where + `
+		ORDER BY id
+		OFFSET floor(random() * (SELECT COUNT(*) FROM books` + where + `))::bigint
+		LIMIT 1`
 
-		if len(conditions) > 0 {
-			query += " WHERE " + strings.Join(conditions, " AND ")
+	book := &domain.Book{}
+	err := r.readConn(ctx).QueryRowContext(ctx, query, args...).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+		&book.Publisher, &book.PublishYear, &book.Genre,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+		&book.CreatedAt, &book.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get random book: %w", err)
+	}
+
+	return book, nil
+}
+
+// GetRelated returns up to limit other books sharing the book's author or genre.
+// A shared author scores above a shared genre, so same-author books come first.
+func (r *bookRepository) GetRelated(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error) {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books
+		WHERE id <> $1 AND (author = $2 OR genre = $3)
+		ORDER BY (CASE WHEN author = $2 THEN 2 ELSE 0 END) + (CASE WHEN genre = $3 THEN 1 ELSE 0 END) DESC,
+		         created_at DESC, id ASC
+		LIMIT $4`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, book.ID, book.Author, book.Genre, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related books: %w", err)
+	}
+	defer rows.Close()
+
+	var books []*domain.Book
+	for rows.Next() {
+		related := &domain.Book{}
+		err := rows.Scan(
+			&related.ID, &related.PublicID, &related.Title, &related.Author, &related.ISBN,
+			&related.Publisher, &related.PublishYear, &related.Genre,
+			&related.Pages, &related.Available, &related.Description, &related.AccessionNumber,
+			&related.CreatedAt, &related.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan related book: %w", err)
 		}
+		books = append(books, related)
 	}
 
-	query += " ORDER BY created_at DESC"
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return books, nil
+}
+
+// FindByTitleAuthor returns the books whose title and author match,
+// ignoring case and surrounding whitespace, in ID order
+func (r *bookRepository) FindByTitleAuthor(ctx context.Context, title, author string) ([]*domain.Book, error) {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books
+		WHERE LOWER(TRIM(title)) = LOWER(TRIM($1)) AND LOWER(TRIM(author)) = LOWER(TRIM($2))
+		ORDER BY id`
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, title, author)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query books: %w", err)
+		return nil, fmt.Errorf("failed to find books by title and author: %w", err)
 	}
 	defer rows.Close()
 
@@ -124,9 +416,9 @@ func (r *bookRepository) GetAll(ctx context.Context, filter *domain.BookFilter)
 	for rows.Next() {
 		book := &domain.Book{}
 		err := rows.Scan(
-			&book.ID, &book.Title, &book.Author, &book.ISBN,
+			&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
 			&book.Publisher, &book.PublishYear, &book.Genre,
-			&book.Pages, &book.Available, &book.Description,
+			&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
 			&book.CreatedAt, &book.UpdatedAt,
 		)
 		if err != nil {
@@ -152,19 +444,22 @@ func (r *bookRepository) Update(ctx context.Context, book *domain.Book) (*domain
 		WHERE id = $1
 		RETURNING updated_at`
 
-	err := r.db.QueryRowContext(
-		ctx, query,
-		book.ID, book.Title, book.Author, book.ISBN,
-		book.Publisher, book.PublishYear, book.Genre,
-		book.Pages, book.Available, book.Description, book.UpdatedAt,
-	).Scan(&book.UpdatedAt)
+	err := r.withRetry(ctx, func() error {
+		return r.conn(ctx).QueryRowContext(
+			ctx, query,
+			book.ID, book.Title, book.Author, book.ISBN,
+			book.Publisher, book.PublishYear, book.Genre,
+			book.Pages, book.Available, book.Description, book.UpdatedAt,
+		).Scan(&book.UpdatedAt)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("book with ID %d not found", book.ID)
+			return nil, fmt.Errorf("book with ID %d %w", book.ID, domain.ErrBookNotFound)
 		}
 		return nil, fmt.Errorf("failed to update book: %w", err)
 	}
+	r.markWrite()
 
 	return book, nil
 }
@@ -173,7 +468,7 @@ func (r *bookRepository) Update(ctx context.Context, book *domain.Book) (*domain
 func (r *bookRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM books WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.conn(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete book: %w", err)
 	}
@@ -184,31 +479,58 @@ func (r *bookRepository) Delete(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("book with ID %d not found", id)
+		return fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
 	}
+	r.markWrite()
 
 	return nil
 }
 
+// GetByPublicID retrieves a book by its public ID
+func (r *bookRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre, 
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books 
+		WHERE public_id = $1`
+
+	book := &domain.Book{}
+	err := r.readConn(ctx).QueryRowContext(ctx, query, publicID).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+		&book.Publisher, &book.PublishYear, &book.Genre,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+		&book.CreatedAt, &book.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book with public ID %s %w", publicID, domain.ErrBookNotFound)
+		}
+		return nil, fmt.Errorf("failed to get book by public ID: %w", err)
+	}
+
+	return book, nil
+}
+
 // GetByISBN retrieves a book by its ISBN
 func (r *bookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
 	query := `
-		SELECT id, title, author, isbn, publisher, publish_year, genre, 
-		       pages, available, description, created_at, updated_at
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre, 
+		       pages, available, description, accession_number, created_at, updated_at
 		FROM books 
 		WHERE isbn = $1`
 
 	book := &domain.Book{}
-	err := r.db.QueryRowContext(ctx, query, isbn).Scan(
-		&book.ID, &book.Title, &book.Author, &book.ISBN,
+	err := r.readConn(ctx).QueryRowContext(ctx, query, isbn).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
 		&book.Publisher, &book.PublishYear, &book.Genre,
-		&book.Pages, &book.Available, &book.Description,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
 		&book.CreatedAt, &book.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("book with ISBN %s not found", isbn)
+			return nil, fmt.Errorf("book with ISBN %s %w", isbn, domain.ErrBookNotFound)
 		}
 		return nil, fmt.Errorf("failed to get book by ISBN: %w", err)
 	}
@@ -216,54 +538,766 @@ func (r *bookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Bo
 	return book, nil
 }
 
+// GetByAccessionNumber retrieves a book by its accession number
+func (r *bookRepository) GetByAccessionNumber(ctx context.Context, number string) (*domain.Book, error) {
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre, 
+		       pages, available, description, accession_number, created_at, updated_at
+		FROM books 
+		WHERE accession_number = $1`
+
+	book := &domain.Book{}
+	err := r.readConn(ctx).QueryRowContext(ctx, query, number).Scan(
+		&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+		&book.Publisher, &book.PublishYear, &book.Genre,
+		&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+		&book.CreatedAt, &book.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book with accession number %s %w", number, domain.ErrBookNotFound)
+		}
+		return nil, fmt.Errorf("failed to get book by accession number: %w", err)
+	}
+
+	return book, nil
+}
+
 // Count returns the total number of books with optional filtering
 func (r *bookRepository) Count(ctx context.Context, filter *domain.BookFilter) (int, error) {
 	query := "SELECT COUNT(*) FROM books"
 
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+	where, args := buildWhereClause(filter, 1)
+	query += where
+
+	var count int
+	err := r.readConn(ctx).QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	return count, nil
+}
+
+// EstimateCount returns the planner's estimate of the number of books matching the filter.
+// Unfiltered estimates come from pg_class.reltuples; filtered ones from EXPLAIN.
+func (r *bookRepository) EstimateCount(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	if filter.IsEmpty() {
+		var estimate float64
+		err := r.readConn(ctx).QueryRowContext(ctx,
+			"SELECT reltuples FROM pg_class WHERE oid = 'books'::regclass",
+		).Scan(&estimate)
+		if err != nil {
+			return 0, fmt.Errorf("failed to estimate books count: %w", err)
+		}
+		// reltuples is -1 (or 0) until the table has been vacuumed or analyzed
+		if estimate <= 0 {
+			return 0, fmt.Errorf("no row estimate available for books")
+		}
+		return int(estimate), nil
+	}
+
+	where, args := buildWhereClause(filter, 1)
+
+	var plan string
+	err := r.readConn(ctx).QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 FROM books"+where, args...).Scan(&plan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate books count: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil || len(explain) == 0 {
+		return 0, fmt.Errorf("failed to parse query plan: %v", err)
+	}
+
+	return int(explain[0].Plan.PlanRows), nil
+}
+
+// GetAuthors returns distinct authors with their book counts, paginated
+func (r *bookRepository) GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, error) {
+	query := `
+		SELECT author, COUNT(*) AS count
+		FROM books
+		GROUP BY author
+		ORDER BY author ASC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authors: %w", err)
+	}
+	defer rows.Close()
+
+	var authors []*domain.AuthorCount
+	for rows.Next() {
+		author := &domain.AuthorCount{}
+		if err := rows.Scan(&author.Author, &author.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan author: %w", err)
+		}
+		authors = append(authors, author)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return authors, nil
+}
 
-	if filter != nil {
-		if filter.Author != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(author) LIKE LOWER($%d)", argIndex))
-			args = append(args, "%"+filter.Author+"%")
-			argIndex++
+// CountAuthors returns the number of distinct authors
+func (r *bookRepository) CountAuthors(ctx context.Context) (int, error) {
+	var count int
+	err := r.readConn(ctx).QueryRowContext(ctx, "SELECT COUNT(DISTINCT author) FROM books").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count authors: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetGenres returns distinct genres with their book counts, paginated
+func (r *bookRepository) GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, error) {
+	query := `
+		SELECT genre, COUNT(*) AS count
+		FROM books
+		GROUP BY genre
+		ORDER BY genre ASC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query genres: %w", err)
+	}
+	defer rows.Close()
+
+	var genres []*domain.GenreCount
+	for rows.Next() {
+		genre := &domain.GenreCount{}
+		if err := rows.Scan(&genre.Genre, &genre.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan genre: %w", err)
 		}
+		genres = append(genres, genre)
+	}
 
-		if filter.Genre != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(genre) = LOWER($%d)", argIndex))
-			args = append(args, filter.Genre)
-			argIndex++
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return genres, nil
+}
+
+// CountGenres returns the number of distinct genres
+func (r *bookRepository) CountGenres(ctx context.Context) (int, error) {
+	var count int
+	err := r.readConn(ctx).QueryRowContext(ctx, "SELECT COUNT(DISTINCT genre) FROM books").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count genres: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPublishers returns distinct publishers starting with prefix, ignoring
+// case, with their book counts, paginated
+func (r *bookRepository) GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, error) {
+	query := `
+		SELECT publisher, COUNT(*) AS count
+		FROM books
+		WHERE LOWER(publisher) LIKE $1
+		GROUP BY publisher
+		ORDER BY publisher ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, prefixPattern(prefix), page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publishers: %w", err)
+	}
+	defer rows.Close()
+
+	var publishers []*domain.PublisherCount
+	for rows.Next() {
+		publisher := &domain.PublisherCount{}
+		if err := rows.Scan(&publisher.Publisher, &publisher.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan publisher: %w", err)
 		}
+		publishers = append(publishers, publisher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return publishers, nil
+}
+
+// CountPublishers returns the number of distinct publishers starting with prefix
+func (r *bookRepository) CountPublishers(ctx context.Context, prefix string) (int, error) {
+	var count int
+	err := r.readConn(ctx).QueryRowContext(ctx,
+		"SELECT COUNT(DISTINCT publisher) FROM books WHERE LOWER(publisher) LIKE $1",
+		prefixPattern(prefix)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count publishers: %w", err)
+	}
+
+	return count, nil
+}
 
-		if filter.Available != nil {
-			conditions = append(conditions, fmt.Sprintf("available = $%d", argIndex))
-			args = append(args, *filter.Available)
-			argIndex++
+// GetDistinctValues returns the distinct values of field among the books
+// matching the filter, with their book counts, paginated in value order
+func (r *bookRepository) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, error) {
+	// field is checked against domain.DistinctFields by the service, so it is
+	// safe to interpolate
+	query := fmt.Sprintf("SELECT CAST(%s AS TEXT), COUNT(*) FROM books", field)
+
+	where, args := buildWhereClause(filter, 1)
+	query += where
+	query += fmt.Sprintf(" GROUP BY %s ORDER BY %s ASC LIMIT $%d OFFSET $%d", field, field, len(args)+1, len(args)+2)
+	args = append(args, page.Limit, page.Offset)
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distinct values: %w", err)
+	}
+	defer rows.Close()
+
+	var values []*domain.DistinctValue
+	for rows.Next() {
+		value := &domain.DistinctValue{}
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan distinct value: %w", err)
 		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return values, nil
+}
+
+// CountDistinctValues returns the number of distinct values of field among
+// the books matching the filter
+func (r *bookRepository) CountDistinctValues(ctx context.Context, field string, filter *domain.BookFilter) (int, error) {
+	query := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM books", field)
+
+	where, args := buildWhereClause(filter, 1)
+	query += where
+
+	var count int
+	if err := r.readConn(ctx).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count distinct values: %w", err)
+	}
+
+	return count, nil
+}
+
+// likeEscaper escapes the LIKE wildcards, and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// prefixPattern returns a lower-cased LIKE pattern matching values that start
+// with prefix literally; an empty prefix matches everything
+func prefixPattern(prefix string) string {
+	return likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+}
+
+// attentionConditions are the data-quality checks behind GetNeedingAttention,
+// in the order their reasons are reported. $1 is AttentionMinPages.
+var attentionConditions = []struct {
+	reason string
+	sql    string
+}{
+	{domain.ReasonMissingDescription, "COALESCE(TRIM(description), '') = ''"},
+	{domain.ReasonLowPageCount, "pages < $1"},
+	{domain.ReasonFuturePublishYear, "publish_year > EXTRACT(YEAR FROM CURRENT_DATE)"},
+	{domain.ReasonMissingGenre, "TRIM(genre) = ''"},
+}
+
+// attentionWhere matches books failing any data-quality check
+func attentionWhere() string {
+	conditions := make([]string, len(attentionConditions))
+	for i, c := range attentionConditions {
+		conditions[i] = c.sql
+	}
+	return " WHERE " + strings.Join(conditions, " OR ")
+}
 
-		if filter.Search != "" {
-			searchCondition := fmt.Sprintf(`(
-				LOWER(title) LIKE LOWER($%d) OR 
-				LOWER(author) LIKE LOWER($%d) OR 
-				LOWER(description) LIKE LOWER($%d)
-			)`, argIndex, argIndex, argIndex)
-			conditions = append(conditions, searchCondition)
-			args = append(args, "%"+filter.Search+"%")
-			argIndex++
+// GetNeedingAttention returns books failing any data-quality check with the
+// reasons each was flagged. The checks run in SQL so only flagged rows are read.
+func (r *bookRepository) GetNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, error) {
+	flags := make([]string, len(attentionConditions))
+	for i, c := range attentionConditions {
+		flags[i] = "(" + c.sql + ")"
+	}
+	query := `
+		SELECT id, public_id, title, author, isbn, publisher, publish_year, genre,
+		       pages, available, COALESCE(description, ''), created_at, updated_at, ` +
+		strings.Join(flags, ", ") + `
+		FROM books` + attentionWhere() + `
+		ORDER BY id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, domain.AttentionMinPages, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query books needing attention: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*domain.BookAttention
+	for rows.Next() {
+		book := &domain.Book{}
+		matched := make([]bool, len(attentionConditions))
+		dest := []interface{}{
+			&book.ID, &book.PublicID, &book.Title, &book.Author, &book.ISBN,
+			&book.Publisher, &book.PublishYear, &book.Genre,
+			&book.Pages, &book.Available, &book.Description, &book.AccessionNumber,
+			&book.CreatedAt, &book.UpdatedAt,
+		}
+		for i := range matched {
+			dest = append(dest, &matched[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan book: %w", err)
 		}
 
-		if len(conditions) > 0 {
-			query += " WHERE " + strings.Join(conditions, " AND ")
+		result := &domain.BookAttention{Book: book, Reasons: []string{}}
+		for i, c := range attentionConditions {
+			if matched[i] {
+				result.Reasons = append(result.Reasons, c.reason)
+			}
 		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
+	return results, nil
+}
+
+// CountNeedingAttention returns the number of books failing any data-quality check
+func (r *bookRepository) CountNeedingAttention(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	err := r.readConn(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM books"+attentionWhere(), domain.AttentionMinPages).Scan(&count)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count books: %w", err)
+		return 0, fmt.Errorf("failed to count books needing attention: %w", err)
 	}
 
 	return count, nil
-}
\ No newline at end of file
+}
+
+// GetPageStats returns page statistics for the books matching the filter,
+// computed in one aggregate query
+func (r *bookRepository) GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(pages), 0),
+		       MIN(pages),
+		       MAX(pages),
+		       ROUND(AVG(pages), 2),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY pages)
+		FROM books`
+
+	where, args := buildWhereClause(filter, 1)
+	query += where
+
+	stats := &domain.PageStats{}
+	var min, max sql.NullInt64
+	var average, median sql.NullFloat64
+	err := r.readConn(ctx).QueryRowContext(ctx, query, args...).Scan(
+		&stats.Books, &stats.TotalPages, &min, &max, &average, &median,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page stats: %w", err)
+	}
+
+	// Aggregates over no rows are NULL
+	if stats.Books > 0 {
+		minPages, maxPages := int(min.Int64), int(max.Int64)
+		stats.MinPages, stats.MaxPages = &minPages, &maxPages
+		stats.Average, stats.Median = &average.Float64, &median.Float64
+	}
+
+	return stats, nil
+}
+
+// GetGrowth counts the books created in each bucket of the query with
+// date_trunc, in UTC. Buckets without books are not returned.
+func (r *bookRepository) GetGrowth(ctx context.Context, q *domain.GrowthQuery) ([]*domain.GrowthBucket, error) {
+	query := `
+		SELECT date_trunc($1::text, created_at AT TIME ZONE 'UTC') AS bucket, COUNT(*)
+		FROM books
+		WHERE created_at >= $2 AND created_at < $3
+		GROUP BY bucket
+		ORDER BY bucket ASC`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query, string(q.Interval), q.Start(), q.End())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query growth: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*domain.GrowthBucket
+	for rows.Next() {
+		bucket := &domain.GrowthBucket{}
+		if err := rows.Scan(&bucket.Start, &bucket.Books); err != nil {
+			return nil, fmt.Errorf("failed to scan growth bucket: %w", err)
+		}
+		// The bucket is a timestamp without time zone, scanned in a zero
+		// offset zone; rebuild it in UTC so equal buckets compare equal
+		bucket.Start = time.Date(bucket.Start.Year(), bucket.Start.Month(), bucket.Start.Day(), 0, 0, 0, 0, time.UTC)
+		buckets = append(buckets, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// GetGenreStats returns each genre with its total, available and checked-out counts
+func (r *bookRepository) GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error) {
+	query := `
+		SELECT genre,
+		       COUNT(*) AS total,
+		       COUNT(*) FILTER (WHERE available) AS available,
+		       COUNT(*) FILTER (WHERE NOT available) AS checked_out
+		FROM books
+		GROUP BY genre
+		ORDER BY genre ASC`
+
+	rows, err := r.readConn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query genre stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*domain.GenreStats
+	for rows.Next() {
+		stat := &domain.GenreStats{}
+		if err := rows.Scan(&stat.Genre, &stat.Total, &stat.Available, &stat.CheckedOut); err != nil {
+			return nil, fmt.Errorf("failed to scan genre stats: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return stats, nil
+}
+
+// BulkUpdate applies the changes to the books matching the filter, or to the
+// first filter.Limit of them in ID order when Limit is set, and returns the
+// number affected and the highest ID updated
+func (r *bookRepository) BulkUpdate(ctx context.Context, filter *domain.BookFilter, changes *domain.BulkBookChanges) (int, int, error) {
+	query, args := buildBulkUpdateQuery(filter, changes)
+	if query == "" {
+		return 0, 0, nil
+	}
+
+	var affected, lastID int
+	err := r.withRetry(ctx, func() error {
+		var err error
+		affected, lastID, err = r.bulkUpdate(ctx, query, args)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	r.markWrite()
+
+	return affected, lastID, nil
+}
+
+// ConvertISBNs replaces each book's ISBN in one transaction, joining the
+// request-scoped transaction when there is one
+func (r *bookRepository) ConvertISBNs(ctx context.Context, changes []domain.ISBNChange, keepOriginal bool) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE books
+		SET isbn = $2, original_isbn = CASE WHEN $3 THEN $4 ELSE original_isbn END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+
+	err := r.withRetry(ctx, func() error {
+		tx, ok := database.TxFromContext(ctx)
+		if !ok {
+			var err error
+			tx, err = r.db.BeginTx(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("failed to begin transaction: %w", err)
+			}
+			defer tx.Rollback()
+		}
+
+		for _, change := range changes {
+			if _, err := tx.ExecContext(ctx, query, change.ID, change.To, keepOriginal, change.From); err != nil {
+				return fmt.Errorf("failed to convert ISBN of book %d: %w", change.ID, err)
+			}
+		}
+
+		if !ok {
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("failed to commit ISBN conversion: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.markWrite()
+
+	return nil
+}
+
+// buildBulkUpdateQuery builds the UPDATE for a bulk update, returning the IDs
+// it changes. With filter.Limit set, only the first Limit matching books in ID
+// order are updated. It returns an empty query when there is nothing to change.
+func buildBulkUpdateQuery(filter *domain.BookFilter, changes *domain.BulkBookChanges) (string, []interface{}) {
+	var sets []string
+	var args []interface{}
+	argIndex := 1
+
+	if changes.Genre != nil {
+		sets = append(sets, fmt.Sprintf("genre = $%d", argIndex))
+		args = append(args, *changes.Genre)
+		argIndex++
+	}
+
+	if changes.Publisher != nil {
+		sets = append(sets, fmt.Sprintf("publisher = $%d", argIndex))
+		args = append(args, *changes.Publisher)
+		argIndex++
+	}
+
+	if changes.Available != nil {
+		sets = append(sets, fmt.Sprintf("available = $%d", argIndex))
+		args = append(args, *changes.Available)
+		argIndex++
+	}
+
+	if len(sets) == 0 {
+		return "", nil
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+
+	where, whereArgs := buildWhereClause(filter, argIndex)
+	args = append(args, whereArgs...)
+	argIndex += len(whereArgs)
+
+	query := "UPDATE books SET " + strings.Join(sets, ", ")
+	if filter != nil && filter.Limit > 0 {
+		query += fmt.Sprintf(" WHERE id IN (SELECT id FROM books%s ORDER BY id LIMIT $%d)", where, argIndex)
+		args = append(args, filter.Limit)
+	} else {
+		query += where
+	}
+
+	return query + " RETURNING id", args
+}
+
+// bulkUpdate runs a bulk UPDATE, joining the request-scoped transaction when
+// there is one and otherwise committing its own, and returns the number of
+// rows changed and the highest ID among them
+func (r *bookRepository) bulkUpdate(ctx context.Context, query string, args []interface{}) (int, int, error) {
+	tx, ok := database.TxFromContext(ctx)
+	if !ok {
+		var err error
+		tx, err = r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to bulk update books: %w", err)
+	}
+	defer rows.Close()
+
+	var affected, lastID int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan updated ID: %w", err)
+		}
+		affected++
+		if id > lastID {
+			lastID = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to bulk update books: %w", err)
+	}
+
+	if !ok {
+		if err := tx.Commit(); err != nil {
+			return 0, 0, fmt.Errorf("failed to commit bulk update: %w", err)
+		}
+	}
+
+	return affected, lastID, nil
+}
+
+// authorityTables maps each authority kind to its reference table
+var authorityTables = map[domain.AuthorityKind]string{
+	domain.AuthorityAuthor:    "authors",
+	domain.AuthorityPublisher: "publishers",
+}
+
+// authorityTable returns the reference table of kind
+func authorityTable(kind domain.AuthorityKind) (string, error) {
+	table, ok := authorityTables[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown authority kind %q", kind)
+	}
+	return table, nil
+}
+
+// AuthorityExists reports whether name is in the reference table of kind,
+// ignoring case. It reads from the primary, so a name added moments ago, or
+// earlier in the same transaction, is seen.
+func (r *bookRepository) AuthorityExists(ctx context.Context, kind domain.AuthorityKind, name string) (bool, error) {
+	table, err := authorityTable(kind)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE LOWER(name) = LOWER($1))", table)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", kind, err)
+	}
+
+	return exists, nil
+}
+
+// CreateAuthority adds the authority to the reference table of its kind and
+// reports whether it was added. A name already there, ignoring case, is left
+// alone and returned as stored.
+func (r *bookRepository) CreateAuthority(ctx context.Context, authority *domain.Authority) (*domain.Authority, bool, error) {
+	table, err := authorityTable(authority.Kind)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created := &domain.Authority{Kind: authority.Kind}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name) VALUES ($1)
+		ON CONFLICT DO NOTHING
+		RETURNING id, name, created_at`, table)
+	err = r.conn(ctx).QueryRowContext(ctx, query, authority.Name).Scan(&created.ID, &created.Name, &created.CreatedAt)
+	if err == nil {
+		r.markWrite()
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to create %s: %w", authority.Kind, err)
+	}
+
+	query = fmt.Sprintf("SELECT id, name, created_at FROM %s WHERE LOWER(name) = LOWER($1)", table)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, authority.Name).Scan(&created.ID, &created.Name, &created.CreatedAt); err != nil {
+		return nil, false, fmt.Errorf("failed to get existing %s: %w", authority.Kind, err)
+	}
+
+	return created, false, nil
+}
+
+// buildWhereClause builds the WHERE clause and arguments for a book filter.
+// Placeholders are numbered starting at argIndex so the clause can follow other arguments.
+func buildWhereClause(filter *domain.BookFilter, argIndex int) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []interface{}
+
+	if filter.Author != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(author) LIKE LOWER($%d)", argIndex))
+		args = append(args, "%"+filter.Author+"%")
+		argIndex++
+	}
+
+	if filter.Genre != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(genre) = LOWER($%d)", argIndex))
+		args = append(args, filter.Genre)
+		argIndex++
+	}
+
+	if filter.Publisher != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(publisher) LIKE LOWER($%d)", argIndex))
+		args = append(args, "%"+filter.Publisher+"%")
+		argIndex++
+	}
+
+	if filter.Available != nil {
+		conditions = append(conditions, fmt.Sprintf("available = $%d", argIndex))
+		args = append(args, *filter.Available)
+		argIndex++
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, fmt.Sprintf("id > $%d", argIndex))
+		args = append(args, filter.AfterID)
+		argIndex++
+	}
+
+	if filter.Search != "" {
+		searchCondition := fmt.Sprintf(`(
+			LOWER(title) LIKE LOWER($%d) OR 
+			LOWER(author) LIKE LOWER($%d) OR 
+			LOWER(description) LIKE LOWER($%d)
+		)`, argIndex, argIndex, argIndex)
+		conditions = append(conditions, searchCondition)
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// buildOrderBy builds the ORDER BY clause for a book filter. An explicit sort
+// always wins; otherwise searches are ordered by relevance and everything else
+// by newest first. Relevance ranking adds the search term as placeholder argIndex.
+func buildOrderBy(filter *domain.BookFilter, argIndex int) (string, []interface{}) {
+	if filter != nil && filter.Sort != "" {
+		direction := "ASC"
+		if filter.Order == "desc" {
+			direction = "DESC"
+		}
+		// Sort has been validated against domain.SortableFields
+		if filter.Sort == "id" {
+			return " ORDER BY id " + direction, nil
+		}
+		return fmt.Sprintf(" ORDER BY %s %s, id ASC", filter.Sort, direction), nil
+	}
+
+	if filter != nil && filter.Search != "" {
+		return fmt.Sprintf(` ORDER BY ts_rank(
+			to_tsvector('english', title || ' ' || author || ' ' || COALESCE(description, '')),
+			plainto_tsquery('english', $%d)
+		) DESC, created_at DESC`, argIndex), []interface{}{filter.Search}
+	}
+
+	return " ORDER BY created_at DESC", nil
+}
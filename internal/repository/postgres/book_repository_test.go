@@ -0,0 +1,484 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"library-management/internal/config"
+	"library-management/internal/database"
+	"library-management/internal/domain"
+	"library-management/internal/repository"
+	"library-management/internal/repository/repositorytest"
+)
+
+func TestBuildOrderBy(t *testing.T) {
+	t.Run("no search uses newest first", func(t *testing.T) {
+		orderBy, args := buildOrderBy(&domain.BookFilter{Genre: "Programming"}, 2)
+		if orderBy != " ORDER BY created_at DESC" {
+			t.Errorf("got %q, want created_at ordering", orderBy)
+		}
+		if len(args) != 0 {
+			t.Errorf("got %v, want no args", args)
+		}
+	})
+
+	t.Run("search without sort uses relevance", func(t *testing.T) {
+		orderBy, args := buildOrderBy(&domain.BookFilter{Search: "golang"}, 2)
+		if !strings.Contains(orderBy, "ts_rank(") || !strings.Contains(orderBy, "plainto_tsquery('english', $2)") {
+			t.Errorf("got %q, want relevance ordering on $2", orderBy)
+		}
+		if len(args) != 1 || args[0] != "golang" {
+			t.Errorf("got %v, want search term arg", args)
+		}
+	})
+
+	t.Run("explicit sort wins over search", func(t *testing.T) {
+		orderBy, args := buildOrderBy(&domain.BookFilter{Search: "golang", Sort: "title", Order: "desc"}, 2)
+		if orderBy != " ORDER BY title DESC, id ASC" {
+			t.Errorf("got %q, want title ordering", orderBy)
+		}
+		if len(args) != 0 {
+			t.Errorf("got %v, want no args", args)
+		}
+	})
+
+	t.Run("explicit sort defaults to ascending", func(t *testing.T) {
+		orderBy, _ := buildOrderBy(&domain.BookFilter{Sort: "publish_year"}, 1)
+		if orderBy != " ORDER BY publish_year ASC, id ASC" {
+			t.Errorf("got %q, want ascending publish_year ordering", orderBy)
+		}
+	})
+}
+
+func TestBuildCursorClauses(t *testing.T) {
+	filter := &domain.BookFilter{Genre: "Programming", Sort: "id", AfterID: 42}
+
+	where, args := buildWhereClause(filter, 1)
+	if where != " WHERE LOWER(genre) = LOWER($1) AND id > $2" {
+		t.Errorf("Unexpected where clause %q", where)
+	}
+	if len(args) != 2 || args[1] != 42 {
+		t.Errorf("Unexpected args %v", args)
+	}
+
+	if orderBy, _ := buildOrderBy(filter, 3); orderBy != " ORDER BY id ASC" {
+		t.Errorf("got %q, want plain id ordering", orderBy)
+	}
+}
+
+func TestBuildBulkUpdateQuery(t *testing.T) {
+	genre := "Programming"
+	changes := &domain.BulkBookChanges{Genre: &genre}
+
+	query, args := buildBulkUpdateQuery(&domain.BookFilter{Genre: "Prog", AfterID: 500, Limit: 250}, changes)
+	want := "UPDATE books SET genre = $1, updated_at = CURRENT_TIMESTAMP" +
+		" WHERE id IN (SELECT id FROM books WHERE LOWER(genre) = LOWER($2) AND id > $3 ORDER BY id LIMIT $4) RETURNING id"
+	if query != want {
+		t.Errorf("Unexpected batched query %q", query)
+	}
+	if len(args) != 4 || args[2] != 500 || args[3] != 250 {
+		t.Errorf("Unexpected args %v", args)
+	}
+
+	query, _ = buildBulkUpdateQuery(&domain.BookFilter{Genre: "Prog"}, changes)
+	if query != "UPDATE books SET genre = $1, updated_at = CURRENT_TIMESTAMP WHERE LOWER(genre) = LOWER($2) RETURNING id" {
+		t.Errorf("Unexpected unbatched query %q", query)
+	}
+
+	if query, _ := buildBulkUpdateQuery(&domain.BookFilter{Genre: "Prog"}, &domain.BulkBookChanges{}); query != "" {
+		t.Errorf("got %q, want no query without changes", query)
+	}
+}
+
+func TestBuildWhereClause(t *testing.T) {
+	available := true
+	where, args := buildWhereClause(&domain.BookFilter{Genre: "Programming", Available: &available}, 3)
+
+	if where != " WHERE LOWER(genre) = LOWER($3) AND available = $4" {
+		t.Errorf("Unexpected where clause %q", where)
+	}
+	if len(args) != 2 {
+		t.Errorf("got %v, want 2 args", args)
+	}
+
+	if where, args := buildWhereClause(&domain.BookFilter{}, 1); where != "" || args != nil {
+		t.Errorf("got %q %v, want empty clause for empty filter", where, args)
+	}
+
+	where, args = buildWhereClause(&domain.BookFilter{Author: "martin", Publisher: "O'Reilly"}, 1)
+	if where != " WHERE LOWER(author) LIKE LOWER($1) AND LOWER(publisher) LIKE LOWER($2)" {
+		t.Errorf("Unexpected publisher where clause %q", where)
+	}
+	if len(args) != 2 || args[1] != "%O'Reilly%" {
+		t.Errorf("got %v, want publisher pattern arg", args)
+	}
+}
+
+// countingDriver is a minimal database/sql driver that counts statements per pool
+type countingDriver struct {
+	mu      sync.Mutex
+	queries int
+	execs   int
+}
+
+func (d *countingDriver) Counts() (queries, execs int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries, d.execs
+}
+
+func (d *countingDriver) Connect(ctx context.Context) (driver.Conn, error) {
+	return &countingConn{d: d}, nil
+}
+func (d *countingDriver) Driver() driver.Driver                 { return d }
+func (d *countingDriver) Open(name string) (driver.Conn, error) { return &countingConn{d: d}, nil }
+
+type countingConn struct{ d *countingDriver }
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) { return &countingStmt{d: c.d}, nil }
+func (c *countingConn) Close() error                              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error)                 { return countingTx{}, nil }
+
+type countingTx struct{}
+
+func (countingTx) Commit() error   { return nil }
+func (countingTx) Rollback() error { return nil }
+
+type countingStmt struct{ d *countingDriver }
+
+func (s *countingStmt) Close() error  { return nil }
+func (s *countingStmt) NumInput() int { return -1 }
+func (s *countingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs++
+	return driver.RowsAffected(1), nil
+}
+func (s *countingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries++
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestReadReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+
+	newPools := func() (*sql.DB, *countingDriver, *sql.DB, *countingDriver) {
+		primary, replica := &countingDriver{}, &countingDriver{}
+		return sql.OpenDB(primary), primary, sql.OpenDB(replica), replica
+	}
+
+	// read runs each routed read method once
+	read := func(repo repository.BookRepository) {
+		repo.GetByID(ctx, 1)
+		repo.GetAll(ctx, &domain.BookFilter{})
+		repo.GetByISBN(ctx, "9780134190440")
+		repo.Count(ctx, &domain.BookFilter{})
+	}
+
+	t.Run("reads go to the replica and writes to the primary", func(t *testing.T) {
+		primaryDB, primary, replicaDB, replica := newPools()
+		repo := NewBookRepository(primaryDB, WithReadReplica(replicaDB))
+
+		read(repo)
+		if q, _ := replica.Counts(); q != 4 {
+			t.Errorf("got %d, want 4 replica queries", q)
+		}
+		if q, _ := primary.Counts(); q != 0 {
+			t.Errorf("got %d, want no primary queries", q)
+		}
+
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, e := primary.Counts(); e != 1 {
+			t.Errorf("got %d execs, want delete on primary", e)
+		}
+		if _, e := replica.Counts(); e != 0 {
+			t.Errorf("got %d, want no replica execs", e)
+		}
+	})
+
+	t.Run("no replica falls back to the primary", func(t *testing.T) {
+		primaryDB, primary, _, _ := newPools()
+		repo := NewBookRepository(primaryDB)
+
+		read(repo)
+		if q, _ := primary.Counts(); q != 4 {
+			t.Errorf("got %d, want 4 primary queries", q)
+		}
+	})
+
+	t.Run("reads stay on the primary within the lag window", func(t *testing.T) {
+		primaryDB, primary, replicaDB, replica := newPools()
+		repo := NewBookRepository(primaryDB, WithReadReplica(replicaDB), WithReplicaLagWindow(time.Minute))
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		repo.(*bookRepository).now = func() time.Time { return now }
+
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		repo.GetByID(ctx, 1)
+		if q, _ := primary.Counts(); q != 1 {
+			t.Errorf("got %d primary queries, want read-after-write on primary", q)
+		}
+
+		now = now.Add(2 * time.Minute)
+		repo.GetByID(ctx, 1)
+		if q, _ := replica.Counts(); q != 1 {
+			t.Errorf("got %d replica queries, want read on replica after the lag window", q)
+		}
+	})
+}
+
+// newTestRepository connects to TEST_DATABASE_URL and returns a repository
+// over a freshly initialized books table, skipping the test when it is unset
+func newTestRepository(t *testing.T) repository.BookRepository {
+	t.Helper()
+	return NewBookRepository(newTestDB(t))
+}
+
+// newTestDB connects to TEST_DATABASE_URL and initializes a fresh books
+// table, skipping the test when it is unset
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.Connect(databaseURL, 25, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("DROP TABLE IF EXISTS books, accession_counters, authors, publishers"); err != nil {
+		t.Fatalf("Failed to reset books table: %v", err)
+	}
+	cfg := &config.Config{
+		PublishYearMin:        domain.DefaultPublishYears().Min,
+		PublishYearMax:        domain.DefaultPublishYears().Max,
+		AccessionNumberDigits: 5,
+		DescriptionMaxLength:  domain.DefaultDescriptionMaxLength,
+	}
+	if err := database.InitializeDatabase(db, cfg); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	return db
+}
+
+func TestBookRepository_Upsert(t *testing.T) {
+	repositorytest.TestUpsert(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetByPublicID(t *testing.T) {
+	repositorytest.TestGetByPublicID(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetNeedingAttention(t *testing.T) {
+	repositorytest.TestGetNeedingAttention(t, newTestRepository(t))
+}
+
+func TestBookRepository_CaseInsensitiveFilters(t *testing.T) {
+	repositorytest.TestCaseInsensitiveFilters(t, newTestRepository(t))
+}
+
+func TestBookRepository_ExistingISBNs(t *testing.T) {
+	repositorytest.TestExistingISBNs(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetRandom(t *testing.T) {
+	repositorytest.TestGetRandom(t, newTestRepository(t))
+}
+
+func TestBookRepository_FindByTitleAuthor(t *testing.T) {
+	repositorytest.TestFindByTitleAuthor(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetPublishersPrefix(t *testing.T) {
+	repositorytest.TestGetPublishersPrefix(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetPageStats(t *testing.T) {
+	repositorytest.TestGetPageStats(t, newTestRepository(t))
+}
+
+func TestBookRepository_CreateAssignsAccessionNumber(t *testing.T) {
+	repositorytest.TestCreateAssignsAccessionNumber(t, newTestRepository(t))
+}
+
+func TestBookRepository_AccessionNumbers(t *testing.T) {
+	repositorytest.TestAccessionNumbers(t, newTestRepository(t))
+}
+
+func TestBookRepository_AccessionNumbersConcurrent(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	created := time.Date(2001, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	const count = 20
+	numbers := make(chan string, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			book, err := repo.Create(ctx, &domain.Book{
+				Title:       fmt.Sprintf("Concurrent Accession Book %d", i),
+				Author:      "Concurrent Author",
+				ISBN:        fmt.Sprintf("978-00000012%02d", i),
+				Publisher:   "Concurrent Publisher",
+				PublishYear: 2001,
+				Genre:       "Concurrent Genre",
+				Pages:       100,
+				CreatedAt:   created,
+				UpdatedAt:   created,
+			})
+			if err != nil {
+				t.Errorf("Failed to create book %d: %v", i, err)
+				return
+			}
+			numbers <- book.AccessionNumber
+		}(i)
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := make(map[string]bool)
+	for number := range numbers {
+		if seen[number] {
+			t.Errorf("Accession number %s was given out twice", number)
+		}
+		seen[number] = true
+	}
+	for i := 1; i <= count; i++ {
+		if want := fmt.Sprintf("2001-%05d", i); !seen[want] {
+			t.Errorf("want %s to be given out", want)
+		}
+	}
+}
+
+func TestBookRepository_GetGrowth(t *testing.T) {
+	repositorytest.TestGetGrowth(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetRelated(t *testing.T) {
+	repositorytest.TestGetRelated(t, newTestRepository(t))
+}
+
+func TestBookRepository_GetDistinctValues(t *testing.T) {
+	repositorytest.TestGetDistinctValues(t, newTestRepository(t))
+}
+
+func TestBookRepository_Authorities(t *testing.T) {
+	repositorytest.TestAuthorities(t, newTestRepository(t))
+}
+
+func TestBookRepository_DescriptionLengthConstraint(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	newBook := func(isbn, description string) *domain.Book {
+		return &domain.Book{
+			Title:       "Description Limit Book",
+			Author:      "Limit Author",
+			ISBN:        isbn,
+			Publisher:   "Limit Publisher",
+			PublishYear: 2020,
+			Genre:       "Limit Genre",
+			Pages:       100,
+			Description: description,
+		}
+	}
+
+	atLimit := strings.Repeat("é", domain.DefaultDescriptionMaxLength)
+	if _, err := repo.Create(ctx, newBook("978-0000001301", atLimit)); err != nil {
+		t.Fatalf("got %v, want a description at the limit to be stored", err)
+	}
+	if _, err := repo.Create(ctx, newBook("978-0000001302", atLimit+"é")); err == nil {
+		t.Error("want the CHECK constraint to reject a description over the limit")
+	}
+}
+
+func TestBookRepository_GetByIDForUpdate(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewBookRepository(db)
+	ctx := context.Background()
+
+	book, err := repo.Create(ctx, &domain.Book{
+		Title:       "Locked Book",
+		Author:      "Lock Author",
+		ISBN:        "978-0000001501",
+		Publisher:   "Lock Publisher",
+		PublishYear: 2020,
+		Genre:       "Lock Genre",
+		Pages:       100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+
+	first, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer first.Rollback()
+	firstCtx := database.ContextWithTx(ctx, first)
+	if _, err := repo.GetByIDForUpdate(firstCtx, book.ID); err != nil {
+		t.Fatalf("Failed to lock book: %v", err)
+	}
+
+	second, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer second.Rollback()
+	locked := make(chan *domain.Book, 1)
+	go func() {
+		got, err := repo.GetByIDForUpdate(database.ContextWithTx(ctx, second), book.ID)
+		if err != nil {
+			t.Errorf("Failed to lock book: %v", err)
+		}
+		locked <- got
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("want the second lock to wait for the first transaction")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	book.Title = "Locked Book Revised"
+	updated, err := repo.Update(firstCtx, book)
+	if err != nil {
+		t.Fatalf("Failed to update book: %v", err)
+	}
+	if err := first.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	got := <-locked
+	if got == nil || got.ETag() != updated.ETag() {
+		t.Errorf("got %+v, want the second lock to see the committed update", got)
+	}
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+
+	"library-management/internal/database"
+)
+
+// Default retry settings for transient database errors
+const (
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 50 * time.Millisecond
+)
+
+// WithRetry sets how many times an idempotent write is retried after a
+// transient error, and the initial backoff, which doubles on each attempt
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(r *bookRepository) {
+		r.maxRetries = maxRetries
+		r.retryBackoff = backoff
+	}
+}
+
+// withRetry runs fn, retrying transient failures with exponential backoff.
+// Only use it for writes that are safe to repeat. Inside a request-scoped
+// transaction it runs fn once, since a failed statement aborts the
+// transaction and retrying cannot succeed.
+func (r *bookRepository) withRetry(ctx context.Context, fn func() error) error {
+	if _, ok := database.TxFromContext(ctx); ok {
+		return fn()
+	}
+
+	backoff := r.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.maxRetries || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransient reports whether err is worth retrying: serialization failures,
+// deadlocks and dropped connections. Constraint violations and other
+// errors are permanent.
+func isTransient(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", "40P01": // serialization_failure, deadlock_detected
+			return true
+		}
+		return pqErr.Code.Class() == "08" // connection_exception
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"library-management/internal/database"
+)
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository(nil, WithRetry(3, time.Millisecond)).(*bookRepository)
+
+	// flaky fails with each of errs in turn, then succeeds
+	flaky := func(errs ...error) (func() error, *int) {
+		calls := 0
+		return func() error {
+			calls++
+			if calls <= len(errs) {
+				return fmt.Errorf("failed to update book: %w", errs[calls-1])
+			}
+			return nil
+		}, &calls
+	}
+
+	t.Run("transient errors are retried until success", func(t *testing.T) {
+		fn, calls := flaky(&pq.Error{Code: "40001"}, &pq.Error{Code: "08006"})
+		if err := repo.withRetry(ctx, fn); err != nil {
+			t.Fatalf("got %v, want success after retries", err)
+		}
+		if *calls != 3 {
+			t.Errorf("got %d, want 3 attempts", *calls)
+		}
+	})
+
+	t.Run("gives up after the retry limit", func(t *testing.T) {
+		deadlock := &pq.Error{Code: "40P01"}
+		fn, calls := flaky(deadlock, deadlock, deadlock, deadlock, deadlock)
+		if err := repo.withRetry(ctx, fn); err == nil {
+			t.Fatal("want error after exhausting retries")
+		}
+		if *calls != 4 {
+			t.Errorf("got %d, want 4 attempts", *calls)
+		}
+	})
+
+	t.Run("constraint violations are not retried", func(t *testing.T) {
+		fn, calls := flaky(&pq.Error{Code: "23505"})
+		if err := repo.withRetry(ctx, fn); err == nil {
+			t.Fatal("want unique violation to be returned")
+		}
+		if *calls != 1 {
+			t.Errorf("got %d, want 1 attempt", *calls)
+		}
+	})
+
+	t.Run("not retried inside a request transaction", func(t *testing.T) {
+		tx, err := sql.OpenDB(&countingDriver{}).BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		defer tx.Rollback()
+
+		fn, calls := flaky(&pq.Error{Code: "40001"})
+		if err := repo.withRetry(database.ContextWithTx(ctx, tx), fn); err == nil {
+			t.Fatal("want serialization failure to be returned")
+		}
+		if *calls != 1 {
+			t.Errorf("got %d, want 1 attempt", *calls)
+		}
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"check violation", &pq.Error{Code: "23514"}, false},
+		{"no rows", sql.ErrNoRows, false},
+		{"other error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.expected {
+				t.Errorf("got %v, want isTransient=%v", got, tt.expected)
+			}
+		})
+	}
+}
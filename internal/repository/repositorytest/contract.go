@@ -0,0 +1,839 @@
+// Package repositorytest provides contract tests that every
+// repository.BookRepository implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"library-management/internal/domain"
+	"library-management/internal/repository"
+)
+
+// TestUpsert checks that Upsert creates a book for a new ISBN and updates the
+// existing book, keeping its ID, for a known one. repo must not already
+// contain ISBN 978-0000000002.
+func TestUpsert(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	book := &domain.Book{
+		Title:       "Original Title",
+		Author:      "Original Author",
+		ISBN:        "978-0000000002",
+		Publisher:   "Original Publisher",
+		PublishYear: 2020,
+		Genre:       "Original Genre",
+		Pages:       100,
+		Available:   true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	inserted, created, err := repo.Upsert(ctx, book)
+	if err != nil {
+		t.Fatalf("unexpected error on insert: %v", err)
+	}
+	if !created {
+		t.Error("want created to be true for a new ISBN")
+	}
+	if inserted.ID == 0 {
+		t.Error("want inserted book to have an ID")
+	}
+
+	changed := *inserted
+	changed.Title = "Updated Title"
+	changed.Pages = 250
+	changed.ID = 0
+	changed.UpdatedAt = now.Add(time.Minute)
+
+	updated, created, err := repo.Upsert(ctx, &changed)
+	if err != nil {
+		t.Fatalf("unexpected error on update: %v", err)
+	}
+	if created {
+		t.Error("want created to be false for an existing ISBN")
+	}
+	if updated.ID != inserted.ID {
+		t.Errorf("got %d, want ID %d to be kept", updated.ID, inserted.ID)
+	}
+
+	stored, err := repo.GetByISBN(ctx, book.ISBN)
+	if err != nil {
+		t.Fatalf("Failed to get upserted book: %v", err)
+	}
+	if stored.Title != "Updated Title" || stored.Pages != 250 {
+		t.Errorf("got title %q pages %d, want updated fields to be stored", stored.Title, stored.Pages)
+	}
+}
+
+// TestGetRelated checks that GetRelated excludes the book itself and ranks
+// same-author books above same-genre-only ones. repo must not already
+// contain books by "Related Author" or in the "Related Genre" genre.
+func TestGetRelated(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	create := func(title, author, genre, isbn string) *domain.Book {
+		book, err := repo.Create(ctx, &domain.Book{
+			Title:       title,
+			Author:      author,
+			ISBN:        isbn,
+			Publisher:   "Related Publisher",
+			PublishYear: 2020,
+			Genre:       genre,
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %q: %v", title, err)
+		}
+		return book
+	}
+
+	book := create("Subject", "Related Author", "Related Genre", "978-0000000101")
+	genreOnly := create("Same Genre", "Other Author", "Related Genre", "978-0000000102")
+	authorOnly := create("Same Author", "Related Author", "Other Genre", "978-0000000103")
+	both := create("Same Both", "Related Author", "Related Genre", "978-0000000104")
+	create("Unrelated", "Other Author", "Other Genre", "978-0000000105")
+
+	related, err := repo.GetRelated(ctx, book, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []int
+	for _, b := range related {
+		got = append(got, b.ID)
+	}
+	want := []int{both.ID, authorOnly.ID, genreOnly.ID}
+	if len(got) != len(want) {
+		t.Fatalf("related IDs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("related IDs = %v, want %v", got, want)
+		}
+	}
+
+	limited, err := repo.GetRelated(ctx, book, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(limited) != 1 || limited[0].ID != both.ID {
+		t.Errorf("got %d books, want only the best match with limit 1", len(limited))
+	}
+
+	unrelated, err := repo.GetRelated(ctx, &domain.Book{ID: -1, Author: "Nobody", Genre: "Nothing"}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unrelated) != 0 {
+		t.Errorf("got %d, want no related books", len(unrelated))
+	}
+}
+
+// TestGetByPublicID checks that Create assigns a public ID and that the book
+// can be looked up by it
+func TestGetByPublicID(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	created, err := repo.Create(ctx, &domain.Book{
+		Title:       "Public Book",
+		Author:      "Public Author",
+		ISBN:        "978-0000000201",
+		Publisher:   "Public Publisher",
+		PublishYear: 2020,
+		Genre:       "Public Genre",
+		Pages:       100,
+		Available:   true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+	if !domain.IsPublicID(created.PublicID) {
+		t.Fatalf("got %q, want a well-formed public ID", created.PublicID)
+	}
+
+	found, err := repo.GetByPublicID(ctx, created.PublicID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.ID != created.ID {
+		t.Errorf("book = %d, want %d", found.ID, created.ID)
+	}
+
+	if _, err := repo.GetByPublicID(ctx, "00000000-0000-4000-8000-999999999999"); err == nil {
+		t.Error("want error for unknown public ID")
+	}
+}
+
+// TestGetNeedingAttention checks that GetNeedingAttention flags only books
+// with data-quality issues, with every matching reason, and paginates. repo
+// must otherwise contain only books without issues.
+func TestGetNeedingAttention(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	books := []struct {
+		title       string
+		description string
+		pages       int
+		year        int
+		genre       string
+		reasons     []string
+	}{
+		{"Clean Record", "Complete", 200, 2020, "Fiction", nil},
+		{"No Description", "  ", 200, 2020, "Fiction", []string{domain.ReasonMissingDescription}},
+		{"Pamphlet", "Short", 3, 2020, "Fiction", []string{domain.ReasonLowPageCount}},
+		{"From The Future", "Complete", 200, now.Year() + 1, "", []string{domain.ReasonFuturePublishYear, domain.ReasonMissingGenre}},
+	}
+	for i, b := range books {
+		_, err := repo.Create(ctx, &domain.Book{
+			Title:       b.title,
+			Author:      "Attention Author",
+			ISBN:        fmt.Sprintf("978-00000003%02d", i),
+			Publisher:   "Attention Publisher",
+			PublishYear: b.year,
+			Genre:       b.genre,
+			Pages:       b.pages,
+			Description: b.description,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %q: %v", b.title, err)
+		}
+	}
+
+	flagged, err := repo.GetNeedingAttention(ctx, &domain.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(flagged) != 3 {
+		t.Fatalf("got %d, want 3 flagged books", len(flagged))
+	}
+	for i, result := range flagged {
+		expected := books[i+1]
+		if result.Book.Title != expected.title {
+			t.Errorf("got %q, want %q at position %d", result.Book.Title, expected.title, i)
+		}
+		if strings.Join(result.Reasons, ",") != strings.Join(expected.reasons, ",") {
+			t.Errorf("got %v, want reasons %v for %q", result.Reasons, expected.reasons, expected.title)
+		}
+	}
+
+	count, err := repo.CountNeedingAttention(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+
+	page, err := repo.GetNeedingAttention(ctx, &domain.Pagination{Limit: 1, Offset: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Book.Title != "From The Future" {
+		t.Errorf("got %v, want the third flagged book", page)
+	}
+}
+
+// TestCaseInsensitiveFilters checks that the author, publisher, genre and
+// search filters ignore case, and that genre still matches exactly. repo must
+// not already contain ISBNs 978-00000004xx.
+func TestCaseInsensitiveFilters(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	books := []struct {
+		title     string
+		author    string
+		publisher string
+	}{
+		{"Quillfeather Chronicles", "Zora Quillfeather", "Brambleworth Press"},
+		{"Unrelated Volume", "Someone Else", "Other House"},
+	}
+	for i, b := range books {
+		_, err := repo.Create(ctx, &domain.Book{
+			Title:       b.title,
+			Author:      b.author,
+			ISBN:        fmt.Sprintf("978-00000004%02d", i),
+			Publisher:   b.publisher,
+			PublishYear: 2020,
+			Genre:       "Speculative Fiction",
+			Pages:       200,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create %q: %v", b.title, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.BookFilter
+		want   int
+	}{
+		{"author", domain.BookFilter{Author: "QUILLFEATHER"}, 1},
+		{"publisher", domain.BookFilter{Publisher: "brambleWORTH"}, 1},
+		{"genre", domain.BookFilter{Genre: "speculative FICTION"}, 2},
+		{"genre is not a substring match", domain.BookFilter{Genre: "speculative"}, 0},
+		{"search", domain.BookFilter{Search: "quillFEATHER chronicles"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			got, err := repo.GetAll(ctx, &filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d, want %d books", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// TestExistingISBNs checks that ExistingISBNs reports only the normalized ISBNs
+// that belong to a book, whatever formatting the stored ISBN uses. repo must
+// not already contain ISBNs 978-00000005xx.
+func TestExistingISBNs(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for i, isbn := range []string{"978-0000000500", "97800000005 0x"} {
+		_, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("ISBN Book %d", i),
+			Author:      "ISBN Author",
+			ISBN:        isbn,
+			Publisher:   "ISBN Publisher",
+			PublishYear: 2020,
+			Genre:       "Reference",
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book with ISBN %s: %v", isbn, err)
+		}
+	}
+
+	existing, err := repo.ExistingISBNs(ctx, []string{"9780000000500", "978000000050X", "9780000000599"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]bool{"9780000000500": true, "978000000050X": true}
+	if len(existing) != len(want) {
+		t.Fatalf("got %v, want %v", existing, want)
+	}
+	for isbn := range want {
+		if !existing[isbn] {
+			t.Errorf("got %v, want %s to exist", existing, isbn)
+		}
+	}
+}
+
+// TestGetRandom checks that GetRandom only returns books matching the filter
+// and returns nil when none match. repo must not already contain ISBNs
+// 978-00000006xx or books in the genres used here.
+func TestGetRandom(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	books := []struct {
+		genre     string
+		available bool
+	}{
+		{"Random Poetry", true},
+		{"Random Poetry", true},
+		{"Random Poetry", false},
+		{"Random Drama", true},
+	}
+	for i, b := range books {
+		_, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("Random Book %d", i),
+			Author:      "Random Author",
+			ISBN:        fmt.Sprintf("978-00000006%02d", i),
+			Publisher:   "Random Publisher",
+			PublishYear: 2020,
+			Genre:       b.genre,
+			Pages:       100,
+			Available:   b.available,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+
+	available := true
+	for i := 0; i < 20; i++ {
+		book, err := repo.GetRandom(ctx, &domain.BookFilter{Genre: "random poetry", Available: &available})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book == nil {
+			t.Fatal("want a book")
+		}
+		if book.Genre != "Random Poetry" || !book.Available {
+			t.Fatalf("got %q available=%v, want an available Random Poetry book", book.Genre, book.Available)
+		}
+	}
+
+	book, err := repo.GetRandom(ctx, &domain.BookFilter{Genre: "Random Epic"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book != nil {
+		t.Errorf("got %q, want nil for no match", book.Title)
+	}
+}
+
+// TestFindByTitleAuthor checks that FindByTitleAuthor matches title and author
+// ignoring case and surrounding whitespace, and requires both to match. repo
+// must not already contain ISBNs 978-00000007xx or the titles used here.
+func TestFindByTitleAuthor(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	books := []struct {
+		title, author string
+	}{
+		{"Duplicate Title", "Duplicate Author"},
+		{"duplicate title ", "DUPLICATE AUTHOR"},
+		{"Duplicate Title", "Other Author"},
+		{"Other Title", "Duplicate Author"},
+	}
+	var ids []int
+	for i, b := range books {
+		book, err := repo.Create(ctx, &domain.Book{
+			Title:       b.title,
+			Author:      b.author,
+			ISBN:        fmt.Sprintf("978-00000007%02d", i),
+			Publisher:   "Duplicate Publisher",
+			PublishYear: 2020,
+			Genre:       "Duplicate Genre",
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+		ids = append(ids, book.ID)
+	}
+
+	found, err := repo.FindByTitleAuthor(ctx, "Duplicate Title", "duplicate author")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != ids[0] || found[1].ID != ids[1] {
+		var got []int
+		for _, b := range found {
+			got = append(got, b.ID)
+		}
+		t.Errorf("IDs = %v, want %v", got, ids[:2])
+	}
+
+	found, err = repo.FindByTitleAuthor(ctx, "Missing Title", "Duplicate Author")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("got %d, want no matches", len(found))
+	}
+}
+
+// TestGetPublishersPrefix checks that the publisher aggregation filters by a
+// case-insensitive prefix, treating LIKE wildcards in it literally
+func TestGetPublishersPrefix(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	publishers := []string{"Zq_Prefix Books", "Zq_Prefix Books", "zq_prefix Media", "ZqXPrefix House"}
+	for i, publisher := range publishers {
+		if _, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("Prefix Book %d", i),
+			Author:      "Prefix Author",
+			ISBN:        fmt.Sprintf("978-00000008%02d", i),
+			Publisher:   publisher,
+			PublishYear: 2020,
+			Genre:       "Prefix Genre",
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}); err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+
+	counts, err := repo.GetPublishers(ctx, "ZQ_", &domain.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]int)
+	for _, c := range counts {
+		got[c.Publisher] = c.Count
+	}
+	if len(got) != 2 || got["Zq_Prefix Books"] != 2 || got["zq_prefix Media"] != 1 {
+		t.Errorf("got %v, want Zq_Prefix Books (2) and zq_prefix Media (1)", got)
+	}
+
+	total, err := repo.CountPublishers(ctx, "ZQ_")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("got %d, want 2 publishers", total)
+	}
+
+	if total, err := repo.CountPublishers(ctx, "zq%"); err != nil || total != 0 {
+		t.Errorf("got %d (%v), want no publishers for a literal %%", total, err)
+	}
+}
+
+// TestGetPageStats checks the page aggregates over a filtered set of books,
+// including the even-count median and an empty set. repo must not contain
+// books in the "Page Stats Genre" genre.
+func TestGetPageStats(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for i, pages := range []int{300, 100, 1000, 200} {
+		if _, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("Page Stats Book %d", i),
+			Author:      "Page Stats Author",
+			ISBN:        fmt.Sprintf("978-00000009%02d", i),
+			Publisher:   "Page Stats Publisher",
+			PublishYear: 2020,
+			Genre:       "Page Stats Genre",
+			Pages:       pages,
+			Available:   i != 2,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}); err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+
+	stats, err := repo.GetPageStats(ctx, &domain.BookFilter{Genre: "page stats genre"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Books != 4 || stats.TotalPages != 1600 {
+		t.Errorf("got %d with %d, want 4 books with 1600 pages", stats.Books, stats.TotalPages)
+	}
+	if stats.MinPages == nil || *stats.MinPages != 100 || stats.MaxPages == nil || *stats.MaxPages != 1000 {
+		t.Errorf("got %v and %v, want min 100 and max 1000", stats.MinPages, stats.MaxPages)
+	}
+	if stats.Average == nil || *stats.Average != 400 || stats.Median == nil || *stats.Median != 250 {
+		t.Errorf("got %v and %v, want average 400 and median 250", stats.Average, stats.Median)
+	}
+
+	available := true
+	stats, err = repo.GetPageStats(ctx, &domain.BookFilter{Genre: "Page Stats Genre", Available: &available})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Books != 3 || *stats.MaxPages != 300 || *stats.Median != 200 {
+		t.Errorf("got %+v, want 3 available books, max 300, median 200", stats)
+	}
+
+	stats, err = repo.GetPageStats(ctx, &domain.BookFilter{Genre: "No Such Page Stats Genre"})
+	if err != nil {
+		t.Fatalf("unexpected error for an empty set: %v", err)
+	}
+	if stats.Books != 0 || stats.TotalPages != 0 || stats.MinPages != nil || stats.MaxPages != nil || stats.Average != nil || stats.Median != nil {
+		t.Errorf("got %+v, want empty stats", stats)
+	}
+}
+
+// TestGetGrowth checks that books are counted in the bucket holding their
+// creation time, that books outside the range are left out and that empty
+// buckets are not returned. repo must not contain books created in 2001.
+func TestGetGrowth(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	created := []time.Time{
+		time.Date(2000, 12, 31, 23, 59, 0, 0, time.UTC), // before the range
+		time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2001, 1, 20, 12, 0, 0, 0, time.UTC),
+		time.Date(2001, 3, 31, 23, 59, 0, 0, time.UTC),
+		time.Date(2001, 4, 1, 0, 0, 0, 0, time.UTC), // after the range
+	}
+	for i, at := range created {
+		if _, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("Growth Book %d", i),
+			Author:      "Growth Author",
+			ISBN:        fmt.Sprintf("978-00000010%02d", i),
+			Publisher:   "Growth Publisher",
+			PublishYear: 2000,
+			Genre:       "Growth Genre",
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   at,
+			UpdatedAt:   at,
+		}); err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+
+	buckets, err := repo.GetGrowth(ctx, &domain.GrowthQuery{
+		Interval: domain.GrowthIntervalMonth,
+		From:     time.Date(2001, 1, 15, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2001, 3, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("got %d, want 2 non-empty buckets", len(buckets))
+	}
+	january, march := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2001, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !buckets[0].Start.Equal(january) || buckets[0].Books != 2 {
+		t.Errorf("got %d from %s, want 2 books in January 2001", buckets[0].Books, buckets[0].Start)
+	}
+	if !buckets[1].Start.Equal(march) || buckets[1].Books != 1 {
+		t.Errorf("got %d from %s, want 1 book in March 2001", buckets[1].Books, buckets[1].Start)
+	}
+
+	buckets, err = repo.GetGrowth(ctx, &domain.GrowthQuery{
+		Interval: domain.GrowthIntervalWeek,
+		From:     time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2001, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 2001-01-01 was a Monday; the 20th falls in the week of the 15th
+	if len(buckets) != 2 || !buckets[1].Start.Equal(time.Date(2001, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v, want buckets for the weeks of the 1st and 15th", buckets)
+	}
+}
+
+// TestCreateAssignsAccessionNumber checks that Create returns the number
+// assigned to a book created without one, in YYYY-NNNNN form for the year of
+// creation. repo must not already contain ISBN 978-0000001601.
+func TestCreateAssignsAccessionNumber(t *testing.T, repo repository.BookRepository) {
+	now := time.Now().UTC()
+	created, err := repo.Create(context.Background(), &domain.Book{
+		Title:       "Accession Format Book",
+		Author:      "Accession Author",
+		ISBN:        "978-0000001601",
+		Publisher:   "Accession Publisher",
+		PublishYear: 2001,
+		Genre:       "Accession Genre",
+		Pages:       100,
+		Available:   true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := regexp.MustCompile(fmt.Sprintf(`^%d-\d{5,}$`, now.Year()))
+	if !want.MatchString(created.AccessionNumber) {
+		t.Errorf("AccessionNumber = %q, want %s", created.AccessionNumber, want)
+	}
+}
+
+// TestAccessionNumbers checks that created books are numbered per year of
+// creation with a five-digit counter, that an upsert of a known ISBN keeps
+// the book's number without using one and that books can be found by their
+// number. repo must not contain books created in 1998 or 1999.
+func TestAccessionNumbers(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	newBook := func(i int, at time.Time) *domain.Book {
+		return &domain.Book{
+			Title:       fmt.Sprintf("Accession Book %d", i),
+			Author:      "Accession Author",
+			ISBN:        fmt.Sprintf("978-00000011%02d", i),
+			Publisher:   "Accession Publisher",
+			PublishYear: 1998,
+			Genre:       "Accession Genre",
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   at,
+			UpdatedAt:   at,
+		}
+	}
+	create := func(i int, at time.Time) *domain.Book {
+		t.Helper()
+		created, err := repo.Create(ctx, newBook(i, at))
+		if err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+		return created
+	}
+
+	first := create(0, time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC))
+	other := create(1, time.Date(1998, 12, 31, 12, 0, 0, 0, time.UTC))
+	second := create(2, time.Date(1999, 7, 1, 0, 0, 0, 0, time.UTC))
+	if first.AccessionNumber != "1999-00001" || second.AccessionNumber != "1999-00002" {
+		t.Errorf("got %q and %q, want 1999-00001 and 1999-00002", first.AccessionNumber, second.AccessionNumber)
+	}
+	if other.AccessionNumber != "1998-00001" {
+		t.Errorf("got %q, want 1998-00001", other.AccessionNumber)
+	}
+
+	updated := newBook(0, time.Now().UTC())
+	updated.Title = "Accession Book 0, Revised"
+	upserted, created, err := repo.Upsert(ctx, updated)
+	if err != nil || created {
+		t.Fatalf("got created %v, error %v, want the upsert to update book 0", created, err)
+	}
+	if upserted.AccessionNumber != first.AccessionNumber {
+		t.Errorf("got %q, want the upserted book to keep %q", upserted.AccessionNumber, first.AccessionNumber)
+	}
+	if third := create(3, time.Date(1999, 9, 1, 0, 0, 0, 0, time.UTC)); third.AccessionNumber != "1999-00003" {
+		t.Errorf("got %q next, want the upsert to use no number", third.AccessionNumber)
+	}
+
+	found, err := repo.GetByAccessionNumber(ctx, "1998-00001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.ID != other.ID || found.AccessionNumber != "1998-00001" {
+		t.Errorf("got %d with %q, want book %d", found.ID, found.AccessionNumber, other.ID)
+	}
+	if _, err := repo.GetByAccessionNumber(ctx, "1999-99999"); err == nil {
+		t.Error("want error for an unknown accession number")
+	}
+}
+
+// TestGetDistinctValues checks the distinct values and counts of each
+// distinct field, scoped by a filter and paginated. repo must not contain
+// books in the "Distinct Genre" or "Distinct Other" genres.
+func TestGetDistinctValues(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	books := []struct {
+		author, publisher, genre string
+		year                     int
+	}{
+		{"Distinct Author B", "Distinct Press", "Distinct Genre", 2001},
+		{"Distinct Author A", "Distinct Press", "Distinct Genre", 1999},
+		{"Distinct Author A", "Distinct House", "Distinct Genre", 2001},
+		{"Distinct Author C", "Distinct House", "Distinct Other", 1998},
+	}
+	for i, b := range books {
+		if _, err := repo.Create(ctx, &domain.Book{
+			Title:       fmt.Sprintf("Distinct Book %d", i),
+			Author:      b.author,
+			ISBN:        fmt.Sprintf("978-00000014%02d", i),
+			Publisher:   b.publisher,
+			PublishYear: b.year,
+			Genre:       b.genre,
+			Pages:       100,
+			Available:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}); err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+
+	scope := &domain.BookFilter{Genre: "distinct genre"}
+	tests := []struct {
+		field string
+		want  []domain.DistinctValue
+	}{
+		{"author", []domain.DistinctValue{{Value: "Distinct Author A", Count: 2}, {Value: "Distinct Author B", Count: 1}}},
+		{"publisher", []domain.DistinctValue{{Value: "Distinct House", Count: 1}, {Value: "Distinct Press", Count: 2}}},
+		{"publish_year", []domain.DistinctValue{{Value: "1999", Count: 1}, {Value: "2001", Count: 2}}},
+		{"genre", []domain.DistinctValue{{Value: "Distinct Genre", Count: 3}}},
+	}
+	for _, tt := range tests {
+		values, err := repo.GetDistinctValues(ctx, tt.field, scope, &domain.Pagination{Limit: 10})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field, err)
+		}
+		if len(values) != len(tt.want) {
+			t.Fatalf("%s: got %d, want %d values", tt.field, len(values), len(tt.want))
+		}
+		for i, want := range tt.want {
+			if *values[i] != want {
+				t.Errorf("%s: got %+v, want value %d to be %+v", tt.field, *values[i], i, want)
+			}
+		}
+
+		total, err := repo.CountDistinctValues(ctx, tt.field, scope)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field, err)
+		}
+		if total != len(tt.want) {
+			t.Errorf("%s: got %d, want %d distinct values", tt.field, total, len(tt.want))
+		}
+	}
+
+	// Pages follow the value order
+	values, err := repo.GetDistinctValues(ctx, "author", scope, &domain.Pagination{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 1 || values[0].Value != "Distinct Author B" {
+		t.Errorf("got %+v, want the second page to hold Distinct Author B", values)
+	}
+}
+
+// TestAuthorities checks that authorities are looked up and deduplicated
+// ignoring case, separately for each kind. repo must not know the
+// "Contract Authority" names.
+func TestAuthorities(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	if exists, err := repo.AuthorityExists(ctx, domain.AuthorityAuthor, "Contract Authority"); err != nil || exists {
+		t.Fatalf("got %v (%v), want an unknown author", exists, err)
+	}
+
+	created, added, err := repo.CreateAuthority(ctx, &domain.Authority{Kind: domain.AuthorityAuthor, Name: "Contract Authority"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !added || created.ID == 0 || created.Name != "Contract Authority" || created.Kind != domain.AuthorityAuthor {
+		t.Errorf("got %+v (added %v), want a new author", created, added)
+	}
+
+	if exists, err := repo.AuthorityExists(ctx, domain.AuthorityAuthor, "contract AUTHORITY"); err != nil || !exists {
+		t.Errorf("got %v (%v), want the author to be found ignoring case", exists, err)
+	}
+
+	// A name differing only in case is the same authority
+	again, added, err := repo.CreateAuthority(ctx, &domain.Authority{Kind: domain.AuthorityAuthor, Name: "CONTRACT authority"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added || again.ID != created.ID || again.Name != "Contract Authority" {
+		t.Errorf("got %+v (added %v), want the existing author %+v", again, added, created)
+	}
+
+	// Kinds have separate tables
+	if exists, err := repo.AuthorityExists(ctx, domain.AuthorityPublisher, "Contract Authority"); err != nil || exists {
+		t.Errorf("got %v (%v), want no publisher of that name", exists, err)
+	}
+	if _, added, err := repo.CreateAuthority(ctx, &domain.Authority{Kind: domain.AuthorityPublisher, Name: "Contract Authority"}); err != nil || !added {
+		t.Errorf("got added %v (%v), want a new publisher", added, err)
+	}
+}
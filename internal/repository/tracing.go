@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"library-management/internal/domain"
+)
+
+// TracerName identifies spans created by the repository layer
+const TracerName = "library-management/repository"
+
+// tracingRepository wraps a BookRepository with a client span per call,
+// recording the operation and the number of rows returned or affected.
+// Query arguments are never recorded.
+type tracingRepository struct {
+	next   BookRepository
+	tracer trace.Tracer
+}
+
+// NewTracingRepository wraps next so every call is traced with tp
+func NewTracingRepository(next BookRepository, tp trace.TracerProvider) BookRepository {
+	return &tracingRepository{next: next, tracer: tp.Tracer(TracerName)}
+}
+
+func (t *tracingRepository) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "BookRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+}
+
+// finish records the row count or error and ends the span
+func finish(span trace.Span, rows int, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int("db.rows", rows))
+	}
+	span.End()
+}
+
+func (t *tracingRepository) Create(ctx context.Context, book *domain.Book) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "Create")
+	result, err := t.next.Create(ctx, book)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetByID(ctx context.Context, id int) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetByID")
+	result, err := t.next.GetByID(ctx, id)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetByIDForUpdate")
+	result, err := t.next.GetByIDForUpdate(ctx, id)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetByPublicID")
+	result, err := t.next.GetByPublicID(ctx, publicID)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetAll(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetAll")
+	result, err := t.next.GetAll(ctx, filter)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "Update")
+	result, err := t.next.Update(ctx, book)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetByISBN")
+	result, err := t.next.GetByISBN(ctx, isbn)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetByAccessionNumber(ctx context.Context, number string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetByAccessionNumber")
+	result, err := t.next.GetByAccessionNumber(ctx, number)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) ExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error) {
+	ctx, span := t.start(ctx, "ExistingISBNs")
+	result, err := t.next.ExistingISBNs(ctx, isbns)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) GetRandom(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetRandom")
+	result, err := t.next.GetRandom(ctx, filter)
+	rows := 0
+	if result != nil {
+		rows = 1
+	}
+	finish(span, rows, err)
+	return result, err
+}
+
+func (t *tracingRepository) Count(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	ctx, span := t.start(ctx, "Count")
+	result, err := t.next.Count(ctx, filter)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, error) {
+	ctx, span := t.start(ctx, "GetAuthors")
+	result, err := t.next.GetAuthors(ctx, page)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) CountAuthors(ctx context.Context) (int, error) {
+	ctx, span := t.start(ctx, "CountAuthors")
+	result, err := t.next.CountAuthors(ctx)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, error) {
+	ctx, span := t.start(ctx, "GetGenres")
+	result, err := t.next.GetGenres(ctx, page)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) CountGenres(ctx context.Context) (int, error) {
+	ctx, span := t.start(ctx, "CountGenres")
+	result, err := t.next.CountGenres(ctx)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, error) {
+	ctx, span := t.start(ctx, "GetPublishers")
+	result, err := t.next.GetPublishers(ctx, prefix, page)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) CountPublishers(ctx context.Context, prefix string) (int, error) {
+	ctx, span := t.start(ctx, "CountPublishers")
+	result, err := t.next.CountPublishers(ctx, prefix)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, error) {
+	ctx, span := t.start(ctx, "GetDistinctValues")
+	result, err := t.next.GetDistinctValues(ctx, field, filter, page)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) CountDistinctValues(ctx context.Context, field string, filter *domain.BookFilter) (int, error) {
+	ctx, span := t.start(ctx, "CountDistinctValues")
+	result, err := t.next.CountDistinctValues(ctx, field, filter)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) BulkUpdate(ctx context.Context, filter *domain.BookFilter, changes *domain.BulkBookChanges) (int, int, error) {
+	ctx, span := t.start(ctx, "BulkUpdate")
+	result, lastID, err := t.next.BulkUpdate(ctx, filter, changes)
+	finish(span, result, err)
+	return result, lastID, err
+}
+
+func (t *tracingRepository) ConvertISBNs(ctx context.Context, changes []domain.ISBNChange, keepOriginal bool) error {
+	ctx, span := t.start(ctx, "ConvertISBNs")
+	err := t.next.ConvertISBNs(ctx, changes, keepOriginal)
+	finish(span, len(changes), err)
+	return err
+}
+
+func (t *tracingRepository) GetNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, error) {
+	ctx, span := t.start(ctx, "GetNeedingAttention")
+	result, err := t.next.GetNeedingAttention(ctx, page)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) CountNeedingAttention(ctx context.Context) (int, error) {
+	ctx, span := t.start(ctx, "CountNeedingAttention")
+	result, err := t.next.CountNeedingAttention(ctx)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error) {
+	ctx, span := t.start(ctx, "GetPageStats")
+	result, err := t.next.GetPageStats(ctx, filter)
+	n := 0
+	if result != nil {
+		n = result.Books
+	}
+	finish(span, n, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetGrowth(ctx context.Context, query *domain.GrowthQuery) ([]*domain.GrowthBucket, error) {
+	ctx, span := t.start(ctx, "GetGrowth")
+	result, err := t.next.GetGrowth(ctx, query)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error) {
+	ctx, span := t.start(ctx, "GetGenreStats")
+	result, err := t.next.GetGenreStats(ctx)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) EstimateCount(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	ctx, span := t.start(ctx, "EstimateCount")
+	result, err := t.next.EstimateCount(ctx, filter)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) GetRelated(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetRelated")
+	result, err := t.next.GetRelated(ctx, book, limit)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) FindByTitleAuthor(ctx context.Context, title, author string) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "FindByTitleAuthor")
+	result, err := t.next.FindByTitleAuthor(ctx, title, author)
+	finish(span, len(result), err)
+	return result, err
+}
+
+func (t *tracingRepository) ForEach(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	ctx, span := t.start(ctx, "ForEach")
+	rows := 0
+	err := t.next.ForEach(ctx, filter, func(book *domain.Book) error {
+		rows++
+		return fn(book)
+	})
+	finish(span, rows, err)
+	return err
+}
+
+func (t *tracingRepository) Delete(ctx context.Context, id int) error {
+	ctx, span := t.start(ctx, "Delete")
+	err := t.next.Delete(ctx, id)
+	finish(span, 1, err)
+	return err
+}
+
+func (t *tracingRepository) Upsert(ctx context.Context, book *domain.Book) (*domain.Book, bool, error) {
+	ctx, span := t.start(ctx, "Upsert")
+	result, created, err := t.next.Upsert(ctx, book)
+	finish(span, 1, err)
+	return result, created, err
+}
+
+func (t *tracingRepository) AuthorityExists(ctx context.Context, kind domain.AuthorityKind, name string) (bool, error) {
+	ctx, span := t.start(ctx, "AuthorityExists")
+	result, err := t.next.AuthorityExists(ctx, kind, name)
+	finish(span, 1, err)
+	return result, err
+}
+
+func (t *tracingRepository) CreateAuthority(ctx context.Context, authority *domain.Authority) (*domain.Authority, bool, error) {
+	ctx, span := t.start(ctx, "CreateAuthority")
+	result, created, err := t.next.CreateAuthority(ctx, authority)
+	finish(span, 1, err)
+	return result, created, err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"library-management/internal/domain"
+)
+
+// CreateAuthority adds a known author or publisher. A name already known,
+// ignoring case, is returned as stored with created false.
+func (s *bookService) CreateAuthority(ctx context.Context, kind domain.AuthorityKind, req *domain.CreateAuthorityRequest) (*domain.Authority, bool, error) {
+	if s.normalizeUnicode {
+		req = &domain.CreateAuthorityRequest{Name: domain.ToNFC(req.Name)}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	authority, created, err := s.repo.CreateAuthority(ctx, &domain.Authority{Kind: kind, Name: domain.NormalizeSpace(req.Name)})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create %s: %w", kind, err)
+	}
+
+	return authority, created, nil
+}
+
+// checkAuthority applies the authority mode to the name of kind on a write.
+// Strict mode rejects a name missing from its reference table with
+// domain.ErrUnknownAuthority; lenient mode adds it.
+func (s *bookService) checkAuthority(ctx context.Context, kind domain.AuthorityKind, name string) error {
+	if s.authorityMode != domain.AuthorityModeStrict && s.authorityMode != domain.AuthorityModeLenient {
+		return nil
+	}
+
+	exists, err := s.repo.AuthorityExists(ctx, kind, name)
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", kind, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if s.authorityMode == domain.AuthorityModeStrict {
+		return fmt.Errorf("%s %q %w", kind, name, domain.ErrUnknownAuthority)
+	}
+	if _, _, err := s.repo.CreateAuthority(ctx, &domain.Authority{Kind: kind, Name: name}); err != nil {
+		return fmt.Errorf("failed to add %s: %w", kind, err)
+	}
+	return nil
+}
+
+// authorityFieldErrors reports the book's author and publisher that strict
+// mode would reject, so validation agrees with CreateBook. Lenient mode
+// accepts every name and adds nothing here; a failed lookup is not reported.
+func (s *bookService) authorityFieldErrors(ctx context.Context, book *domain.Book) []domain.FieldError {
+	if s.authorityMode != domain.AuthorityModeStrict {
+		return nil
+	}
+
+	var errs []domain.FieldError
+	for _, check := range []struct {
+		field string
+		kind  domain.AuthorityKind
+		name  string
+	}{
+		{"author", domain.AuthorityAuthor, book.Author},
+		{"publisher", domain.AuthorityPublisher, book.Publisher},
+	} {
+		if check.name == "" {
+			continue
+		}
+		if exists, err := s.repo.AuthorityExists(ctx, check.kind, check.name); err == nil && !exists {
+			errs = append(errs, domain.FieldError{
+				Field:   check.field,
+				Message: fmt.Sprintf("%s %q %s", check.kind, check.name, domain.ErrUnknownAuthority),
+			})
+		}
+	}
+	return errs
+}
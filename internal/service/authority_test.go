@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"library-management/internal/domain"
+)
+
+// authorityRequest returns a valid create request with the given author and publisher
+func authorityRequest(isbn, author, publisher string) *domain.CreateBookRequest {
+	return &domain.CreateBookRequest{
+		Title:       "Authority Book",
+		Author:      author,
+		ISBN:        isbn,
+		Publisher:   publisher,
+		PublishYear: 2020,
+		Genre:       "Reference",
+		Pages:       100,
+	}
+}
+
+func TestBookService_StrictAuthoritiesReject(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithAuthorityMode(domain.AuthorityModeStrict))
+	ctx := context.Background()
+
+	if _, _, err := service.CreateAuthority(ctx, domain.AuthorityAuthor, &domain.CreateAuthorityRequest{Name: " Known  Author "}); err != nil {
+		t.Fatalf("Failed to add author: %v", err)
+	}
+	if _, _, err := service.CreateAuthority(ctx, domain.AuthorityPublisher, &domain.CreateAuthorityRequest{Name: "Known Press"}); err != nil {
+		t.Fatalf("Failed to add publisher: %v", err)
+	}
+
+	// Known names pass, ignoring case
+	book, err := service.CreateBook(ctx, authorityRequest("978-1000000001", "known author", "KNOWN PRESS"))
+	if err != nil {
+		t.Fatalf("got %v, want known authorities to be accepted", err)
+	}
+
+	tests := []struct {
+		name      string
+		author    string
+		publisher string
+	}{
+		{"unknown author", "Unknown Author", "Known Press"},
+		{"unknown publisher", "Known Author", "Unknown Press"},
+	}
+	for _, tt := range tests {
+		_, err := service.CreateBook(ctx, authorityRequest("978-1000000002", tt.author, tt.publisher))
+		if !errors.Is(err, domain.ErrUnknownAuthority) {
+			t.Errorf("%s: got %v, want ErrUnknownAuthority", tt.name, err)
+		}
+	}
+	if len(repo.books) != 1 {
+		t.Errorf("got %d books, want rejected books not to be created", len(repo.books))
+	}
+
+	unknown := "Unknown Author"
+	if _, err := service.UpdateBook(ctx, book.ID, &domain.UpdateBookRequest{Author: &unknown}); !errors.Is(err, domain.ErrUnknownAuthority) {
+		t.Errorf("got %v, want the update to be rejected", err)
+	}
+	publisher := "Unknown Press"
+	if _, err := service.BulkUpdateBooks(ctx, &domain.BulkUpdateRequest{Filter: domain.BookFilter{Genre: "Reference"}, Changes: domain.BulkBookChanges{Publisher: &publisher}}); !errors.Is(err, domain.ErrUnknownAuthority) {
+		t.Errorf("got %v, want the bulk update to be rejected", err)
+	}
+
+	// Books whose names predate the checks can still be edited
+	repo.books[book.ID].Author = "Legacy Author"
+	pages := 200
+	if _, err := service.UpdateBook(ctx, book.ID, &domain.UpdateBookRequest{Pages: &pages}); err != nil {
+		t.Errorf("got %v, want an update leaving the author alone to pass", err)
+	}
+
+	if exists, _ := repo.AuthorityExists(ctx, domain.AuthorityAuthor, "Unknown Author"); exists {
+		t.Error("want strict mode not to add authorities")
+	}
+}
+
+func TestBookService_LenientAuthoritiesAutoCreate(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithAuthorityMode(domain.AuthorityModeLenient))
+	ctx := context.Background()
+
+	book, err := service.CreateBook(ctx, authorityRequest("978-1000000003", "New  Author", "New Press"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for kind, name := range map[domain.AuthorityKind]string{domain.AuthorityAuthor: "New Author", domain.AuthorityPublisher: "New Press"} {
+		if exists, _ := repo.AuthorityExists(ctx, kind, name); !exists {
+			t.Errorf("want %s %q to be added", kind, name)
+		}
+	}
+
+	// A name already known, ignoring case, is not added twice
+	if _, err := service.CreateBook(ctx, authorityRequest("978-1000000004", "new author", "New Press")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.authorities[domain.AuthorityAuthor]) != 1 {
+		t.Errorf("got %d, want one author", len(repo.authorities[domain.AuthorityAuthor]))
+	}
+
+	author := "Second Author"
+	if _, err := service.UpdateBook(ctx, book.ID, &domain.UpdateBookRequest{Author: &author}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists, _ := repo.AuthorityExists(ctx, domain.AuthorityAuthor, author); !exists {
+		t.Errorf("want the updated author to be added")
+	}
+}
+
+func TestBookService_AuthoritiesOffByDefault(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+
+	if _, err := service.CreateBook(context.Background(), authorityRequest("978-1000000005", "Any Author", "Any Press")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.authorities) != 0 {
+		t.Errorf("got %v, want no authorities to be added", repo.authorities)
+	}
+}
+
+func TestBookService_CreateAuthority(t *testing.T) {
+	service := NewBookService(NewMockBookRepository())
+	ctx := context.Background()
+
+	authority, created, err := service.CreateAuthority(ctx, domain.AuthorityPublisher, &domain.CreateAuthorityRequest{Name: "  O'Reilly   Media "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created || authority.Name != "O'Reilly Media" || authority.Kind != domain.AuthorityPublisher {
+		t.Errorf("got %+v (created %v), want a new normalized publisher", authority, created)
+	}
+
+	again, created, err := service.CreateAuthority(ctx, domain.AuthorityPublisher, &domain.CreateAuthorityRequest{Name: "o'reilly media"})
+	if err != nil || created || again.ID != authority.ID {
+		t.Errorf("got %+v (created %v, %v), want the existing publisher", again, created, err)
+	}
+
+	if _, _, err := service.CreateAuthority(ctx, domain.AuthorityAuthor, &domain.CreateAuthorityRequest{Name: "   "}); !errors.Is(err, ErrValidation) {
+		t.Errorf("got %v, want a validation error for a blank name", err)
+	}
+}
+
+func TestBookService_StrictAuthoritiesValidateAgreesWithCreate(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithAuthorityMode(domain.AuthorityModeStrict))
+	ctx := context.Background()
+
+	if _, _, err := service.CreateAuthority(ctx, domain.AuthorityAuthor, &domain.CreateAuthorityRequest{Name: "Known Author"}); err != nil {
+		t.Fatalf("Failed to add author: %v", err)
+	}
+	if _, _, err := service.CreateAuthority(ctx, domain.AuthorityPublisher, &domain.CreateAuthorityRequest{Name: "Known Press"}); err != nil {
+		t.Fatalf("Failed to add publisher: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		req    *domain.CreateBookRequest
+		fields []string
+	}{
+		{"known", authorityRequest("978-1000000006", "known author", "Known Press"), nil},
+		{"unknown author", authorityRequest("978-1000000007", "Unknown Author", "Known Press"), []string{"author"}},
+		{"unknown both", authorityRequest("978-1000000008", "Unknown  Author", "Unknown Press"), []string{"author", "publisher"}},
+	}
+	for _, tt := range tests {
+		errs := service.ValidateBook(ctx, tt.req)
+		var fields []string
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s: errors on = %v, want %v", tt.name, errs, tt.fields)
+		}
+
+		_, err := service.CreateBook(ctx, tt.req)
+		if (len(errs) == 0) != (err == nil) {
+			t.Errorf("%s: validate reported %v but create returned %v", tt.name, errs, err)
+		}
+	}
+}
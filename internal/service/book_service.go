@@ -2,38 +2,216 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"library-management/internal/domain"
+	"library-management/internal/publicid"
 	"library-management/internal/repository"
+	"library-management/internal/storage"
 )
 
+// DefaultBulkUpdateConfirmThreshold is the number of affected rows above which
+// a bulk update must be explicitly confirmed
+const DefaultBulkUpdateConfirmThreshold = 100
+
+// ErrImmutableField is returned when an update would change a field
+// configured as immutable
+var ErrImmutableField = errors.New("is immutable and cannot be changed")
+
+// ErrValidation wraps the reason a request failed validation
+var ErrValidation = errors.New("validation error")
+
+// ErrConfirmRequired is returned when a bulk update matches more books than
+// the confirmation threshold and was not confirmed
+var ErrConfirmRequired = errors.New("set confirm to true to proceed")
+
+// ErrDuplicateISBN is returned when a create or update would give a book
+// the ISBN of another book
+var ErrDuplicateISBN = errors.New("already exists")
+
 type bookService struct {
 	repo repository.BookRepository
+
+	bulkUpdateConfirmThreshold int
+	countMode                  domain.CountMode
+	exportStorage              storage.Storage
+	batchSize                  int
+	progress                   ProgressFunc
+	immutableFields            map[string]bool
+	publicIDs                  publicid.Generator
+	keepOriginalISBN           bool
+	genreAliases               map[string]string
+	authorityMode              domain.AuthorityMode
+	publishYears               domain.PublishYearRange
+	descriptionMaxLength       int
+	normalizeUnicode           bool
+}
+
+// ProgressFunc is called after each committed batch of a large operation
+// with the operation name and the cumulative number of books processed
+type ProgressFunc func(operation string, done int)
+
+// Option configures optional book service behaviour
+type Option func(*bookService)
+
+// WithBulkUpdateConfirmThreshold sets the number of affected rows above which
+// a bulk update requires the confirm flag
+func WithBulkUpdateConfirmThreshold(threshold int) Option {
+	return func(s *bookService) {
+		s.bulkUpdateConfirmThreshold = threshold
+	}
+}
+
+// WithCountMode sets how list totals are computed
+func WithCountMode(mode domain.CountMode) Option {
+	return func(s *bookService) {
+		s.countMode = mode
+	}
+}
+
+// WithExportStorage sets where catalog exports are uploaded
+func WithExportStorage(store storage.Storage) Option {
+	return func(s *bookService) {
+		s.exportStorage = store
+	}
+}
+
+// WithBatchSize sets how many books large operations such as bulk updates
+// process, and commit, at a time
+func WithBatchSize(size int) Option {
+	return func(s *bookService) {
+		s.batchSize = size
+	}
+}
+
+// WithImmutableFields rejects updates that would change any of the given
+// fields once a book exists
+func WithImmutableFields(fields []string) Option {
+	return func(s *bookService) {
+		s.immutableFields = make(map[string]bool, len(fields))
+		for _, field := range fields {
+			s.immutableFields[field] = true
+		}
+	}
+}
+
+// WithProgress sets the function told about each committed batch
+func WithProgress(fn ProgressFunc) Option {
+	return func(s *bookService) {
+		s.progress = fn
+	}
+}
+
+// WithPublicIDGenerator sets how new books get their public ID. Without it
+// the database assigns a random UUID.
+func WithPublicIDGenerator(g publicid.Generator) Option {
+	return func(s *bookService) {
+		s.publicIDs = g
+	}
+}
+
+// WithKeepOriginalISBN makes the ISBN-13 backfill keep each replaced ISBN-10
+// alongside the converted ISBN
+func WithKeepOriginalISBN(keep bool) Option {
+	return func(s *bookService) {
+		s.keepOriginalISBN = keep
+	}
+}
+
+// WithGenreAliases normalizes the genre of every write through aliases,
+// which maps lower-cased variants to canonical genres. Unmapped genres are
+// stored as sent.
+func WithGenreAliases(aliases map[string]string) Option {
+	return func(s *bookService) {
+		s.genreAliases = aliases
+	}
+}
+
+// WithAuthorityMode sets how the author and publisher of writes are checked
+// against the authors and publishers reference tables
+func WithAuthorityMode(mode domain.AuthorityMode) Option {
+	return func(s *bookService) {
+		s.authorityMode = mode
+	}
+}
+
+// WithPublishYearRange sets the publish years that create and update accept
+func WithPublishYearRange(years domain.PublishYearRange) Option {
+	return func(s *bookService) {
+		s.publishYears = years
+	}
+}
+
+// WithDescriptionMaxLength sets the longest description, in characters, that
+// create and update accept
+func WithDescriptionMaxLength(max int) Option {
+	return func(s *bookService) {
+		s.descriptionMaxLength = max
+	}
+}
+
+// WithUnicodeNormalization converts the text fields of every write to
+// Unicode NFC before it is validated and stored
+func WithUnicodeNormalization(enabled bool) Option {
+	return func(s *bookService) {
+		s.normalizeUnicode = enabled
+	}
 }
 
 // NewBookService creates a new book service
-func NewBookService(repo repository.BookRepository) BookService {
-	return &bookService{
-		repo: repo,
+func NewBookService(repo repository.BookRepository, opts ...Option) BookService {
+	s := &bookService{
+		repo:                       repo,
+		bulkUpdateConfirmThreshold: DefaultBulkUpdateConfirmThreshold,
+		countMode:                  domain.CountModeExact,
+		authorityMode:              domain.AuthorityModeOff,
+		publishYears:               domain.DefaultPublishYears(),
+		descriptionMaxLength:       domain.DefaultDescriptionMaxLength,
+		batchSize:                  domain.DefaultBatchSize,
+		progress:                   func(string, int) {},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateBook creates a new book
 func (s *bookService) CreateBook(ctx context.Context, req *domain.CreateBookRequest) (*domain.Book, error) {
-	// Validate the request
-	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("validation error: %w", err)
+	if s.normalizeUnicode {
+		req = req.ToNFC()
 	}
 
-	// Check if a book with this ISBN already exists
-	existingBook, err := s.repo.GetByISBN(ctx, req.ISBN)
-	if err == nil && existingBook != nil {
-		return nil, fmt.Errorf("book with ISBN %s already exists", req.ISBN)
+	// Validate the request
+	if err := req.Validate(s.publishYears, s.descriptionMaxLength); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
 	}
 
 	// Convert request to domain model
 	book := req.ToBook()
+	book.Genre = s.normalizeGenre(book.Genre)
+
+	// Check if a book with this ISBN already exists
+	if s.isbnTaken(ctx, book.ISBN) {
+		return nil, fmt.Errorf("book with ISBN %s %w", book.ISBN, ErrDuplicateISBN)
+	}
+
+	if err := s.checkAuthority(ctx, domain.AuthorityAuthor, book.Author); err != nil {
+		return nil, err
+	}
+	if err := s.checkAuthority(ctx, domain.AuthorityPublisher, book.Publisher); err != nil {
+		return nil, err
+	}
+
+	if s.publicIDs != nil {
+		publicID, err := s.publicIDs.Generate(ctx, book, s.publicIDTaken)
+		if err != nil {
+			return nil, err
+		}
+		book.PublicID = publicID
+	}
 
 	// Create the book
 	createdBook, err := s.repo.Create(ctx, book)
@@ -44,10 +222,57 @@ func (s *bookService) CreateBook(ctx context.Context, req *domain.CreateBookRequ
 	return createdBook, nil
 }
 
+// ValidateBook runs the same checks as CreateBook without saving and
+// returns every field-level failure
+func (s *bookService) ValidateBook(ctx context.Context, req *domain.CreateBookRequest) []domain.FieldError {
+	if s.normalizeUnicode {
+		req = req.ToNFC()
+	}
+	errs := req.FieldErrors(s.publishYears, s.descriptionMaxLength)
+	if isbn := domain.NormalizeSpace(req.ISBN); isbn != "" && s.isbnTaken(ctx, isbn) {
+		errs = append(errs, domain.FieldError{
+			Field:   "isbn",
+			Message: fmt.Sprintf("book with ISBN %s already exists", isbn),
+		})
+	}
+	errs = append(errs, s.authorityFieldErrors(ctx, req.ToBook())...)
+	return errs
+}
+
+// normalizeText normalizes the whitespace of a text field and, when Unicode
+// normalization is on, converts it to NFC
+func (s *bookService) normalizeText(v string) string {
+	if s.normalizeUnicode {
+		v = domain.ToNFC(v)
+	}
+	return domain.NormalizeSpace(v)
+}
+
+// normalizeGenre returns the canonical form of genre when it is a
+// configured alias, ignoring case and surrounding whitespace
+func (s *bookService) normalizeGenre(genre string) string {
+	if canonical, ok := s.genreAliases[strings.ToLower(strings.TrimSpace(genre))]; ok {
+		return canonical
+	}
+	return genre
+}
+
+// isbnTaken reports whether a book with the given ISBN already exists
+func (s *bookService) isbnTaken(ctx context.Context, isbn string) bool {
+	existingBook, err := s.repo.GetByISBN(ctx, isbn)
+	return err == nil && existingBook != nil
+}
+
+// publicIDTaken reports whether a book already has the given public ID
+func (s *bookService) publicIDTaken(ctx context.Context, publicID string) bool {
+	existingBook, err := s.repo.GetByPublicID(ctx, publicID)
+	return err == nil && existingBook != nil
+}
+
 // GetBookByID retrieves a book by its ID
 func (s *bookService) GetBookByID(ctx context.Context, id int) (*domain.Book, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid book ID: %d", id)
+		return nil, fmt.Errorf("%w: invalid book ID: %d", ErrValidation, id)
 	}
 
 	book, err := s.repo.GetByID(ctx, id)
@@ -58,8 +283,89 @@ func (s *bookService) GetBookByID(ctx context.Context, id int) (*domain.Book, er
 	return book, nil
 }
 
+// GetBookForUpdate retrieves a book by its ID, locking it until the
+// transaction in ctx ends
+func (s *bookService) GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid book ID: %d", ErrValidation, id)
+	}
+
+	book, err := s.repo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	return book, nil
+}
+
+// GetBookByPublicID retrieves a book by its public ID
+func (s *bookService) GetBookByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	if !domain.IsPublicID(publicID) {
+		return nil, fmt.Errorf("%w: invalid public book ID: %q", ErrValidation, publicID)
+	}
+
+	book, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	return book, nil
+}
+
+// GetRelatedBooks returns other books sharing the book's author or genre, same-author first
+func (s *bookService) GetRelatedBooks(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error) {
+	if limit <= 0 {
+		limit = domain.DefaultRelatedLimit
+	}
+	if limit > domain.MaxRelatedLimit {
+		limit = domain.MaxRelatedLimit
+	}
+
+	books, err := s.repo.GetRelated(ctx, book, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related books: %w", err)
+	}
+
+	if books == nil {
+		books = []*domain.Book{}
+	}
+
+	return books, nil
+}
+
+// FindDuplicateBooks returns other books with the same title and author as book
+func (s *bookService) FindDuplicateBooks(ctx context.Context, book *domain.Book) ([]*domain.Book, error) {
+	matches, err := s.repo.FindByTitleAuthor(ctx, book.Title, book.Author)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate books: %w", err)
+	}
+
+	duplicates := []*domain.Book{}
+	for _, match := range matches {
+		if match.ID != book.ID {
+			duplicates = append(duplicates, match)
+		}
+	}
+	return duplicates, nil
+}
+
+// GetRandomBook returns a random book matching the filter, or nil when none match
+func (s *bookService) GetRandomBook(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	book, err := s.repo.GetRandom(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get random book: %w", err)
+	}
+	return book, nil
+}
+
 // GetAllBooks retrieves all books with optional filtering
 func (s *bookService) GetAllBooks(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+
 	books, err := s.repo.GetAll(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get books: %w", err)
@@ -73,10 +379,37 @@ func (s *bookService) GetAllBooks(ctx context.Context, filter *domain.BookFilter
 	return books, nil
 }
 
+// StreamBooks calls fn for every book matching filter in ID order, streaming
+// them from the repository
+func (s *bookService) StreamBooks(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+
+	return s.repo.ForEach(ctx, filter, fn)
+}
+
 // UpdateBook updates an existing book
 func (s *bookService) UpdateBook(ctx context.Context, id int, req *domain.UpdateBookRequest) (*domain.Book, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid book ID: %d", id)
+		return nil, fmt.Errorf("%w: invalid book ID: %d", ErrValidation, id)
+	}
+	if s.normalizeUnicode {
+		req = req.ToNFC()
+	}
+
+	// Validate the request
+	if err := req.Validate(s.publishYears, s.descriptionMaxLength); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	// Normalize a copy, leaving the caller's request as sent
+	req = req.Normalized()
+	if req.Genre != nil {
+		genre := s.normalizeGenre(*req.Genre)
+		req.Genre = &genre
 	}
 
 	// Get the existing book
@@ -85,11 +418,30 @@ func (s *bookService) UpdateBook(ctx context.Context, id int, req *domain.Update
 		return nil, fmt.Errorf("failed to get existing book: %w", err)
 	}
 
+	for _, field := range req.ChangedFields(existingBook) {
+		if s.immutableFields[field] {
+			return nil, fmt.Errorf("%s %w", field, ErrImmutableField)
+		}
+	}
+
 	// Check if ISBN is being updated and conflicts with another book
 	if req.ISBN != nil && *req.ISBN != existingBook.ISBN {
 		conflictingBook, err := s.repo.GetByISBN(ctx, *req.ISBN)
 		if err == nil && conflictingBook != nil && conflictingBook.ID != id {
-			return nil, fmt.Errorf("book with ISBN %s already exists", *req.ISBN)
+			return nil, fmt.Errorf("book with ISBN %s %w", *req.ISBN, ErrDuplicateISBN)
+		}
+	}
+
+	// Only a changed author or publisher is checked, so books from before
+	// authority checks can still be edited
+	if req.Author != nil && *req.Author != existingBook.Author {
+		if err := s.checkAuthority(ctx, domain.AuthorityAuthor, *req.Author); err != nil {
+			return nil, err
+		}
+	}
+	if req.Publisher != nil && *req.Publisher != existingBook.Publisher {
+		if err := s.checkAuthority(ctx, domain.AuthorityPublisher, *req.Publisher); err != nil {
+			return nil, err
 		}
 	}
 
@@ -108,7 +460,7 @@ func (s *bookService) UpdateBook(ctx context.Context, id int, req *domain.Update
 // DeleteBook deletes a book by its ID
 func (s *bookService) DeleteBook(ctx context.Context, id int) error {
 	if id <= 0 {
-		return fmt.Errorf("invalid book ID: %d", id)
+		return fmt.Errorf("%w: invalid book ID: %d", ErrValidation, id)
 	}
 
 	// Check if book exists before attempting to delete
@@ -128,7 +480,7 @@ func (s *bookService) DeleteBook(ctx context.Context, id int) error {
 // GetBookByISBN retrieves a book by its ISBN
 func (s *bookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
 	if isbn == "" {
-		return nil, fmt.Errorf("ISBN cannot be empty")
+		return nil, fmt.Errorf("%w: ISBN cannot be empty", ErrValidation)
 	}
 
 	book, err := s.repo.GetByISBN(ctx, isbn)
@@ -139,12 +491,372 @@ func (s *bookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.B
 	return book, nil
 }
 
-// GetBooksCount returns the total number of books with optional filtering
-func (s *bookService) GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, error) {
+// GetBookByAccessionNumber retrieves a book by its accession number
+func (s *bookService) GetBookByAccessionNumber(ctx context.Context, number string) (*domain.Book, error) {
+	if number == "" {
+		return nil, fmt.Errorf("%w: accession number cannot be empty", ErrValidation)
+	}
+
+	book, err := s.repo.GetByAccessionNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book by accession number: %w", err)
+	}
+
+	return book, nil
+}
+
+// GetBooksCount returns the total number of books with optional filtering,
+// and whether the total is an estimate rather than an exact count
+func (s *bookService) GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, bool, error) {
+	estimate := s.countMode == domain.CountModeApproximate ||
+		(s.countMode == domain.CountModeFilteredExact && filter.IsEmpty())
+
+	if estimate {
+		count, err := s.repo.EstimateCount(ctx, filter)
+		if err == nil {
+			return count, true, nil
+		}
+		// Fall back to an exact count when no estimate is available
+	}
+
 	count, err := s.repo.Count(ctx, filter)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get books count: %w", err)
+		return 0, false, fmt.Errorf("failed to get books count: %w", err)
+	}
+
+	return count, false, nil
+}
+
+// GetAuthors returns distinct authors with their book counts and the total number of authors
+func (s *bookService) GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, int, error) {
+	if page == nil {
+		page = &domain.Pagination{}
+	}
+	page.Normalize()
+
+	authors, err := s.repo.GetAuthors(ctx, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get authors: %w", err)
+	}
+
+	total, err := s.repo.CountAuthors(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count authors: %w", err)
+	}
+
+	if authors == nil {
+		authors = []*domain.AuthorCount{}
+	}
+
+	return authors, total, nil
+}
+
+// GetGenres returns distinct genres with their book counts and the total number of genres
+func (s *bookService) GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, int, error) {
+	if page == nil {
+		page = &domain.Pagination{}
+	}
+	page.Normalize()
+
+	genres, err := s.repo.GetGenres(ctx, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get genres: %w", err)
+	}
+
+	total, err := s.repo.CountGenres(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count genres: %w", err)
+	}
+
+	if genres == nil {
+		genres = []*domain.GenreCount{}
+	}
+
+	return genres, total, nil
+}
+
+// GetPublishers returns distinct publishers starting with prefix, with their
+// book counts and the total number of such publishers
+func (s *bookService) GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, int, error) {
+	if page == nil {
+		page = &domain.Pagination{}
+	}
+	page.Normalize()
+
+	publishers, err := s.repo.GetPublishers(ctx, prefix, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get publishers: %w", err)
+	}
+
+	total, err := s.repo.CountPublishers(ctx, prefix)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count publishers: %w", err)
+	}
+
+	if publishers == nil {
+		publishers = []*domain.PublisherCount{}
+	}
+
+	return publishers, total, nil
+}
+
+// GetDistinctValues returns the distinct values of field among the books
+// matching the filter, with their book counts and the total number of such
+// values
+func (s *bookService) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, int, error) {
+	if err := domain.ValidateDistinctField(field); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+	if page == nil {
+		page = &domain.Pagination{}
+	}
+	page.Normalize()
+
+	values, err := s.repo.GetDistinctValues(ctx, field, filter, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get distinct values: %w", err)
+	}
+
+	total, err := s.repo.CountDistinctValues(ctx, field, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count distinct values: %w", err)
+	}
+
+	if values == nil {
+		values = []*domain.DistinctValue{}
+	}
+
+	return values, total, nil
+}
+
+// GetBooksNeedingAttention returns books with data-quality issues, with the
+// reasons each was flagged, and the total number of such books
+func (s *bookService) GetBooksNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, int, error) {
+	if page == nil {
+		page = &domain.Pagination{}
+	}
+	page.Normalize()
+
+	books, err := s.repo.GetNeedingAttention(ctx, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get books needing attention: %w", err)
+	}
+
+	total, err := s.repo.CountNeedingAttention(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count books needing attention: %w", err)
+	}
+
+	if books == nil {
+		books = []*domain.BookAttention{}
+	}
+
+	return books, total, nil
+}
+
+// GetGenreStats returns each genre with its total, available and checked-out counts
+func (s *bookService) GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error) {
+	stats, err := s.repo.GetGenreStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genre stats: %w", err)
+	}
+
+	if stats == nil {
+		stats = []*domain.GenreStats{}
+	}
+
+	return stats, nil
+}
+
+// GetPageStats returns page statistics for the books matching the filter
+func (s *bookService) GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error) {
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+
+	stats, err := s.repo.GetPageStats(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetGrowth returns the number of books created per bucket of the query. The
+// repository reports only buckets with books; the gaps are filled with zero
+// counts so every bucket in the range is present.
+func (s *bookService) GetGrowth(ctx context.Context, query *domain.GrowthQuery) (*domain.CatalogGrowth, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	buckets, err := s.repo.GetGrowth(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get growth: %w", err)
+	}
+
+	growth := &domain.CatalogGrowth{Interval: query.Interval, Buckets: domain.FillGrowthGaps(query, buckets)}
+	for _, bucket := range growth.Buckets {
+		growth.Total += bucket.Books
+	}
+	return growth, nil
+}
+
+// CheckISBNsExist reports, for each requested ISBN as given, whether a book
+// with that ISBN exists, using one repository lookup for the whole batch
+func (s *bookService) CheckISBNsExist(ctx context.Context, req *domain.ISBNExistsRequest) (map[string]bool, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	normalized := make([]string, len(req.ISBNs))
+	for i, isbn := range req.ISBNs {
+		normalized[i] = domain.NormalizeISBN(isbn)
+	}
+
+	existing, err := s.repo.ExistingISBNs(ctx, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ISBNs: %w", err)
+	}
+
+	result := make(map[string]bool, len(req.ISBNs))
+	for i, isbn := range req.ISBNs {
+		result[isbn] = existing[normalized[i]]
+	}
+	return result, nil
+}
+
+// BulkUpdateBooks applies the changes to all books matching the filter in
+// batches and returns the number affected
+func (s *bookService) BulkUpdateBooks(ctx context.Context, req *domain.BulkUpdateRequest) (*domain.BulkUpdateResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	// Normalize a copy, leaving the caller's request as sent
+	normalized := *req
+	if req.Changes.Genre != nil {
+		genre := s.normalizeGenre(s.normalizeText(*req.Changes.Genre))
+		normalized.Changes.Genre = &genre
+	}
+	if req.Changes.Publisher != nil {
+		publisher := s.normalizeText(*req.Changes.Publisher)
+		normalized.Changes.Publisher = &publisher
+	}
+	req = &normalized
+
+	matching, err := s.repo.Count(ctx, &req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count matching books: %w", err)
+	}
+
+	if matching > s.bulkUpdateConfirmThreshold && !req.Confirm {
+		return nil, fmt.Errorf("bulk update would affect %d books; %w", matching, ErrConfirmRequired)
+	}
+
+	if req.Changes.Publisher != nil {
+		if err := s.checkAuthority(ctx, domain.AuthorityPublisher, *req.Changes.Publisher); err != nil {
+			return nil, err
+		}
+	}
+
+	// Update in ID order, one committed batch at a time, so no single
+	// transaction holds locks on every matching row. The count above is
+	// what was checked against the threshold, so the update stops there
+	// even if more books have started matching since.
+	result := &domain.BulkUpdateResult{Matched: matching}
+	batch := req.Filter
+	for result.Affected < matching {
+		remaining := matching - result.Affected
+		batch.Limit = s.batchSize
+		if batch.Limit <= 0 || batch.Limit > remaining {
+			batch.Limit = remaining
+		}
+
+		affected, lastID, err := s.repo.BulkUpdate(ctx, &batch, &req.Changes)
+		if err != nil {
+			return result, fmt.Errorf("failed to bulk update books after %d updated: %w", result.Affected, err)
+		}
+		result.Affected += affected
+		if affected > 0 {
+			s.progress("bulk_update", result.Affected)
+		}
+		if affected < batch.Limit {
+			break
+		}
+		batch.AfterID = lastID
 	}
+	return result, nil
+}
+
+// BackfillISBN13 converts every valid ISBN-10 in the catalog to ISBN-13. Books
+// are read in ID order and each batch's conversions are committed together.
+// Books with a valid ISBN-13 or an invalid ISBN are skipped, as are ISBN-10s
+// whose ISBN-13 another book already has.
+func (s *bookService) BackfillISBN13(ctx context.Context) (*domain.ISBNBackfillResult, error) {
+	result := &domain.ISBNBackfillResult{}
+	filter := &domain.BookFilter{Sort: "id", Limit: s.batchSize}
+
+	for {
+		books, err := s.repo.GetAll(ctx, filter)
+		if err != nil {
+			return result, fmt.Errorf("failed to read books after %d converted: %w", result.Converted, err)
+		}
 
-	return count, nil
-}
\ No newline at end of file
+		var changes []domain.ISBNChange
+		var targets []string
+		for _, book := range books {
+			result.Scanned++
+			if domain.IsISBN13(book.ISBN) {
+				result.AlreadyISBN13++
+				continue
+			}
+			isbn13, ok := domain.ISBN10To13(book.ISBN)
+			if !ok {
+				result.Invalid++
+				continue
+			}
+			changes = append(changes, domain.ISBNChange{ID: book.ID, From: book.ISBN, To: isbn13})
+			targets = append(targets, domain.NormalizeISBN(isbn13))
+		}
+
+		if len(changes) > 0 {
+			taken, err := s.repo.ExistingISBNs(ctx, targets)
+			if err != nil {
+				return result, fmt.Errorf("failed to check converted ISBNs: %w", err)
+			}
+
+			// Two ISBN-10s in the batch can also convert to the same ISBN-13
+			free := changes[:0]
+			for _, change := range changes {
+				target := domain.NormalizeISBN(change.To)
+				if taken[target] {
+					result.Conflicts++
+					continue
+				}
+				taken[target] = true
+				free = append(free, change)
+			}
+
+			if len(free) > 0 {
+				if err := s.repo.ConvertISBNs(ctx, free, s.keepOriginalISBN); err != nil {
+					return result, fmt.Errorf("failed to convert ISBNs after %d converted: %w", result.Converted, err)
+				}
+				result.Converted += len(free)
+				s.progress("isbn_backfill", result.Converted)
+			}
+		}
+
+		if filter.Limit <= 0 || len(books) < filter.Limit {
+			return result, nil
+		}
+		filter.AfterID = books[len(books)-1].ID
+	}
+}
@@ -2,23 +2,53 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"library-management/internal/domain"
+	"library-management/internal/publicid"
+	"library-management/internal/repository/repositorytest"
 )
 
 // MockBookRepository implements repository.BookRepository for testing
 type MockBookRepository struct {
 	books  map[int]*domain.Book
 	nextID int
+
+	// estimate is returned by EstimateCount; zero means no estimate is available
+	estimate int
+
+	// bulkUpdateCalls counts BulkUpdate calls, one per batch
+	bulkUpdateCalls int
+
+	// accessionCounters holds the last accession number given out per year
+	accessionCounters map[int]int
+
+	// originalISBNs holds the ISBNs ConvertISBNs was asked to keep, by book ID
+	originalISBNs map[int]string
+	// convertCalls counts ConvertISBNs calls, one per batch
+	convertCalls int
+
+	// authorities holds the known names of each kind by lower-cased name
+	authorities map[domain.AuthorityKind]map[string]*domain.Authority
+	// nextAuthorityID is the ID given to the next authority created
+	nextAuthorityID int
 }
 
 func NewMockBookRepository() *MockBookRepository {
 	return &MockBookRepository{
-		books:  make(map[int]*domain.Book),
-		nextID: 1,
+		books:             make(map[int]*domain.Book),
+		nextID:            1,
+		accessionCounters: make(map[int]int),
+		originalISBNs:     make(map[int]string),
+		authorities:       make(map[domain.AuthorityKind]map[string]*domain.Authority),
+		nextAuthorityID:   1,
 	}
 }
 
@@ -31,34 +61,124 @@ func (m *MockBookRepository) Create(ctx context.Context, book *domain.Book) (*do
 	}
 
 	book.ID = m.nextID
+	if book.PublicID == "" {
+		book.PublicID = fmt.Sprintf("00000000-0000-4000-8000-%012d", m.nextID)
+	}
 	m.nextID++
-	book.CreatedAt = time.Now()
+	if book.CreatedAt.IsZero() {
+		book.CreatedAt = time.Now()
+	}
 	book.UpdatedAt = time.Now()
+	year := book.CreatedAt.UTC().Year()
+	m.accessionCounters[year]++
+	book.AccessionNumber = fmt.Sprintf("%d-%05d", year, m.accessionCounters[year])
 
 	m.books[book.ID] = book
 	return book, nil
 }
 
+func (m *MockBookRepository) Upsert(ctx context.Context, book *domain.Book) (*domain.Book, bool, error) {
+	for _, existingBook := range m.books {
+		if existingBook.ISBN == book.ISBN {
+			book.ID = existingBook.ID
+			book.AccessionNumber = existingBook.AccessionNumber
+			book.CreatedAt = existingBook.CreatedAt
+			book.UpdatedAt = time.Now()
+			m.books[book.ID] = book
+			return book, false, nil
+		}
+	}
+
+	created, err := m.Create(ctx, book)
+	return created, err == nil, err
+}
+
+func (m *MockBookRepository) GetRelated(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error) {
+	score := func(b *domain.Book) int {
+		s := 0
+		if b.Author == book.Author {
+			s += 2
+		}
+		if b.Genre == book.Genre {
+			s++
+		}
+		return s
+	}
+
+	var related []*domain.Book
+	for _, b := range m.books {
+		if b.ID != book.ID && score(b) > 0 {
+			related = append(related, b)
+		}
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if si, sj := score(related[i]), score(related[j]); si != sj {
+			return si > sj
+		}
+		return related[i].ID < related[j].ID
+	})
+	return paginate(related, &domain.Pagination{Limit: limit}), nil
+}
+
+func (m *MockBookRepository) FindByTitleAuthor(ctx context.Context, title, author string) ([]*domain.Book, error) {
+	var books []*domain.Book
+	for _, b := range m.books {
+		if strings.EqualFold(strings.TrimSpace(b.Title), strings.TrimSpace(title)) &&
+			strings.EqualFold(strings.TrimSpace(b.Author), strings.TrimSpace(author)) {
+			copied := *b
+			books = append(books, &copied)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books, nil
+}
+
 func (m *MockBookRepository) GetByID(ctx context.Context, id int) (*domain.Book, error) {
 	book, exists := m.books[id]
 	if !exists {
-		return nil, fmt.Errorf("book with ID %d not found", id)
+		return nil, fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
 	}
 	return book, nil
 }
 
+func (m *MockBookRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	return m.GetByID(ctx, id)
+}
+
 func (m *MockBookRepository) GetAll(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
 	var books []*domain.Book
 	for _, book := range m.books {
-		books = append(books, book)
+		if matchesFilter(book, filter) {
+			books = append(books, book)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	if filter != nil && filter.Limit > 0 && len(books) > filter.Limit {
+		books = books[:filter.Limit]
 	}
 	return books, nil
 }
 
+func (m *MockBookRepository) ForEach(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	ids := make([]int, 0, len(m.books))
+	for id, book := range m.books {
+		if matchesFilter(book, filter) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if err := fn(m.books[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockBookRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
 	_, exists := m.books[book.ID]
 	if !exists {
-		return nil, fmt.Errorf("book with ID %d not found", book.ID)
+		return nil, fmt.Errorf("book with ID %d %w", book.ID, domain.ErrBookNotFound)
 	}
 
 	book.UpdatedAt = time.Now()
@@ -69,213 +189,1244 @@ func (m *MockBookRepository) Update(ctx context.Context, book *domain.Book) (*do
 func (m *MockBookRepository) Delete(ctx context.Context, id int) error {
 	_, exists := m.books[id]
 	if !exists {
-		return fmt.Errorf("book with ID %d not found", id)
+		return fmt.Errorf("book with ID %d %w", id, domain.ErrBookNotFound)
 	}
 
 	delete(m.books, id)
 	return nil
 }
 
+func (m *MockBookRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	for _, book := range m.books {
+		if book.PublicID == publicID {
+			return book, nil
+		}
+	}
+	return nil, fmt.Errorf("book with public ID %s %w", publicID, domain.ErrBookNotFound)
+}
+
 func (m *MockBookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
 	for _, book := range m.books {
 		if book.ISBN == isbn {
 			return book, nil
 		}
 	}
-	return nil, fmt.Errorf("book with ISBN %s not found", isbn)
+	return nil, fmt.Errorf("book with ISBN %s %w", isbn, domain.ErrBookNotFound)
 }
 
-func (m *MockBookRepository) Count(ctx context.Context, filter *domain.BookFilter) (int, error) {
-	return len(m.books), nil
+func (m *MockBookRepository) GetByAccessionNumber(ctx context.Context, number string) (*domain.Book, error) {
+	for _, book := range m.books {
+		if book.AccessionNumber == number {
+			return book, nil
+		}
+	}
+	return nil, fmt.Errorf("book with accession number %s %w", number, domain.ErrBookNotFound)
 }
 
-// Tests
-func TestBookService_CreateBook(t *testing.T) {
-	repo := NewMockBookRepository()
-	service := NewBookService(repo)
-	ctx := context.Background()
-
-	t.Run("successful creation", func(t *testing.T) {
-		req := &domain.CreateBookRequest{
-			Title:       "Test Book",
-			Author:      "Test Author",
-			ISBN:        "978-1234567890",
-			Publisher:   "Test Publisher",
-			PublishYear: 2024,
-			Genre:       "Test",
-			Pages:       100,
-			Description: "Test description",
+func (m *MockBookRepository) ExistingISBNs(ctx context.Context, isbns []string) (map[string]bool, error) {
+	wanted := make(map[string]bool, len(isbns))
+	for _, isbn := range isbns {
+		wanted[isbn] = true
+	}
+	existing := make(map[string]bool)
+	for _, book := range m.books {
+		if isbn := domain.NormalizeISBN(book.ISBN); wanted[isbn] {
+			existing[isbn] = true
 		}
+	}
+	return existing, nil
+}
 
-		book, err := service.CreateBook(ctx, req)
-		if err != nil {
-			t.Fatalf("Expected no error, got %v", err)
+func (m *MockBookRepository) GetRandom(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	var matching []*domain.Book
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			matching = append(matching, book)
 		}
+	}
+	if len(matching) == 0 {
+		return nil, nil
+	}
+	return matching[rand.Intn(len(matching))], nil
+}
 
-		if book.ID == 0 {
-			t.Error("Expected book ID to be set")
+func (m *MockBookRepository) Count(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	count := 0
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			count++
 		}
+	}
+	return count, nil
+}
 
-		if book.Title != req.Title {
-			t.Errorf("Expected title %s, got %s", req.Title, book.Title)
-		}
-	})
+func (m *MockBookRepository) GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, error) {
+	counts := make(map[string]int)
+	for _, book := range m.books {
+		counts[book.Author]++
+	}
 
-	t.Run("duplicate ISBN", func(t *testing.T) {
-		req1 := &domain.CreateBookRequest{
-			Title:       "Book 1",
-			Author:      "Author 1",
-			ISBN:        "978-1111111111",
-			Publisher:   "Publisher 1",
-			PublishYear: 2024,
-			Genre:       "Genre 1",
-			Pages:       100,
-		}
+	var authors []*domain.AuthorCount
+	for author, count := range counts {
+		authors = append(authors, &domain.AuthorCount{Author: author, Count: count})
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Author < authors[j].Author })
 
-		req2 := &domain.CreateBookRequest{
-			Title:       "Book 2",
-			Author:      "Author 2",
-			ISBN:        "978-1111111111", // Same ISBN
-			Publisher:   "Publisher 2",
-			PublishYear: 2024,
-			Genre:       "Genre 2",
-			Pages:       200,
-		}
+	return paginate(authors, page), nil
+}
 
-		// Create first book
-		_, err := service.CreateBook(ctx, req1)
-		if err != nil {
-			t.Fatalf("Expected no error for first book, got %v", err)
-		}
+func (m *MockBookRepository) CountAuthors(ctx context.Context) (int, error) {
+	authors := make(map[string]bool)
+	for _, book := range m.books {
+		authors[book.Author] = true
+	}
+	return len(authors), nil
+}
 
-		// Try to create second book with same ISBN
-		_, err = service.CreateBook(ctx, req2)
-		if err == nil {
-			t.Error("Expected error for duplicate ISBN")
-		}
-	})
+func (m *MockBookRepository) GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, error) {
+	counts := make(map[string]int)
+	for _, book := range m.books {
+		counts[book.Genre]++
+	}
 
-	t.Run("validation error", func(t *testing.T) {
-		req := &domain.CreateBookRequest{
-			Title:       "", // Empty title should fail validation
-			Author:      "Test Author",
-			ISBN:        "978-1234567890",
-			Publisher:   "Test Publisher",
-			PublishYear: 2024,
-			Genre:       "Test",
-			Pages:       100,
-		}
+	var genres []*domain.GenreCount
+	for genre, count := range counts {
+		genres = append(genres, &domain.GenreCount{Genre: genre, Count: count})
+	}
+	sort.Slice(genres, func(i, j int) bool { return genres[i].Genre < genres[j].Genre })
 
-		_, err := service.CreateBook(ctx, req)
-		if err == nil {
-			t.Error("Expected validation error for empty title")
-		}
-	})
+	return paginate(genres, page), nil
 }
 
-func TestBookService_GetBookByID(t *testing.T) {
-	repo := NewMockBookRepository()
-	service := NewBookService(repo)
-	ctx := context.Background()
+func (m *MockBookRepository) CountGenres(ctx context.Context) (int, error) {
+	genres := make(map[string]bool)
+	for _, book := range m.books {
+		genres[book.Genre] = true
+	}
+	return len(genres), nil
+}
 
-	// Create a book first
-	req := &domain.CreateBookRequest{
-		Title:       "Test Book",
-		Author:      "Test Author",
-		ISBN:        "978-1234567890",
-		Publisher:   "Test Publisher",
-		PublishYear: 2024,
-		Genre:       "Test",
-		Pages:       100,
+func (m *MockBookRepository) GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, error) {
+	counts := make(map[string]int)
+	for _, book := range m.books {
+		if hasPrefixFold(book.Publisher, prefix) {
+			counts[book.Publisher]++
+		}
 	}
 
-	createdBook, err := service.CreateBook(ctx, req)
-	if err != nil {
-		t.Fatalf("Failed to create test book: %v", err)
+	var publishers []*domain.PublisherCount
+	for publisher, count := range counts {
+		publishers = append(publishers, &domain.PublisherCount{Publisher: publisher, Count: count})
 	}
+	sort.Slice(publishers, func(i, j int) bool { return publishers[i].Publisher < publishers[j].Publisher })
 
-	t.Run("successful retrieval", func(t *testing.T) {
-		book, err := service.GetBookByID(ctx, createdBook.ID)
-		if err != nil {
-			t.Fatalf("Expected no error, got %v", err)
-		}
+	return paginate(publishers, page), nil
+}
 
-		if book.ID != createdBook.ID {
-			t.Errorf("Expected ID %d, got %d", createdBook.ID, book.ID)
+func (m *MockBookRepository) CountPublishers(ctx context.Context, prefix string) (int, error) {
+	publishers := make(map[string]bool)
+	for _, book := range m.books {
+		if hasPrefixFold(book.Publisher, prefix) {
+			publishers[book.Publisher] = true
 		}
-	})
+	}
+	return len(publishers), nil
+}
 
-	t.Run("book not found", func(t *testing.T) {
-		_, err := service.GetBookByID(ctx, 999)
-		if err == nil {
-			t.Error("Expected error for non-existent book")
+func (m *MockBookRepository) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, error) {
+	counts := make(map[string]int)
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			counts[distinctValue(book, field)]++
 		}
-	})
+	}
 
-	t.Run("invalid ID", func(t *testing.T) {
-		_, err := service.GetBookByID(ctx, 0)
-		if err == nil {
-			t.Error("Expected error for invalid book ID")
+	var values []*domain.DistinctValue
+	for value, count := range counts {
+		values = append(values, &domain.DistinctValue{Value: value, Count: count})
+	}
+	// Years sort numerically, as the postgres column does
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i].Value, values[j].Value
+		if field == "publish_year" && len(a) != len(b) {
+			return len(a) < len(b)
 		}
+		return a < b
 	})
+
+	return paginate(values, page), nil
 }
 
-func TestBookService_UpdateBook(t *testing.T) {
-	repo := NewMockBookRepository()
-	service := NewBookService(repo)
-	ctx := context.Background()
+func (m *MockBookRepository) CountDistinctValues(ctx context.Context, field string, filter *domain.BookFilter) (int, error) {
+	values := make(map[string]bool)
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			values[distinctValue(book, field)] = true
+		}
+	}
+	return len(values), nil
+}
 
-	// Create a book first
-	req := &domain.CreateBookRequest{
-		Title:       "Original Title",
-		Author:      "Original Author",
-		ISBN:        "978-1234567890",
-		Publisher:   "Original Publisher",
-		PublishYear: 2024,
-		Genre:       "Original Genre",
-		Pages:       100,
+// distinctValue returns the value of one of domain.DistinctFields as text
+func distinctValue(book *domain.Book, field string) string {
+	switch field {
+	case "genre":
+		return book.Genre
+	case "author":
+		return book.Author
+	case "publisher":
+		return book.Publisher
+	default:
+		return fmt.Sprint(book.PublishYear)
 	}
+}
 
-	createdBook, err := service.CreateBook(ctx, req)
-	if err != nil {
-		t.Fatalf("Failed to create test book: %v", err)
+func (m *MockBookRepository) AuthorityExists(ctx context.Context, kind domain.AuthorityKind, name string) (bool, error) {
+	_, ok := m.authorities[kind][strings.ToLower(name)]
+	return ok, nil
+}
+
+func (m *MockBookRepository) CreateAuthority(ctx context.Context, authority *domain.Authority) (*domain.Authority, bool, error) {
+	if m.authorities[authority.Kind] == nil {
+		m.authorities[authority.Kind] = make(map[string]*domain.Authority)
+	}
+	key := strings.ToLower(authority.Name)
+	if existing, ok := m.authorities[authority.Kind][key]; ok {
+		copied := *existing
+		return &copied, false, nil
 	}
 
-	t.Run("successful update", func(t *testing.T) {
-		newTitle := "Updated Title"
-		updateReq := &domain.UpdateBookRequest{
-			Title: &newTitle,
-		}
+	created := &domain.Authority{ID: m.nextAuthorityID, Kind: authority.Kind, Name: authority.Name, CreatedAt: time.Now()}
+	m.nextAuthorityID++
+	m.authorities[authority.Kind][key] = created
+	copied := *created
+	return &copied, true, nil
+}
 
-		updatedBook, err := service.UpdateBook(ctx, createdBook.ID, updateReq)
-		if err != nil {
-			t.Fatalf("Expected no error, got %v", err)
-		}
+// hasPrefixFold reports whether s starts with prefix, ignoring case
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
 
-		if updatedBook.Title != newTitle {
-			t.Errorf("Expected title %s, got %s", newTitle, updatedBook.Title)
-		}
+// attentionReasons applies the same data-quality checks as the postgres query
+func attentionReasons(book *domain.Book) []string {
+	reasons := []string{}
+	if strings.TrimSpace(book.Description) == "" {
+		reasons = append(reasons, domain.ReasonMissingDescription)
+	}
+	if book.Pages < domain.AttentionMinPages {
+		reasons = append(reasons, domain.ReasonLowPageCount)
+	}
+	if book.PublishYear > time.Now().Year() {
+		reasons = append(reasons, domain.ReasonFuturePublishYear)
+	}
+	if strings.TrimSpace(book.Genre) == "" {
+		reasons = append(reasons, domain.ReasonMissingGenre)
+	}
+	return reasons
+}
 
-		// Original author should remain unchanged
-		if updatedBook.Author != req.Author {
-			t.Errorf("Expected author to remain %s, got %s", req.Author, updatedBook.Author)
+func (m *MockBookRepository) GetNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, error) {
+	var results []*domain.BookAttention
+	err := m.ForEach(ctx, nil, func(book *domain.Book) error {
+		if reasons := attentionReasons(book); len(reasons) > 0 {
+			results = append(results, &domain.BookAttention{Book: book, Reasons: reasons})
 		}
+		return nil
 	})
+	return paginate(results, page), err
+}
 
-	t.Run("book not found", func(t *testing.T) {
-		newTitle := "Updated Title"
-		updateReq := &domain.UpdateBookRequest{
-			Title: &newTitle,
+func (m *MockBookRepository) CountNeedingAttention(ctx context.Context) (int, error) {
+	count := 0
+	for _, book := range m.books {
+		if len(attentionReasons(book)) > 0 {
+			count++
 		}
+	}
+	return count, nil
+}
 
-		_, err := service.UpdateBook(ctx, 999, updateReq)
-		if err == nil {
-			t.Error("Expected error for non-existent book")
+func (m *MockBookRepository) GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error) {
+	var pages []int
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			pages = append(pages, book.Pages)
 		}
-	})
+	}
+
+	stats := &domain.PageStats{Books: len(pages)}
+	if len(pages) == 0 {
+		return stats, nil
+	}
+	sort.Ints(pages)
+	for _, p := range pages {
+		stats.TotalPages += p
+	}
+	min, max := pages[0], pages[len(pages)-1]
+	average := math.Round(float64(stats.TotalPages)/float64(len(pages))*100) / 100
+	median := float64(pages[len(pages)/2])
+	if len(pages)%2 == 0 {
+		median = float64(pages[len(pages)/2-1]+pages[len(pages)/2]) / 2
+	}
+	stats.MinPages, stats.MaxPages, stats.Average, stats.Median = &min, &max, &average, &median
+	return stats, nil
 }
 
-func TestBookService_DeleteBook(t *testing.T) {
+func (m *MockBookRepository) GetGrowth(ctx context.Context, query *domain.GrowthQuery) ([]*domain.GrowthBucket, error) {
+	counts := make(map[time.Time]int)
+	for _, book := range m.books {
+		if !book.CreatedAt.Before(query.Start()) && book.CreatedAt.Before(query.End()) {
+			counts[query.Interval.Truncate(book.CreatedAt)]++
+		}
+	}
+
+	var buckets []*domain.GrowthBucket
+	for start, books := range counts {
+		buckets = append(buckets, &domain.GrowthBucket{Start: start, Books: books})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+func (m *MockBookRepository) ConvertISBNs(ctx context.Context, changes []domain.ISBNChange, keepOriginal bool) error {
+	m.convertCalls++
+	for _, change := range changes {
+		book, ok := m.books[change.ID]
+		if !ok {
+			return fmt.Errorf("book with ID %d %w", change.ID, domain.ErrBookNotFound)
+		}
+		book.ISBN = change.To
+		if keepOriginal {
+			m.originalISBNs[change.ID] = change.From
+		}
+	}
+	return nil
+}
+
+func (m *MockBookRepository) BulkUpdate(ctx context.Context, filter *domain.BookFilter, changes *domain.BulkBookChanges) (int, int, error) {
+	m.bulkUpdateCalls++
+
+	var matching []*domain.Book
+	for _, book := range m.books {
+		if matchesFilter(book, filter) {
+			matching = append(matching, book)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID < matching[j].ID })
+	if filter.Limit > 0 && len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+	}
+
+	affected, lastID := 0, 0
+	for _, book := range matching {
+		if changes.Genre != nil {
+			book.Genre = *changes.Genre
+		}
+		if changes.Publisher != nil {
+			book.Publisher = *changes.Publisher
+		}
+		if changes.Available != nil {
+			book.Available = *changes.Available
+		}
+		affected++
+		lastID = book.ID
+	}
+	return affected, lastID, nil
+}
+
+func (m *MockBookRepository) GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error) {
+	byGenre := make(map[string]*domain.GenreStats)
+	for _, book := range m.books {
+		stat, ok := byGenre[book.Genre]
+		if !ok {
+			stat = &domain.GenreStats{Genre: book.Genre}
+			byGenre[book.Genre] = stat
+		}
+		stat.Total++
+		if book.Available {
+			stat.Available++
+		} else {
+			stat.CheckedOut++
+		}
+	}
+
+	var stats []*domain.GenreStats
+	for _, stat := range byGenre {
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Genre < stats[j].Genre })
+	return stats, nil
+}
+
+func (m *MockBookRepository) EstimateCount(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	if m.estimate == 0 {
+		return 0, fmt.Errorf("no row estimate available for books")
+	}
+	return m.estimate, nil
+}
+
+// matchesFilter mirrors the repository filter semantics for the mock
+func matchesFilter(book *domain.Book, filter *domain.BookFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Author != "" && !strings.Contains(strings.ToLower(book.Author), strings.ToLower(filter.Author)) {
+		return false
+	}
+	if filter.Genre != "" && !strings.EqualFold(book.Genre, filter.Genre) {
+		return false
+	}
+	if filter.Publisher != "" && !strings.Contains(strings.ToLower(book.Publisher), strings.ToLower(filter.Publisher)) {
+		return false
+	}
+	if filter.Available != nil && book.Available != *filter.Available {
+		return false
+	}
+	if book.ID <= filter.AfterID {
+		return false
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(book.Title), search) &&
+			!strings.Contains(strings.ToLower(book.Author), search) &&
+			!strings.Contains(strings.ToLower(book.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// paginate applies limit/offset paging to a sorted slice
+func paginate[T any](items []T, page *domain.Pagination) []T {
+	if page.Offset >= len(items) {
+		return nil
+	}
+	end := page.Offset + page.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[page.Offset:end]
+}
+
+// Tests
+func TestMockBookRepository_Upsert(t *testing.T) {
+	repositorytest.TestUpsert(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetRelated(t *testing.T) {
+	repositorytest.TestGetRelated(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetByPublicID(t *testing.T) {
+	repositorytest.TestGetByPublicID(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetNeedingAttention(t *testing.T) {
+	repositorytest.TestGetNeedingAttention(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_CaseInsensitiveFilters(t *testing.T) {
+	repositorytest.TestCaseInsensitiveFilters(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetRandom(t *testing.T) {
+	repositorytest.TestGetRandom(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_FindByTitleAuthor(t *testing.T) {
+	repositorytest.TestFindByTitleAuthor(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetPublishersPrefix(t *testing.T) {
+	repositorytest.TestGetPublishersPrefix(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetPageStats(t *testing.T) {
+	repositorytest.TestGetPageStats(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetGrowth(t *testing.T) {
+	repositorytest.TestGetGrowth(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_AccessionNumbers(t *testing.T) {
+	repositorytest.TestAccessionNumbers(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_ExistingISBNs(t *testing.T) {
+	repositorytest.TestExistingISBNs(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_GetDistinctValues(t *testing.T) {
+	repositorytest.TestGetDistinctValues(t, NewMockBookRepository())
+}
+
+func TestMockBookRepository_Authorities(t *testing.T) {
+	repositorytest.TestAuthorities(t, NewMockBookRepository())
+}
+
+func TestBookService_CheckISBNsExist(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	_, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+		Title:       "Test Book",
+		Author:      "Test Author",
+		ISBN:        "978-1234567890",
+		Publisher:   "Test Publisher",
+		PublishYear: 2024,
+		Genre:       "Test",
+		Pages:       100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+
+	t.Run("mixed existing and missing", func(t *testing.T) {
+		req := &domain.ISBNExistsRequest{ISBNs: []string{"978-1234567890", "978 1234 567890", "978-0000000000"}}
+		exists, err := service.CheckISBNsExist(ctx, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := map[string]bool{"978-1234567890": true, "978 1234 567890": true, "978-0000000000": false}
+		if len(exists) != len(want) {
+			t.Fatalf("got %v, want %v", exists, want)
+		}
+		for isbn, ok := range want {
+			if exists[isbn] != ok {
+				t.Errorf("got %v, want %s exists=%v", exists[isbn], isbn, ok)
+			}
+		}
+	})
+
+	t.Run("too many ISBNs", func(t *testing.T) {
+		req := &domain.ISBNExistsRequest{ISBNs: make([]string, domain.MaxISBNExistsBatch+1)}
+		for i := range req.ISBNs {
+			req.ISBNs[i] = fmt.Sprintf("978-%010d", i)
+		}
+		if _, err := service.CheckISBNsExist(ctx, req); err == nil {
+			t.Error("want error for an oversized batch")
+		}
+	})
+
+	t.Run("empty ISBN", func(t *testing.T) {
+		if _, err := service.CheckISBNsExist(ctx, &domain.ISBNExistsRequest{ISBNs: []string{" - "}}); err == nil {
+			t.Error("want error for an empty ISBN")
+		}
+	})
+}
+
+func TestBookService_GetBookByPublicID(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	created, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+		Title:       "Test Book",
+		Author:      "Test Author",
+		ISBN:        "978-1234567890",
+		Publisher:   "Test Publisher",
+		PublishYear: 2024,
+		Genre:       "Test",
+		Pages:       100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+
+	t.Run("existing public ID", func(t *testing.T) {
+		book, err := service.GetBookByPublicID(ctx, created.PublicID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book.ID != created.ID {
+			t.Errorf("book = %d, want %d", book.ID, created.ID)
+		}
+	})
+
+	t.Run("integer ID is not a public ID", func(t *testing.T) {
+		if _, err := service.GetBookByPublicID(ctx, "1"); err == nil {
+			t.Error("want error for malformed public ID")
+		}
+	})
+}
+
+func TestBookService_CreateBookPublicIDGenerator(t *testing.T) {
+	slugs, err := publicid.New(publicid.FormatSlug)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithPublicIDGenerator(slugs))
+	ctx := context.Background()
+
+	var ids []string
+	for i := 1; i <= 2; i++ {
+		created, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       "Clean Code",
+			Author:      "Robert C. Martin",
+			ISBN:        fmt.Sprintf("978-013235088%d", i),
+			Publisher:   "Prentice Hall",
+			PublishYear: 2008,
+			Genre:       "Programming",
+			Pages:       464,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+		ids = append(ids, created.PublicID)
+	}
+
+	if ids[0] != "clean-code" || ids[1] != "clean-code-2" {
+		t.Errorf("got %v, want public IDs clean-code and clean-code-2", ids)
+	}
+	if book, err := service.GetBookByPublicID(ctx, "clean-code-2"); err != nil || book.ISBN != "978-0132350882" {
+		t.Errorf("got %+v, %v, want the second book by its slug", book, err)
+	}
+}
+
+func TestBookService_BackfillISBN13(t *testing.T) {
+	repo := NewMockBookRepository()
+	var progress []int
+	service := NewBookService(repo, WithBatchSize(2), WithKeepOriginalISBN(true), WithProgress(func(operation string, done int) {
+		progress = append(progress, done)
+	}))
+	ctx := context.Background()
+
+	isbns := []string{
+		"0132350882",     // converts to 978-0132350884
+		"978-0306406157", // already ISBN-13
+		"not-an-isbn",    // invalid
+		"0-306-40615-2",  // its ISBN-13 belongs to the book above
+		"0201633612",     // converts to 978-0201633610
+		"0-201-63361-2",  // same ISBN-10 in another form, in the same batch
+	}
+	for _, isbn := range isbns {
+		if _, err := repo.Create(ctx, &domain.Book{Title: "Book " + isbn, Author: "Author", ISBN: isbn}); err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+	}
+
+	result, err := service.BackfillISBN13(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := domain.ISBNBackfillResult{Scanned: 6, Converted: 2, AlreadyISBN13: 1, Invalid: 1, Conflicts: 2}
+	if *result != want {
+		t.Errorf("got %+v, want %+v", *result, want)
+	}
+
+	wantISBNs := map[int]string{
+		1: "978-0132350884",
+		2: "978-0306406157",
+		3: "not-an-isbn",
+		4: "0-306-40615-2",
+		5: "978-0201633610",
+		6: "0-201-63361-2",
+	}
+	for id, isbn := range wantISBNs {
+		if got := repo.books[id].ISBN; got != isbn {
+			t.Errorf("Book %d: ISBN = %s, want %s", id, got, isbn)
+		}
+	}
+
+	if repo.originalISBNs[1] != "0132350882" || repo.originalISBNs[5] != "0201633612" || len(repo.originalISBNs) != 2 {
+		t.Errorf("got %v, want the converted books' ISBN-10s kept", repo.originalISBNs)
+	}
+	if repo.convertCalls != 2 || len(progress) != 2 || progress[1] != 2 {
+		t.Errorf("got %d calls and progress %v, want two committed batches", repo.convertCalls, progress)
+	}
+
+	// A second run finds nothing left to convert
+	result, err = service.BackfillISBN13(ctx)
+	if err != nil || result.Converted != 0 || result.AlreadyISBN13 != 3 {
+		t.Errorf("got %+v, %v, want an idempotent second run", result, err)
+	}
+}
+
+func TestBookService_GenreAliases(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithGenreAliases(map[string]string{
+		"prog":        "Programming",
+		"programming": "Programming",
+		"sci-fi":      "Science Fiction",
+	}))
+	ctx := context.Background()
+
+	create := func(isbn, genre string) *domain.Book {
+		t.Helper()
+		book, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       "Test Book",
+			Author:      "Test Author",
+			ISBN:        isbn,
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       genre,
+			Pages:       100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+		return book
+	}
+
+	t.Run("mapped genres", func(t *testing.T) {
+		for isbn, genre := range map[string]string{"978-0000000901": "prog", "978-0000000902": " PROG ", "978-0000000903": "programming"} {
+			if book := create(isbn, genre); book.Genre != "Programming" {
+				t.Errorf("got %q, want %q normalized to Programming", book.Genre, genre)
+			}
+		}
+	})
+
+	t.Run("unmapped genre", func(t *testing.T) {
+		if book := create("978-0000000904", "Poetry"); book.Genre != "Poetry" {
+			t.Errorf("got %q, want Poetry stored as sent", book.Genre)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		genre := "Sci-Fi"
+		req := &domain.UpdateBookRequest{Genre: &genre}
+		book, err := service.UpdateBook(ctx, 1, req)
+		if err != nil {
+			t.Fatalf("Failed to update book: %v", err)
+		}
+		if book.Genre != "Science Fiction" {
+			t.Errorf("got %q, want Science Fiction", book.Genre)
+		}
+		if *req.Genre != "Sci-Fi" {
+			t.Errorf("got %q, want the request left as sent", *req.Genre)
+		}
+	})
+
+	t.Run("bulk update", func(t *testing.T) {
+		genre := "sci-fi"
+		_, err := service.BulkUpdateBooks(ctx, &domain.BulkUpdateRequest{
+			Filter:  domain.BookFilter{Genre: "Poetry"},
+			Changes: domain.BulkBookChanges{Genre: &genre},
+		})
+		if err != nil {
+			t.Fatalf("Failed to bulk update: %v", err)
+		}
+		if book, _ := repo.GetByID(ctx, 4); book.Genre != "Science Fiction" {
+			t.Errorf("got %q, want Science Fiction", book.Genre)
+		}
+	})
+}
+
+func TestBookService_GetRelatedBooks(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	var books []*domain.Book
+	for i := 1; i <= domain.MaxRelatedLimit+5; i++ {
+		book, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i),
+			Author:      "Prolific Author",
+			ISBN:        fmt.Sprintf("978-00000%05d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+		books = append(books, book)
+	}
+
+	t.Run("default limit", func(t *testing.T) {
+		related, err := service.GetRelatedBooks(ctx, books[0], 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(related) != domain.DefaultRelatedLimit {
+			t.Errorf("got %d, want %d related books", len(related), domain.DefaultRelatedLimit)
+		}
+	})
+
+	t.Run("limit capped", func(t *testing.T) {
+		related, err := service.GetRelatedBooks(ctx, books[0], 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(related) != domain.MaxRelatedLimit {
+			t.Errorf("got %d, want %d related books", len(related), domain.MaxRelatedLimit)
+		}
+	})
+
+	t.Run("no related books", func(t *testing.T) {
+		related, err := service.GetRelatedBooks(ctx, &domain.Book{ID: 999, Author: "Nobody", Genre: "Nothing"}, 5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if related == nil || len(related) != 0 {
+			t.Errorf("got %v, want an empty list", related)
+		}
+	})
+}
+
+func TestBookService_CreateBook(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	t.Run("successful creation", func(t *testing.T) {
+		req := &domain.CreateBookRequest{
+			Title:       "Test Book",
+			Author:      "Test Author",
+			ISBN:        "978-1234567890",
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+			Description: "Test description",
+		}
+
+		book, err := service.CreateBook(ctx, req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if book.ID == 0 {
+			t.Error("Expected book ID to be set")
+		}
+
+		if book.Title != req.Title {
+			t.Errorf("Expected title %s, got %s", req.Title, book.Title)
+		}
+	})
+
+	t.Run("duplicate ISBN", func(t *testing.T) {
+		req1 := &domain.CreateBookRequest{
+			Title:       "Book 1",
+			Author:      "Author 1",
+			ISBN:        "978-1111111111",
+			Publisher:   "Publisher 1",
+			PublishYear: 2024,
+			Genre:       "Genre 1",
+			Pages:       100,
+		}
+
+		req2 := &domain.CreateBookRequest{
+			Title:       "Book 2",
+			Author:      "Author 2",
+			ISBN:        "978-1111111111", // Same ISBN
+			Publisher:   "Publisher 2",
+			PublishYear: 2024,
+			Genre:       "Genre 2",
+			Pages:       200,
+		}
+
+		// Create first book
+		_, err := service.CreateBook(ctx, req1)
+		if err != nil {
+			t.Fatalf("Expected no error for first book, got %v", err)
+		}
+
+		// Try to create second book with same ISBN
+		_, err = service.CreateBook(ctx, req2)
+		if err == nil {
+			t.Error("Expected error for duplicate ISBN")
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		req := &domain.CreateBookRequest{
+			Title:       "", // Empty title should fail validation
+			Author:      "Test Author",
+			ISBN:        "978-1234567890",
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		}
+
+		_, err := service.CreateBook(ctx, req)
+		if err == nil {
+			t.Error("Expected validation error for empty title")
+		}
+	})
+}
+
+func TestBookService_ValidateBook(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	valid := func() *domain.CreateBookRequest {
+		return &domain.CreateBookRequest{
+			Title:       "Test Book",
+			Author:      "Test Author",
+			ISBN:        "978-1234567890",
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		}
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		if errs := service.ValidateBook(ctx, valid()); len(errs) != 0 {
+			t.Errorf("unexpected errors: %v", errs)
+		}
+		if len(repo.books) != 0 {
+			t.Errorf("got %d books, want nothing saved", len(repo.books))
+		}
+	})
+
+	t.Run("multiple errors", func(t *testing.T) {
+		req := valid()
+		req.Title = ""
+		req.PublishYear = 0
+		req.Pages = 0
+
+		errs := service.ValidateBook(ctx, req)
+		fields := make([]string, 0, len(errs))
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		if strings.Join(fields, ",") != "title,publish_year,pages" {
+			t.Errorf("got %v, want title, publish_year and pages errors", errs)
+		}
+	})
+
+	t.Run("duplicate ISBN", func(t *testing.T) {
+		if _, err := service.CreateBook(ctx, valid()); err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+
+		errs := service.ValidateBook(ctx, valid())
+		if len(errs) != 1 || errs[0].Field != "isbn" {
+			t.Errorf("got %v, want a single isbn error", errs)
+		}
+	})
+}
+
+func TestBookService_GetBookByID(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	// Create a book first
+	req := &domain.CreateBookRequest{
+		Title:       "Test Book",
+		Author:      "Test Author",
+		ISBN:        "978-1234567890",
+		Publisher:   "Test Publisher",
+		PublishYear: 2024,
+		Genre:       "Test",
+		Pages:       100,
+	}
+
+	createdBook, err := service.CreateBook(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to create test book: %v", err)
+	}
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		book, err := service.GetBookByID(ctx, createdBook.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if book.ID != createdBook.ID {
+			t.Errorf("Expected ID %d, got %d", createdBook.ID, book.ID)
+		}
+	})
+
+	t.Run("book not found", func(t *testing.T) {
+		_, err := service.GetBookByID(ctx, 999)
+		if err == nil {
+			t.Error("Expected error for non-existent book")
+		}
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		_, err := service.GetBookByID(ctx, 0)
+		if err == nil {
+			t.Error("Expected error for invalid book ID")
+		}
+	})
+}
+
+func TestBookService_UpdateBook(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	// Create a book first
+	req := &domain.CreateBookRequest{
+		Title:       "Original Title",
+		Author:      "Original Author",
+		ISBN:        "978-1234567890",
+		Publisher:   "Original Publisher",
+		PublishYear: 2024,
+		Genre:       "Original Genre",
+		Pages:       100,
+	}
+
+	createdBook, err := service.CreateBook(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to create test book: %v", err)
+	}
+
+	t.Run("successful update", func(t *testing.T) {
+		newTitle := "Updated Title"
+		updateReq := &domain.UpdateBookRequest{
+			Title: &newTitle,
+		}
+
+		updatedBook, err := service.UpdateBook(ctx, createdBook.ID, updateReq)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if updatedBook.Title != newTitle {
+			t.Errorf("Expected title %s, got %s", newTitle, updatedBook.Title)
+		}
+
+		// Original author should remain unchanged
+		if updatedBook.Author != req.Author {
+			t.Errorf("Expected author to remain %s, got %s", req.Author, updatedBook.Author)
+		}
+	})
+
+	t.Run("book not found", func(t *testing.T) {
+		newTitle := "Updated Title"
+		updateReq := &domain.UpdateBookRequest{
+			Title: &newTitle,
+		}
+
+		_, err := service.UpdateBook(ctx, 999, updateReq)
+		if !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("got %v, want ErrBookNotFound for non-existent book", err)
+		}
+	})
+}
+
+func TestBookService_CreateBookNormalizesText(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	book, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+		Title:       "  Clean   Code ",
+		Author:      "Robert C. Martin ",
+		ISBN:        " 978-0132350884",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+		Genre:       "Programming",
+		Pages:       464,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.Title != "Clean Code" || book.Author != "Robert C. Martin" || book.ISBN != "978-0132350884" {
+		t.Errorf("got %q, %q, %q, want normalized fields", book.Title, book.Author, book.ISBN)
+	}
+
+	// Padding does not get a duplicate ISBN past the uniqueness check
+	_, err = service.CreateBook(ctx, &domain.CreateBookRequest{
+		Title:       "Clean Code",
+		Author:      "Robert C. Martin",
+		ISBN:        "978-0132350884  ",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+		Genre:       "Programming",
+		Pages:       464,
+	})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("got %v, want duplicate ISBN error", err)
+	}
+
+	title := " Clean Code\t"
+	updated, err := service.UpdateBook(ctx, book.ID, &domain.UpdateBookRequest{Title: &title})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Title != "Clean Code" {
+		t.Errorf("got %q, want normalized title", updated.Title)
+	}
+}
+
+func TestBookService_UnicodeNormalization(t *testing.T) {
+	ctx := context.Background()
+	decomposed := "Caf" + "e\u0301"
+	req := &domain.CreateBookRequest{
+		Title:       decomposed,
+		Author:      "Robert C. Martin",
+		ISBN:        "978-0132350884",
+		Publisher:   "Prentice Hall",
+		PublishYear: 2008,
+		Genre:       "Programming",
+		Pages:       464,
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		book, err := NewBookService(NewMockBookRepository()).CreateBook(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateBook() error = %v", err)
+		}
+		if book.Title != decomposed {
+			t.Errorf("title = %q, want %q", book.Title, decomposed)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		service := NewBookService(NewMockBookRepository(), WithUnicodeNormalization(true))
+		book, err := service.CreateBook(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateBook() error = %v", err)
+		}
+		if book.Title != "Caf\u00e9" {
+			t.Errorf("title = %q, want %q", book.Title, "Caf\u00e9")
+		}
+		if req.Title != decomposed {
+			t.Errorf("request title = %q, want it left as sent", req.Title)
+		}
+
+		title := "Cr" + "e\u0300me"
+		updated, err := service.UpdateBook(ctx, book.ID, &domain.UpdateBookRequest{Title: &title})
+		if err != nil {
+			t.Fatalf("UpdateBook() error = %v", err)
+		}
+		if updated.Title != "Cr\u00e8me" {
+			t.Errorf("updated title = %q, want %q", updated.Title, "Cr\u00e8me")
+		}
+	})
+}
+
+func TestBookService_UpdateBookImmutableFields(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithImmutableFields([]string{"isbn", "publish_year"}))
+	ctx := context.Background()
+
+	createdBook, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+		Title:       "Original Title",
+		Author:      "Original Author",
+		ISBN:        "978-1234567890",
+		Publisher:   "Original Publisher",
+		PublishYear: 2024,
+		Genre:       "Original Genre",
+		Pages:       100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test book: %v", err)
+	}
+
+	t.Run("changing an immutable ISBN is rejected", func(t *testing.T) {
+		newISBN := "978-0987654321"
+		_, err := service.UpdateBook(ctx, createdBook.ID, &domain.UpdateBookRequest{ISBN: &newISBN})
+		if !errors.Is(err, ErrImmutableField) {
+			t.Fatalf("got %v, want ErrImmutableField", err)
+		}
+
+		book, err := repo.GetByID(ctx, createdBook.ID)
+		if err != nil {
+			t.Fatalf("Failed to get book: %v", err)
+		}
+		if book.ISBN != "978-1234567890" {
+			t.Errorf("got %s, want ISBN to remain unchanged", book.ISBN)
+		}
+	})
+
+	t.Run("resending the current ISBN is allowed", func(t *testing.T) {
+		sameISBN := createdBook.ISBN
+		newTitle := "Updated Title"
+		updated, err := service.UpdateBook(ctx, createdBook.ID, &domain.UpdateBookRequest{ISBN: &sameISBN, Title: &newTitle})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if updated.Title != newTitle {
+			t.Errorf("title = %s, want %s", updated.Title, newTitle)
+		}
+	})
+}
+
+func TestBookService_StreamBooks(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	for i, genre := range []string{"Fiction", "Science", "fiction"} {
+		if _, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i+1),
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i+1),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       genre,
+			Pages:       100,
+		}); err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	var titles []string
+	err := service.StreamBooks(ctx, &domain.BookFilter{Genre: "Fiction"}, func(book *domain.Book) error {
+		titles = append(titles, book.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(titles) != 2 || titles[0] != "Book 1" || titles[1] != "Book 3" {
+		t.Errorf("got %v, want Book 1 and Book 3 in ID order", titles)
+	}
+
+	if err := service.StreamBooks(ctx, &domain.BookFilter{Sort: "pages; DROP"}, func(*domain.Book) error { return nil }); err == nil {
+		t.Error("want a validation error for an invalid filter")
+	}
+}
+
+func TestBookService_FindDuplicateBooks(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	create := func(title, author, isbn string) *domain.Book {
+		book, err := service.CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       title,
+			Author:      author,
+			ISBN:        isbn,
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+		return book
+	}
+
+	original := create("Clean Code", "Robert C. Martin", "978-0132350884")
+	second := create("clean code", "robert c. martin", "978-0201633610")
+
+	duplicates, err := service.FindDuplicateBooks(ctx, second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(duplicates) != 1 || duplicates[0].ID != original.ID {
+		t.Errorf("got %+v, want only the original book", duplicates)
+	}
+
+	unique := create("Refactoring", "Martin Fowler", "978-0201485677")
+	duplicates, err = service.FindDuplicateBooks(ctx, unique)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("got %+v, want no duplicates", duplicates)
+	}
+}
+
+func TestBookService_DeleteBook(t *testing.T) {
 	repo := NewMockBookRepository()
 	service := NewBookService(repo)
 	ctx := context.Background()
@@ -316,3 +1467,522 @@ func TestBookService_DeleteBook(t *testing.T) {
 		}
 	})
 }
+
+func TestBookService_GetAuthors(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	// Create books by five distinct authors
+	for i := 1; i <= 5; i++ {
+		req := &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i),
+			Author:      fmt.Sprintf("Author %d", i),
+			ISBN:        fmt.Sprintf("978-000000000%d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		}
+		if _, err := service.CreateBook(ctx, req); err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	t.Run("paging through authors", func(t *testing.T) {
+		var seen []string
+		for offset := 0; offset < 5; offset += 2 {
+			authors, total, err := service.GetAuthors(ctx, &domain.Pagination{Limit: 2, Offset: offset})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if total != 5 {
+				t.Errorf("total = %d, want 5", total)
+			}
+			for _, author := range authors {
+				seen = append(seen, author.Author)
+			}
+		}
+
+		if len(seen) != 5 {
+			t.Fatalf("got %d, want 5 authors across pages", len(seen))
+		}
+		for i, author := range seen {
+			expected := fmt.Sprintf("Author %d", i+1)
+			if author != expected {
+				t.Errorf("got %s, want author %s at position %d", author, expected, i)
+			}
+		}
+	})
+
+	t.Run("offset past the end", func(t *testing.T) {
+		authors, _, err := service.GetAuthors(ctx, &domain.Pagination{Limit: 2, Offset: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if authors == nil || len(authors) != 0 {
+			t.Errorf("got %v, want empty non-nil slice", authors)
+		}
+	})
+
+	t.Run("default limit applied", func(t *testing.T) {
+		page := &domain.Pagination{}
+		if _, _, err := service.GetAuthors(ctx, page); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.Limit != domain.DefaultPageLimit {
+			t.Errorf("limit = %d, want %d", page.Limit, domain.DefaultPageLimit)
+		}
+	})
+}
+
+func TestBookService_Publishers(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	publishers := []string{"Addison-Wesley", "O'Reilly Media", "O'Reilly Media", "Prentice Hall"}
+	for i, publisher := range publishers {
+		req := &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i+1),
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i+1),
+			Publisher:   publisher,
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+		}
+		if _, err := service.CreateBook(ctx, req); err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	t.Run("filter is case-insensitive and partial", func(t *testing.T) {
+		filter := &domain.BookFilter{Publisher: "o'reilly"}
+		books, err := service.GetAllBooks(ctx, filter)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(books) != 2 {
+			t.Errorf("got %d, want 2 books", len(books))
+		}
+
+		count, _, err := service.GetBooksCount(ctx, filter)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("count = %d, want 2", count)
+		}
+	})
+
+	t.Run("aggregation", func(t *testing.T) {
+		counts, total, err := service.GetPublishers(ctx, "", &domain.Pagination{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+		if len(counts) != 3 || counts[1].Publisher != "O'Reilly Media" || counts[1].Count != 2 {
+			t.Errorf("got %+v, want O'Reilly Media with 2 books second", counts)
+		}
+	})
+
+	t.Run("aggregation by prefix", func(t *testing.T) {
+		counts, total, err := service.GetPublishers(ctx, "o'r", &domain.Pagination{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 1 || len(counts) != 1 || counts[0].Publisher != "O'Reilly Media" || counts[0].Count != 2 {
+			t.Errorf("got %+v (total %d), want only O'Reilly Media with 2 books", counts, total)
+		}
+	})
+}
+
+func TestBookService_BulkUpdateBooks(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithBulkUpdateConfirmThreshold(2))
+	ctx := context.Background()
+
+	// Create three mislabeled books and one correctly labeled one
+	genres := []string{"Prog", "prog", "Prog", "Architecture"}
+	for i, genre := range genres {
+		req := &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i),
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       genre,
+			Pages:       100,
+		}
+		if _, err := service.CreateBook(ctx, req); err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	programming := "Programming"
+	req := &domain.BulkUpdateRequest{
+		Filter:  domain.BookFilter{Genre: "Prog"},
+		Changes: domain.BulkBookChanges{Genre: &programming},
+	}
+
+	t.Run("confirmation required above threshold", func(t *testing.T) {
+		_, err := service.BulkUpdateBooks(ctx, req)
+		if err == nil {
+			t.Fatal("want error when confirmation is missing")
+		}
+	})
+
+	t.Run("genre rename", func(t *testing.T) {
+		req.Confirm = true
+		result, err := service.BulkUpdateBooks(ctx, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Matched != 3 || result.Affected != 3 {
+			t.Errorf("got %+v, want 3 books matched and affected", result)
+		}
+
+		remaining, _ := repo.Count(ctx, &domain.BookFilter{Genre: "Prog"})
+		if remaining != 0 {
+			t.Errorf("got %d, want no books left in genre Prog", remaining)
+		}
+		renamed, _ := repo.Count(ctx, &domain.BookFilter{Genre: "Programming"})
+		if renamed != 3 {
+			t.Errorf("got %d, want 3 books in genre Programming", renamed)
+		}
+	})
+
+	t.Run("empty filter rejected", func(t *testing.T) {
+		_, err := service.BulkUpdateBooks(ctx, &domain.BulkUpdateRequest{
+			Changes: domain.BulkBookChanges{Genre: &programming},
+			Confirm: true,
+		})
+		if err == nil {
+			t.Error("want error for empty filter")
+		}
+	})
+}
+
+func TestBookService_BulkUpdateBooksBatches(t *testing.T) {
+	repo := NewMockBookRepository()
+	var progress []int
+	service := NewBookService(repo,
+		WithBatchSize(4),
+		WithProgress(func(operation string, done int) { progress = append(progress, done) }),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		_, err := repo.Create(ctx, &domain.Book{
+			Title:     fmt.Sprintf("Book %d", i),
+			ISBN:      fmt.Sprintf("978-00000001%02d", i),
+			Genre:     "Fiction",
+			Available: true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	// The change leaves the books matching the filter, so batching must
+	// advance by ID rather than relying on updated rows dropping out
+	publisher := "New Publisher"
+	result, err := service.BulkUpdateBooks(ctx, &domain.BulkUpdateRequest{
+		Filter:  domain.BookFilter{Genre: "Fiction"},
+		Changes: domain.BulkBookChanges{Publisher: &publisher},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Affected != 10 {
+		t.Errorf("got %d, want 10 books affected", result.Affected)
+	}
+	if repo.bulkUpdateCalls != 3 {
+		t.Errorf("got %d, want 3 batches", repo.bulkUpdateCalls)
+	}
+	if want := []int{4, 8, 10}; fmt.Sprint(progress) != fmt.Sprint(want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+}
+
+// insertAfterCountRepository adds a matching book right after each count,
+// as a concurrent insert between the confirm check and the update would
+type insertAfterCountRepository struct {
+	*MockBookRepository
+	inserted int
+}
+
+func (r *insertAfterCountRepository) Count(ctx context.Context, filter *domain.BookFilter) (int, error) {
+	count, err := r.MockBookRepository.Count(ctx, filter)
+	r.inserted++
+	r.MockBookRepository.Create(ctx, &domain.Book{
+		Title: "Late Book",
+		ISBN:  fmt.Sprintf("978-00000002%02d", r.inserted),
+		Genre: filter.Genre,
+	})
+	return count, err
+}
+
+func TestBookService_BulkUpdateBooksStopsAtConfirmedCount(t *testing.T) {
+	repo := &insertAfterCountRepository{MockBookRepository: NewMockBookRepository()}
+	service := NewBookService(repo, WithBulkUpdateConfirmThreshold(3), WithBatchSize(2))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Create(ctx, &domain.Book{Title: fmt.Sprintf("Book %d", i), ISBN: fmt.Sprintf("978-00000003%02d", i), Genre: "Fiction"}); err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	publisher := "New Publisher"
+	result, err := service.BulkUpdateBooks(ctx, &domain.BulkUpdateRequest{
+		Filter:  domain.BookFilter{Genre: "Fiction"},
+		Changes: domain.BulkBookChanges{Publisher: &publisher},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error without confirmation at the threshold: %v", err)
+	}
+	if result.Matched != 3 || result.Affected != 3 {
+		t.Errorf("got %+v, want 3 books matched and affected", result)
+	}
+	if updated, _ := repo.Count(ctx, &domain.BookFilter{Publisher: publisher}); updated != 3 {
+		t.Errorf("got %d updated, want the book inserted after the count to be left alone", updated)
+	}
+}
+
+func TestBookService_GetGenreStats(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	books := []struct {
+		genre     string
+		available bool
+	}{
+		{"Architecture", true},
+		{"Architecture", false},
+		{"Programming", true},
+		{"Programming", true},
+		{"Programming", false},
+	}
+	for i, b := range books {
+		req := &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i),
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       b.genre,
+			Pages:       100,
+		}
+		created, err := service.CreateBook(ctx, req)
+		if err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+		if !b.available {
+			available := false
+			if _, err := service.UpdateBook(ctx, created.ID, &domain.UpdateBookRequest{Available: &available}); err != nil {
+				t.Fatalf("Failed to check out test book: %v", err)
+			}
+		}
+	}
+
+	stats, err := service.GetGenreStats(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []domain.GenreStats{
+		{Genre: "Architecture", Total: 2, Available: 1, CheckedOut: 1},
+		{Genre: "Programming", Total: 3, Available: 2, CheckedOut: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("got %d, want %d genres", len(stats), len(expected))
+	}
+	for i, want := range expected {
+		if *stats[i] != want {
+			t.Errorf("got %+v, want %+v", *stats[i], want)
+		}
+	}
+}
+
+func TestBookService_GetGrowth(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo)
+	ctx := context.Background()
+
+	for i, at := range []time.Time{
+		time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 28, 18, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC),
+	} {
+		repo.Create(ctx, &domain.Book{ISBN: fmt.Sprintf("978-000000000%d", i), CreatedAt: at})
+	}
+
+	growth, err := service.GetGrowth(ctx, &domain.GrowthQuery{
+		Interval: domain.GrowthIntervalMonth,
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// February, March and May have no books but still get a bucket
+	expected := []int{2, 0, 0, 1, 0}
+	if len(growth.Buckets) != len(expected) {
+		t.Fatalf("got %d, want %d buckets", len(growth.Buckets), len(expected))
+	}
+	for i, books := range expected {
+		bucket := growth.Buckets[i]
+		if start := time.Date(2024, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC); !bucket.Start.Equal(start) || bucket.Books != books {
+			t.Errorf("got %d from %s, want %d books from %s", bucket.Books, bucket.Start, books, start)
+		}
+	}
+	if growth.Total != 3 || growth.Interval != domain.GrowthIntervalMonth {
+		t.Errorf("got %d by %s, want 3 books by month", growth.Total, growth.Interval)
+	}
+
+	if _, err := service.GetGrowth(ctx, &domain.GrowthQuery{
+		Interval: domain.GrowthIntervalDay,
+		From:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}); err == nil {
+		t.Error("want error for from after to")
+	}
+}
+
+func TestBookService_PublishYearRange(t *testing.T) {
+	repo := NewMockBookRepository()
+	service := NewBookService(repo, WithPublishYearRange(domain.PublishYearRange{Min: 1900, Max: 2040}))
+	ctx := context.Background()
+
+	cases := []struct {
+		year    int
+		wantErr bool
+	}{
+		{1899, true},
+		{1900, false},
+		{2040, false},
+		{2041, true},
+	}
+
+	for i, tc := range cases {
+		req := &domain.CreateBookRequest{
+			Title:       "Test Book",
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: tc.year,
+			Genre:       "Test",
+			Pages:       100,
+		}
+
+		_, err := service.CreateBook(ctx, req)
+		if tc.wantErr && err == nil {
+			t.Errorf("want error for publish year %d", tc.year)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("unexpected error for publish year %d: %v", tc.year, err)
+		}
+	}
+
+	t.Run("update outside range", func(t *testing.T) {
+		year := 1899
+		_, err := service.UpdateBook(ctx, 2, &domain.UpdateBookRequest{PublishYear: &year})
+		if err == nil {
+			t.Error("want error updating publish year below the minimum")
+		}
+	})
+}
+
+func TestBookService_DescriptionMaxLength(t *testing.T) {
+	service := NewBookService(NewMockBookRepository(), WithDescriptionMaxLength(5))
+	ctx := context.Background()
+
+	req := &domain.CreateBookRequest{
+		Title:       "Test Book",
+		Author:      "Test Author",
+		ISBN:        "978-0000000001",
+		Publisher:   "Test Publisher",
+		PublishYear: 2001,
+		Genre:       "Test",
+		Pages:       100,
+		Description: "abcdef",
+	}
+	if _, err := service.CreateBook(ctx, req); !errors.Is(err, domain.ErrDescriptionTooLong) {
+		t.Errorf("got %v, want ErrDescriptionTooLong", err)
+	}
+	if errs := service.ValidateBook(ctx, req); len(errs) != 1 || errs[0].Field != "description" {
+		t.Errorf("got %+v, want a description field error", errs)
+	}
+
+	req.Description = "abcde"
+	if _, err := service.CreateBook(ctx, req); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBookService_GetBooksCount(t *testing.T) {
+	ctx := context.Background()
+	unfiltered := &domain.BookFilter{}
+	filtered := &domain.BookFilter{Genre: "Programming"}
+
+	newRepo := func() *MockBookRepository {
+		repo := NewMockBookRepository()
+		repo.estimate = 1000
+		for i, genre := range []string{"Programming", "Programming", "Architecture"} {
+			repo.Create(ctx, &domain.Book{ISBN: fmt.Sprintf("978-000000000%d", i), Genre: genre})
+		}
+		return repo
+	}
+
+	cases := []struct {
+		name          string
+		mode          domain.CountMode
+		filter        *domain.BookFilter
+		wantCount     int
+		wantEstimated bool
+	}{
+		{"exact unfiltered", domain.CountModeExact, unfiltered, 3, false},
+		{"exact filtered", domain.CountModeExact, filtered, 2, false},
+		{"approximate unfiltered", domain.CountModeApproximate, unfiltered, 1000, true},
+		{"approximate filtered", domain.CountModeApproximate, filtered, 1000, true},
+		{"filtered_exact unfiltered", domain.CountModeFilteredExact, unfiltered, 1000, true},
+		{"filtered_exact filtered", domain.CountModeFilteredExact, filtered, 2, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewBookService(newRepo(), WithCountMode(tc.mode))
+
+			count, estimated, err := service.GetBooksCount(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != tc.wantCount {
+				t.Errorf("count = %d, want %d", count, tc.wantCount)
+			}
+			if estimated != tc.wantEstimated {
+				t.Errorf("estimated = %v, want %v", estimated, tc.wantEstimated)
+			}
+		})
+	}
+
+	t.Run("falls back to exact without an estimate", func(t *testing.T) {
+		repo := newRepo()
+		repo.estimate = 0
+		service := NewBookService(repo, WithCountMode(domain.CountModeApproximate))
+
+		count, estimated, err := service.GetBooksCount(ctx, unfiltered)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 3 || estimated {
+			t.Errorf("got %d (estimated %v), want exact count 3", count, estimated)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"library-management/internal/domain"
+)
+
+// ExportKeyPrefix starts the storage key of every requested catalog export
+const ExportKeyPrefix = "catalog-"
+
+// BackupKeyPrefix starts the storage key of every scheduled backup, keeping
+// them apart from requested exports so pruning backups never touches those
+const BackupKeyPrefix = "backup-"
+
+// ErrExportNotConfigured is returned when no export storage has been set
+var ErrExportNotConfigured = errors.New("catalog export storage is not configured")
+
+// csvHeader lists the columns of a CSV catalog export
+var csvHeader = []string{
+	"id", "public_id", "title", "author", "isbn", "publisher", "publish_year",
+	"genre", "pages", "available", "description", "created_at", "updated_at",
+}
+
+// ExportCatalog streams every book in the given format to the export storage.
+// The export is piped straight from the database rows to the storage backend.
+func (s *bookService) ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	return s.exportCatalog(ctx, ExportKeyPrefix, format)
+}
+
+// BackupCatalog exports the catalog like ExportCatalog, under BackupKeyPrefix
+func (s *bookService) BackupCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	return s.exportCatalog(ctx, BackupKeyPrefix, format)
+}
+
+// exportCatalog streams the catalog to a key starting with prefix
+func (s *bookService) exportCatalog(ctx context.Context, prefix string, format domain.ExportFormat) (*domain.ExportResult, error) {
+	if s.exportStorage == nil {
+		return nil, ErrExportNotConfigured
+	}
+	if _, err := domain.ParseExportFormat(string(format)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	key := fmt.Sprintf("%s%s.%s", prefix, time.Now().UTC().Format("20060102T150405Z"), format)
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.writeCatalog(ctx, pw, format))
+	}()
+
+	size, err := s.exportStorage.Put(ctx, key, pr)
+	// Unblock the writer if storage stopped reading early
+	pr.CloseWithError(errors.New("export upload finished"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to export catalog: %w", err)
+	}
+
+	return &domain.ExportResult{Key: key, Size: size, Format: format}, nil
+}
+
+// writeCatalog writes every book to w in the given format
+func (s *bookService) writeCatalog(ctx context.Context, w io.Writer, format domain.ExportFormat) error {
+	if format == domain.ExportFormatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		err := s.repo.ForEach(ctx, nil, func(book *domain.Book) error {
+			return cw.Write([]string{
+				strconv.Itoa(book.ID), book.PublicID, book.Title, book.Author, book.ISBN,
+				book.Publisher, strconv.Itoa(book.PublishYear), book.Genre,
+				strconv.Itoa(book.Pages), strconv.FormatBool(book.Available), book.Description,
+				book.CreatedAt.UTC().Format(time.RFC3339), book.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	sep := ""
+	err := s.repo.ForEach(ctx, nil, func(book *domain.Book) error {
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		sep = ","
+		return enc.Encode(book)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
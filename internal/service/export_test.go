@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"library-management/internal/domain"
+)
+
+// mockStorage records uploaded objects in memory
+type mockStorage struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *mockStorage) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, body)
+	if err != nil {
+		return 0, err
+	}
+	m.objects[key] = buf.Bytes()
+	return n, nil
+}
+
+func (m *mockStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func TestBookService_ExportCatalog(t *testing.T) {
+	repo := NewMockBookRepository()
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		_, err := NewBookService(repo).CreateBook(ctx, &domain.CreateBookRequest{
+			Title:       fmt.Sprintf("Book %d", i),
+			Author:      "Test Author",
+			ISBN:        fmt.Sprintf("978-000000000%d", i),
+			Publisher:   "Test Publisher",
+			PublishYear: 2024,
+			Genre:       "Test",
+			Pages:       100,
+			Description: "Quotes \"and\", commas",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create test book: %v", err)
+		}
+	}
+
+	store := &mockStorage{objects: map[string][]byte{}}
+	service := NewBookService(repo, WithExportStorage(store))
+
+	t.Run("json", func(t *testing.T) {
+		result, err := service.ExportCatalog(ctx, domain.ExportFormatJSON)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(result.Key, "catalog-") || !strings.HasSuffix(result.Key, ".json") {
+			t.Errorf("Unexpected key %s", result.Key)
+		}
+		data := store.objects[result.Key]
+		if result.Size != int64(len(data)) {
+			t.Errorf("size = %d, want %d", result.Size, len(data))
+		}
+
+		var books []domain.Book
+		if err := json.Unmarshal(data, &books); err != nil {
+			t.Fatalf("Export is not valid JSON: %v", err)
+		}
+		if len(books) != 3 || books[0].Title != "Book 1" || books[2].Title != "Book 3" {
+			t.Errorf("got %+v, want 3 books in ID order", books)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		result, err := service.ExportCatalog(ctx, domain.ExportFormatCSV)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		records, err := csv.NewReader(bytes.NewReader(store.objects[result.Key])).ReadAll()
+		if err != nil {
+			t.Fatalf("Export is not valid CSV: %v", err)
+		}
+		if len(records) != 4 || records[0][2] != "title" {
+			t.Fatalf("got %v, want header and 3 rows", records)
+		}
+		if records[1][2] != "Book 1" || records[1][10] != "Quotes \"and\", commas" {
+			t.Errorf("Unexpected first row %v", records[1])
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		result, err := service.BackupCatalog(ctx, domain.ExportFormatJSON)
+		if err != nil {
+			t.Fatalf("BackupCatalog() error = %v", err)
+		}
+		if !strings.HasPrefix(result.Key, BackupKeyPrefix) || strings.HasPrefix(result.Key, ExportKeyPrefix) {
+			t.Errorf("key = %s, want prefix %s", result.Key, BackupKeyPrefix)
+		}
+		if _, ok := store.objects[result.Key]; !ok {
+			t.Errorf("backup %s was not stored", result.Key)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if _, err := service.ExportCatalog(ctx, "xml"); err == nil {
+			t.Error("want validation error")
+		}
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		failing := NewBookService(repo, WithExportStorage(&mockStorage{err: errors.New("bucket unavailable")}))
+		if _, err := failing.ExportCatalog(ctx, domain.ExportFormatJSON); err == nil || !strings.Contains(err.Error(), "bucket unavailable") {
+			t.Errorf("got %v, want storage error", err)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		if _, err := NewBookService(repo).ExportCatalog(ctx, domain.ExportFormatJSON); !errors.Is(err, ErrExportNotConfigured) {
+			t.Errorf("got %v, want ErrExportNotConfigured", err)
+		}
+	})
+}
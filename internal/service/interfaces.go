@@ -10,11 +10,35 @@ type BookService interface {
 	// CreateBook creates a new book
 	CreateBook(ctx context.Context, req *domain.CreateBookRequest) (*domain.Book, error)
 	
+	// ValidateBook runs create validation without saving and returns every field error
+	ValidateBook(ctx context.Context, req *domain.CreateBookRequest) []domain.FieldError
+	
 	// GetBookByID retrieves a book by its ID
 	GetBookByID(ctx context.Context, id int) (*domain.Book, error)
 	
+	// GetBookForUpdate retrieves a book by its ID and locks it until the
+	// transaction in ctx ends, for checks that must hold until a write
+	GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error)
+	
+	// GetBookByPublicID retrieves a book by its public ID
+	GetBookByPublicID(ctx context.Context, publicID string) (*domain.Book, error)
+	
+	// GetRelatedBooks returns other books sharing the book's author or genre, same-author first
+	GetRelatedBooks(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error)
+	
+	// FindDuplicateBooks returns other books with the same title and author
+	// as book, ignoring case; a likely duplicate even when ISBNs differ
+	FindDuplicateBooks(ctx context.Context, book *domain.Book) ([]*domain.Book, error)
+	
+	// GetRandomBook returns a random book matching the filter, or nil when none match
+	GetRandomBook(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error)
+	
 	// GetAllBooks retrieves all books with optional filtering
 	GetAllBooks(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error)
+
+	// StreamBooks calls fn for every book matching filter in ID order without
+	// loading them all, stopping at the first error fn returns
+	StreamBooks(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error
 	
 	// UpdateBook updates an existing book
 	UpdateBook(ctx context.Context, id int, req *domain.UpdateBookRequest) (*domain.Book, error)
@@ -25,6 +49,63 @@ type BookService interface {
 	// GetBookByISBN retrieves a book by its ISBN
 	GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error)
 	
-	// GetBooksCount returns the total number of books with optional filtering
-	GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, error)
-}
\ No newline at end of file
+	// GetBookByAccessionNumber retrieves a book by its accession number
+	GetBookByAccessionNumber(ctx context.Context, number string) (*domain.Book, error)
+	
+	// CheckISBNsExist reports, for each requested ISBN as given, whether a
+	// book with that ISBN exists. ISBNs are compared after normalization.
+	CheckISBNsExist(ctx context.Context, req *domain.ISBNExistsRequest) (map[string]bool, error)
+	
+	// GetBooksCount returns the total number of books with optional filtering,
+	// and whether the total is an estimate rather than an exact count
+	GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, bool, error)
+	
+	// GetAuthors returns distinct authors with their book counts and the total number of authors
+	GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, int, error)
+	
+	// GetGenres returns distinct genres with their book counts and the total number of genres
+	GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, int, error)
+	
+	// GetPublishers returns distinct publishers with their book counts and the
+	// total number of publishers; a non-empty prefix keeps those starting with it
+	GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, int, error)
+	
+	// GetDistinctValues returns the distinct values of field among the books
+	// matching the filter, with their book counts, and the total number of
+	// such values
+	GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, int, error)
+	
+	// GetBooksNeedingAttention returns books with data-quality issues, with the
+	// reasons each was flagged, and the total number of such books
+	GetBooksNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, int, error)
+	
+	// BulkUpdateBooks applies the changes to the books matching the filter,
+	// at most as many as matched when checked, and reports both counts
+	BulkUpdateBooks(ctx context.Context, req *domain.BulkUpdateRequest) (*domain.BulkUpdateResult, error)
+	
+	// BackfillISBN13 converts every valid ISBN-10 in the catalog to ISBN-13,
+	// batch by batch, and reports what it did with each book
+	BackfillISBN13(ctx context.Context) (*domain.ISBNBackfillResult, error)
+	
+	// GetGenreStats returns each genre with its total, available and checked-out counts
+	GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error)
+	
+	// GetPageStats returns the count, total, min, max, average and median
+	// pages of the books matching the filter
+	GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error)
+	
+	// GetGrowth returns the number of books created per bucket of the query,
+	// with zero-count buckets filling the gaps
+	GetGrowth(ctx context.Context, query *domain.GrowthQuery) (*domain.CatalogGrowth, error)
+	
+	// ExportCatalog streams every book in the given format to the export storage
+	ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error)
+	
+	// BackupCatalog exports the catalog like ExportCatalog under the scheduled
+	// backup key prefix
+	BackupCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error)
+	
+	// CreateAuthority adds a known author or publisher and reports whether it
+	// was added; a name already known, ignoring case, is returned as stored
+	CreateAuthority(ctx context.Context, kind domain.AuthorityKind, req *domain.CreateAuthorityRequest) (*domain.Authority, bool, error)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"library-management/internal/domain"
+)
+
+// TracerName identifies spans created by the service layer
+const TracerName = "library-management/service"
+
+// tracingService wraps a BookService with a span per call
+type tracingService struct {
+	next   BookService
+	tracer trace.Tracer
+}
+
+// NewTracingService wraps next so every call is traced with tp
+func NewTracingService(next BookService, tp trace.TracerProvider) BookService {
+	return &tracingService{next: next, tracer: tp.Tracer(TracerName)}
+}
+
+func (t *tracingService) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "BookService."+method)
+}
+
+// end records err, if any, and ends the span
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *tracingService) ValidateBook(ctx context.Context, req *domain.CreateBookRequest) []domain.FieldError {
+	ctx, span := t.start(ctx, "ValidateBook")
+	defer span.End()
+	return t.next.ValidateBook(ctx, req)
+}
+
+func (t *tracingService) CreateBook(ctx context.Context, req *domain.CreateBookRequest) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "CreateBook")
+	result, err := t.next.CreateBook(ctx, req)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetBookByID(ctx context.Context, id int) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetBookByID")
+	result, err := t.next.GetBookByID(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetBookForUpdate")
+	result, err := t.next.GetBookForUpdate(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetBookByPublicID(ctx context.Context, publicID string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetBookByPublicID")
+	result, err := t.next.GetBookByPublicID(ctx, publicID)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetRelatedBooks(ctx context.Context, book *domain.Book, limit int) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetRelatedBooks")
+	result, err := t.next.GetRelatedBooks(ctx, book, limit)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) FindDuplicateBooks(ctx context.Context, book *domain.Book) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "FindDuplicateBooks")
+	result, err := t.next.FindDuplicateBooks(ctx, book)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetRandomBook(ctx context.Context, filter *domain.BookFilter) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetRandomBook")
+	result, err := t.next.GetRandomBook(ctx, filter)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetAllBooks(ctx context.Context, filter *domain.BookFilter) ([]*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetAllBooks")
+	result, err := t.next.GetAllBooks(ctx, filter)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) UpdateBook(ctx context.Context, id int, req *domain.UpdateBookRequest) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "UpdateBook")
+	result, err := t.next.UpdateBook(ctx, id, req)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) DeleteBook(ctx context.Context, id int) error {
+	ctx, span := t.start(ctx, "DeleteBook")
+	err := t.next.DeleteBook(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (t *tracingService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetBookByISBN")
+	result, err := t.next.GetBookByISBN(ctx, isbn)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetBookByAccessionNumber(ctx context.Context, number string) (*domain.Book, error) {
+	ctx, span := t.start(ctx, "GetBookByAccessionNumber")
+	result, err := t.next.GetBookByAccessionNumber(ctx, number)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) CheckISBNsExist(ctx context.Context, req *domain.ISBNExistsRequest) (map[string]bool, error) {
+	ctx, span := t.start(ctx, "CheckISBNsExist")
+	result, err := t.next.CheckISBNsExist(ctx, req)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetBooksCount(ctx context.Context, filter *domain.BookFilter) (int, bool, error) {
+	ctx, span := t.start(ctx, "GetBooksCount")
+	result, extra, err := t.next.GetBooksCount(ctx, filter)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) GetAuthors(ctx context.Context, page *domain.Pagination) ([]*domain.AuthorCount, int, error) {
+	ctx, span := t.start(ctx, "GetAuthors")
+	result, extra, err := t.next.GetAuthors(ctx, page)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) GetGenres(ctx context.Context, page *domain.Pagination) ([]*domain.GenreCount, int, error) {
+	ctx, span := t.start(ctx, "GetGenres")
+	result, extra, err := t.next.GetGenres(ctx, page)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) GetPublishers(ctx context.Context, prefix string, page *domain.Pagination) ([]*domain.PublisherCount, int, error) {
+	ctx, span := t.start(ctx, "GetPublishers")
+	result, extra, err := t.next.GetPublishers(ctx, prefix, page)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) GetDistinctValues(ctx context.Context, field string, filter *domain.BookFilter, page *domain.Pagination) ([]*domain.DistinctValue, int, error) {
+	ctx, span := t.start(ctx, "GetDistinctValues")
+	result, extra, err := t.next.GetDistinctValues(ctx, field, filter, page)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) GetBooksNeedingAttention(ctx context.Context, page *domain.Pagination) ([]*domain.BookAttention, int, error) {
+	ctx, span := t.start(ctx, "GetBooksNeedingAttention")
+	result, extra, err := t.next.GetBooksNeedingAttention(ctx, page)
+	end(span, err)
+	return result, extra, err
+}
+
+func (t *tracingService) BackfillISBN13(ctx context.Context) (*domain.ISBNBackfillResult, error) {
+	ctx, span := t.start(ctx, "BackfillISBN13")
+	result, err := t.next.BackfillISBN13(ctx)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) BulkUpdateBooks(ctx context.Context, req *domain.BulkUpdateRequest) (*domain.BulkUpdateResult, error) {
+	ctx, span := t.start(ctx, "BulkUpdateBooks")
+	result, err := t.next.BulkUpdateBooks(ctx, req)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetPageStats(ctx context.Context, filter *domain.BookFilter) (*domain.PageStats, error) {
+	ctx, span := t.start(ctx, "GetPageStats")
+	result, err := t.next.GetPageStats(ctx, filter)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetGrowth(ctx context.Context, query *domain.GrowthQuery) (*domain.CatalogGrowth, error) {
+	ctx, span := t.start(ctx, "GetGrowth")
+	result, err := t.next.GetGrowth(ctx, query)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) GetGenreStats(ctx context.Context) ([]*domain.GenreStats, error) {
+	ctx, span := t.start(ctx, "GetGenreStats")
+	result, err := t.next.GetGenreStats(ctx)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) StreamBooks(ctx context.Context, filter *domain.BookFilter, fn func(*domain.Book) error) error {
+	ctx, span := t.start(ctx, "StreamBooks")
+	err := t.next.StreamBooks(ctx, filter, fn)
+	end(span, err)
+	return err
+}
+
+func (t *tracingService) ExportCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	ctx, span := t.start(ctx, "ExportCatalog")
+	result, err := t.next.ExportCatalog(ctx, format)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) BackupCatalog(ctx context.Context, format domain.ExportFormat) (*domain.ExportResult, error) {
+	ctx, span := t.start(ctx, "BackupCatalog")
+	result, err := t.next.BackupCatalog(ctx, format)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracingService) CreateAuthority(ctx context.Context, kind domain.AuthorityKind, req *domain.CreateAuthorityRequest) (*domain.Authority, bool, error) {
+	ctx, span := t.start(ctx, "CreateAuthority")
+	result, created, err := t.next.CreateAuthority(ctx, kind, req)
+	end(span, err)
+	return result, created, err
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Config holds the settings for an S3-compatible bucket
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3 stores objects in an S3-compatible bucket using path-style requests
+// signed with AWS Signature Version 4
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates an S3-compatible storage
+func NewS3(cfg S3Config, client *http.Client) *S3 {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3{cfg: cfg, client: client, now: time.Now}
+}
+
+// Put uploads body to the bucket under key. PutObject needs the length and
+// payload hash up front, so the body is spooled to a temporary file rather
+// than held in memory.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	tmp, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to spool export: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to rewind spool file: %w", err)
+	}
+
+	objectURL, err := url.JoinPath(s.cfg.Endpoint, s.cfg.Bucket, key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, io.NopCloser(tmp))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = size
+	s.sign(req, hex.EncodeToString(hash.Sum(nil)))
+
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	resp.Body.Close()
+
+	return size, nil
+}
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// List returns the keys starting with prefix, following continuation tokens
+func (s *S3) List(ctx context.Context, prefix string) ([]string, error) {
+	bucketURL, err := url.JoinPath(s.cfg.Endpoint, s.cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, bucketURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 request: %w", err)
+		}
+		// SigV4 requires spaces encoded as %20 in the canonical query
+		req.URL.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+		s.sign(req, emptyPayloadHash)
+
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list S3 objects: %w", err)
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Delete removes the object named key from the bucket
+func (s *S3) Delete(ctx context.Context, key string) error {
+	objectURL, err := url.JoinPath(s.cfg.Endpoint, s.cfg.Bucket, key)
+	if err != nil {
+		return fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, objectURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, emptyPayloadHash)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete S3 object: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request and turns non-2xx responses into errors
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// sign adds SigV4 authentication headers for a request with the given payload hash
+func (s *S3) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	for _, part := range []string{s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
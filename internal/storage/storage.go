@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Storage is a destination for catalog exports
+type Storage interface {
+	// Put streams body to the object named key and returns the number of bytes written
+	Put(ctx context.Context, key string, body io.Reader) (int64, error)
+
+	// List returns the keys starting with prefix in lexical order
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes the object named key
+	Delete(ctx context.Context, key string) error
+}
+
+// Local stores objects as files under a directory
+type Local struct {
+	dir string
+}
+
+// NewLocal creates a local filesystem storage rooted at dir
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+// Put writes body to dir/key, replacing the file only once it is complete
+func (l *Local) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	return size, nil
+}
+
+// List returns the names of files in dir starting with prefix
+func (l *Local) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export directory: %w", err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasPrefix(entry.Name(), prefix) {
+			keys = append(keys, entry.Name())
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes dir/key
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(l.dir, filepath.FromSlash(key))); err != nil {
+		return fmt.Errorf("failed to delete export file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocal_Put(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocal(dir)
+
+	size, err := store.Put(context.Background(), "exports/catalog.json", strings.NewReader(`[{"id":1}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 10 {
+		t.Errorf("size = %d, want 10", size)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "exports", "catalog.json"))
+	if err != nil {
+		t.Fatalf("got %v, want export file", err)
+	}
+	if string(data) != `[{"id":1}]` {
+		t.Errorf("Unexpected file contents %q", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "exports"))
+	if len(entries) != 1 {
+		t.Errorf("want temporary file to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestLocal_ListDelete(t *testing.T) {
+	store := NewLocal(t.TempDir())
+	ctx := context.Background()
+
+	if keys, err := store.List(ctx, "catalog-"); err != nil || len(keys) != 0 {
+		t.Fatalf("got %v, %v, want empty listing", keys, err)
+	}
+
+	for _, key := range []string{"catalog-2.json", "other.txt", "catalog-1.csv"} {
+		if _, err := store.Put(ctx, key, strings.NewReader("x")); err != nil {
+			t.Fatalf("Failed to put %s: %v", key, err)
+		}
+	}
+
+	keys, err := store.List(ctx, "catalog-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(keys, ",") != "catalog-1.csv,catalog-2.json" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	if err := store.Delete(ctx, "catalog-1.csv"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys, _ := store.List(ctx, "catalog-"); len(keys) != 1 {
+		t.Errorf("got %v, want 1 key after delete", keys)
+	}
+}
+
+func TestS3_Put(t *testing.T) {
+	var gotPath, gotAuth, gotBody string
+	var gotLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotAuth, gotBody, gotLength = r.URL.Path, r.Header.Get("Authorization"), string(body), r.ContentLength
+		if r.URL.Path == "/backups/denied.csv" {
+			http.Error(w, "AccessDenied", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "backups",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	}, server.Client())
+	store.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	t.Run("uploads body", func(t *testing.T) {
+		size, err := store.Put(context.Background(), "catalog.csv", strings.NewReader("id,title\n1,Go\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if size != 14 || gotLength != 14 {
+			t.Errorf("got %d and %d, want size and Content-Length 14", size, gotLength)
+		}
+		if gotPath != "/backups/catalog.csv" {
+			t.Errorf("got %s, want path-style object URL", gotPath)
+		}
+		if gotBody != "id,title\n1,Go\n" {
+			t.Errorf("Unexpected body %q", gotBody)
+		}
+		if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+			t.Errorf("Unexpected Authorization header %q", gotAuth)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := store.Put(context.Background(), "denied.csv", strings.NewReader("x"))
+		if err == nil || !strings.Contains(err.Error(), "403") {
+			t.Errorf("got %v, want 403 error", err)
+		}
+	})
+}
+
+func TestS3_ListDelete(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Query().Get("continuation-token") == "":
+			w.Write([]byte(`<ListBucketResult><Contents><Key>catalog-1.json</Key></Contents>` +
+				`<IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`))
+		default:
+			w.Write([]byte(`<ListBucketResult><Contents><Key>catalog-2.json</Key></Contents>` +
+				`<IsTruncated>false</IsTruncated></ListBucketResult>`))
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3(S3Config{Endpoint: server.URL, Bucket: "backups", Region: "us-east-1"}, server.Client())
+	ctx := context.Background()
+
+	keys, err := store.List(ctx, "catalog-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(keys, ",") != "catalog-1.json,catalog-2.json" {
+		t.Errorf("got %v, want keys from both pages", keys)
+	}
+
+	if err := store.Delete(ctx, "catalog-1.json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != "/backups/catalog-1.json" {
+		t.Errorf("got %s, want object delete", deleted)
+	}
+}
@@ -0,0 +1,2 @@
+-- Index publisher for filtering and the publishers aggregation
+CREATE INDEX IF NOT EXISTS idx_books_publisher ON books(publisher);
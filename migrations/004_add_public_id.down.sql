@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_books_public_id;
+ALTER TABLE books DROP COLUMN IF EXISTS public_id;
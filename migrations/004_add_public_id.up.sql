@@ -0,0 +1,3 @@
+-- Opaque external key; the volatile default gives existing rows distinct IDs
+ALTER TABLE books ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_public_id ON books(public_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_books_description_lower_trgm;
+DROP INDEX IF EXISTS idx_books_title_lower_trgm;
+DROP INDEX IF EXISTS idx_books_publisher_lower_trgm;
+DROP INDEX IF EXISTS idx_books_author_lower_trgm;
+DROP INDEX IF EXISTS idx_books_genre_lower;
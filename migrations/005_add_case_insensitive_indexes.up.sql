@@ -0,0 +1,10 @@
+-- The list filters compare LOWER(column), which plain column indexes cannot serve.
+-- genre is an exact match, so a B-tree on the expression is enough; author,
+-- publisher and the search columns are '%term%' LIKE matches and need trigram
+-- indexes; search ORs title, author and description, so all three are indexed.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_books_genre_lower ON books(LOWER(genre));
+CREATE INDEX IF NOT EXISTS idx_books_author_lower_trgm ON books USING GIN (LOWER(author) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_books_publisher_lower_trgm ON books USING GIN (LOWER(publisher) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_books_title_lower_trgm ON books USING GIN (LOWER(title) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_books_description_lower_trgm ON books USING GIN (LOWER(description) gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- Serves ISBN lookups that ignore hyphens, spaces and the case of the X check digit
+CREATE INDEX IF NOT EXISTS idx_books_isbn_normalized ON books(UPPER(REPLACE(REPLACE(isbn, '-', ''), ' ', '')));
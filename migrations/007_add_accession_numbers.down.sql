@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS assign_books_accession_number ON books;
+DROP FUNCTION IF EXISTS assign_accession_number();
+DROP INDEX IF EXISTS idx_books_accession_number;
+ALTER TABLE books DROP COLUMN IF EXISTS accession_number;
+DROP TABLE IF EXISTS accession_counters;
@@ -0,0 +1,63 @@
+-- Per-year accession numbers such as 2024-00042, assigned on insert from a
+-- counter row that stays locked until the inserting transaction ends
+CREATE TABLE IF NOT EXISTS accession_counters (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS accession_number VARCHAR(20);
+
+CREATE OR REPLACE FUNCTION assign_accession_number()
+RETURNS TRIGGER AS $func$
+DECLARE
+    acc_year INTEGER := EXTRACT(YEAR FROM COALESCE(NEW.created_at, CURRENT_TIMESTAMP) AT TIME ZONE 'UTC');
+    acc_number INTEGER;
+BEGIN
+    IF NEW.accession_number IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+    -- An upsert of a known ISBN keeps the existing book's number
+    SELECT accession_number INTO NEW.accession_number FROM books WHERE isbn = NEW.isbn;
+    IF NEW.accession_number IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO accession_counters (year, last_number) VALUES (acc_year, 1)
+    ON CONFLICT (year) DO UPDATE SET last_number = accession_counters.last_number + 1
+    RETURNING last_number INTO acc_number;
+    NEW.accession_number := acc_year || '-' || lpad(acc_number::text, GREATEST(5, length(acc_number::text)), '0');
+    RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS assign_books_accession_number ON books;
+CREATE TRIGGER assign_books_accession_number
+    BEFORE INSERT ON books
+    FOR EACH ROW
+    EXECUTE FUNCTION assign_accession_number();
+
+-- Number existing books in creation order and advance the counters past them
+LOCK TABLE books, accession_counters IN EXCLUSIVE MODE;
+WITH pending AS (
+    SELECT id, created_at,
+           EXTRACT(YEAR FROM COALESCE(created_at, CURRENT_TIMESTAMP) AT TIME ZONE 'UTC')::int AS year
+    FROM books
+    WHERE accession_number IS NULL
+), numbered AS (
+    SELECT p.id, p.year,
+           COALESCE(c.last_number, 0) + ROW_NUMBER() OVER (PARTITION BY p.year ORDER BY p.created_at, p.id) AS number
+    FROM pending p
+    LEFT JOIN accession_counters c ON c.year = p.year
+)
+UPDATE books b
+SET accession_number = n.year || '-' || lpad(n.number::text, GREATEST(5, length(n.number::text)), '0')
+FROM numbered n
+WHERE b.id = n.id;
+
+INSERT INTO accession_counters (year, last_number)
+SELECT split_part(accession_number, '-', 1)::int, MAX(split_part(accession_number, '-', 2)::int)
+FROM books
+GROUP BY 1
+ON CONFLICT (year) DO UPDATE SET last_number = GREATEST(accession_counters.last_number, EXCLUDED.last_number);
+
+ALTER TABLE books ALTER COLUMN accession_number SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_accession_number ON books(accession_number);
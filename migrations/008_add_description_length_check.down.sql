@@ -0,0 +1 @@
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_description_length_check;
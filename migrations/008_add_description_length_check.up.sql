@@ -0,0 +1,5 @@
+-- Bound descriptions to the default DESCRIPTION_MAX_LENGTH; NOT VALID leaves
+-- longer descriptions already stored in place while checking new writes
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_description_length_check;
+ALTER TABLE books ADD CONSTRAINT books_description_length_check
+    CHECK (char_length(description) <= 1000) NOT VALID;
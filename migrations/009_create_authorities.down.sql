@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS publishers;
+DROP TABLE IF EXISTS authors;
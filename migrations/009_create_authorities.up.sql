@@ -0,0 +1,29 @@
+-- Reference tables of known authors and publishers, checked on writes when
+-- AUTHORITY_MODE is strict or lenient; names are unique ignoring case
+CREATE TABLE IF NOT EXISTS authors (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_name_lower ON authors (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS publishers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_publishers_name_lower ON publishers (LOWER(name));
+
+-- Seed empty tables with the names already on books, so strict checks do
+-- not reject the existing catalog's authors and publishers
+INSERT INTO authors (name)
+SELECT DISTINCT ON (LOWER(author)) author FROM books
+WHERE NOT EXISTS (SELECT 1 FROM authors)
+ORDER BY LOWER(author), author
+ON CONFLICT DO NOTHING;
+
+INSERT INTO publishers (name)
+SELECT DISTINCT ON (LOWER(publisher)) publisher FROM books
+WHERE NOT EXISTS (SELECT 1 FROM publishers)
+ORDER BY LOWER(publisher), publisher
+ON CONFLICT DO NOTHING;
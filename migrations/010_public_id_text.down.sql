@@ -0,0 +1,3 @@
+-- Fails while any book has a non-UUID public ID, such as a slug
+ALTER TABLE books ALTER COLUMN public_id SET DEFAULT gen_random_uuid();
+ALTER TABLE books ALTER COLUMN public_id TYPE UUID USING public_id::uuid;
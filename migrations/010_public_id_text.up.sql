@@ -0,0 +1,3 @@
+-- Hold any PUBLIC_ID_FORMAT, not just UUIDs; existing UUIDs keep their text form
+ALTER TABLE books ALTER COLUMN public_id TYPE TEXT USING public_id::text;
+ALTER TABLE books ALTER COLUMN public_id SET DEFAULT gen_random_uuid()::text;
@@ -0,0 +1,2 @@
+-- The ISBN-10 the ISBN-13 backfill replaced, when ISBN_BACKFILL_KEEP_ORIGINAL is set
+ALTER TABLE books ADD COLUMN IF NOT EXISTS original_isbn VARCHAR(20);
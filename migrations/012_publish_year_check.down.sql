@@ -0,0 +1,2 @@
+-- Fails while any book's publish_year is outside the constraint's range
+ALTER TABLE books VALIDATE CONSTRAINT books_publish_year_check;
@@ -0,0 +1,5 @@
+-- Bound publish_year to the default PUBLISH_YEAR_MIN/PUBLISH_YEAR_MAX; startup
+-- replaces the constraint with the configured range
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_publish_year_check;
+ALTER TABLE books ADD CONSTRAINT books_publish_year_check
+    CHECK (publish_year >= 1000 AND publish_year <= 2030) NOT VALID;
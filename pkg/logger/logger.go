@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
 // Logger defines the logging interface
@@ -12,6 +14,24 @@ type Logger interface {
 	Warn(msg string, args ...interface{})
 	Debug(msg string, args ...interface{})
 	Fatal(msg string, args ...interface{})
+
+	// With returns a logger that includes the given key-value pairs in every entry
+	With(args ...interface{}) Logger
+}
+
+// RedactedValue replaces the value of redacted fields
+const RedactedValue = "***"
+
+// Options configures a logger
+type Options struct {
+	// Output is where log entries are written; defaults to os.Stdout
+	Output io.Writer
+	// RedactFields lists field names (case-insensitive) whose values are masked
+	RedactFields []string
+	// Level is the lowest level logged: debug, info (the default), warn or error
+	Level string
+	// Format is "json" (the default) or "text" for human-readable entries
+	Format string
 }
 
 type logger struct {
@@ -20,11 +40,46 @@ type logger struct {
 
 // New creates a new structured logger
 func New() Logger {
-	// Create a JSON handler for structured logging
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})
-	
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a new structured logger with the given options
+func NewWithOptions(opts Options) Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	redact := make(map[string]bool, len(opts.RedactFields))
+	for _, field := range opts.RedactFields {
+		if field = strings.TrimSpace(field); field != "" {
+			redact[strings.ToLower(field)] = true
+		}
+	}
+
+	level := slog.LevelInfo
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			level = slog.LevelInfo
+		}
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if redact[strings.ToLower(attr.Key)] {
+				return slog.String(attr.Key, RedactedValue)
+			}
+			return attr
+		},
+	}
+
+	// Log structured JSON unless text is asked for
+	var handler slog.Handler = slog.NewJSONHandler(output, handlerOpts)
+	if strings.EqualFold(opts.Format, "text") {
+		handler = slog.NewTextHandler(output, handlerOpts)
+	}
+
 	return &logger{
 		Logger: slog.New(handler),
 	}
@@ -49,4 +104,8 @@ func (l *logger) Debug(msg string, args ...interface{}) {
 func (l *logger) Fatal(msg string, args ...interface{}) {
 	l.Logger.Error(msg, args...)
 	os.Exit(1)
-}
\ No newline at end of file
+}
+
+func (l *logger) With(args ...interface{}) Logger {
+	return &logger{Logger: l.Logger.With(args...)}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithOptions(Options{
+		Output:       &buf,
+		RedactFields: []string{"Authorization", "borrower"},
+	})
+
+	log.With("borrower", "Jane Doe").Info("loan created", "authorization", "Bearer secret", "book_id", "42")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log entry %q: %v", buf.String(), err)
+	}
+
+	if entry["authorization"] != RedactedValue {
+		t.Errorf("authorization = %v, want %q", entry["authorization"], RedactedValue)
+	}
+	if entry["borrower"] != RedactedValue {
+		t.Errorf("borrower = %v, want %q", entry["borrower"], RedactedValue)
+	}
+	if entry["book_id"] != "42" {
+		t.Errorf("book_id = %v, want %q", entry["book_id"], "42")
+	}
+}
+
+func TestLevelAndFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithOptions(Options{Output: &buf, Level: "warn", Format: "text"})
+
+	log.Info("skipped")
+	log.Warn("kept", "book_id", "42")
+
+	out := buf.String()
+	if bytes.Contains(buf.Bytes(), []byte("skipped")) {
+		t.Errorf("got %q, want info entries below the warn level to be dropped", out)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("level=WARN msg=kept book_id=42")) {
+		t.Errorf("got %q, want a text entry", out)
+	}
+}
@@ -0,0 +1,116 @@
+package realip
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver determines a request's client IP, trusting X-Forwarded-For only
+// when it was appended by a known proxy
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// New creates a resolver that trusts proxies within the given prefixes. With
+// no prefixes, the connection's remote address is always used.
+func New(trusted []netip.Prefix) *Resolver {
+	return &Resolver{trusted: trusted}
+}
+
+// ParsePrefixes parses CIDRs or bare IP addresses into prefixes
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the right-most address in the chain of RemoteAddr and
+// X-Forwarded-For hops that is not a trusted proxy. Hops left of that address
+// were supplied by the client and cannot be trusted. If every hop is a
+// trusted proxy, the left-most is returned.
+func (r *Resolver) ClientIP(req *http.Request) string {
+	remote := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if r == nil || len(r.trusted) == 0 {
+		return remote
+	}
+
+	client, err := netip.ParseAddr(remote)
+	if err != nil || !r.isTrusted(client) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(hop)
+		if err != nil {
+			// A malformed hop cannot be vouched for; stop at the last trusted proxy
+			break
+		}
+		client = addr
+		if !r.isTrusted(addr) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// Scheme returns "https" for TLS connections. Otherwise it returns the
+// X-Forwarded-Proto value, "http" or "https", when the connection comes from a
+// trusted proxy, and "http" when it does not. Proxies that append to the
+// header leave the client-facing scheme first, so that value is used.
+func (r *Resolver) Scheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	if r == nil || len(r.trusted) == 0 {
+		return "http"
+	}
+
+	remote := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	peer, err := netip.ParseAddr(remote)
+	if err != nil || !r.isTrusted(peer) {
+		return "http"
+	}
+
+	proto, _, _ := strings.Cut(req.Header.Get("X-Forwarded-Proto"), ",")
+	if strings.EqualFold(strings.TrimSpace(proto), "https") {
+		return "https"
+	}
+	return "http"
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package realip
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resolver := New(trusted)
+
+	tests := []struct {
+		name     string
+		resolver *Resolver
+		remote   string
+		xff      []string
+		expected string
+	}{
+		{"no trusted proxies ignores header", New(nil), "203.0.113.7:5000", []string{"1.2.3.4"}, "203.0.113.7"},
+		{"untrusted peer ignores header", resolver, "203.0.113.7:5000", []string{"1.2.3.4"}, "203.0.113.7"},
+		{"single trusted hop", resolver, "10.0.0.2:5000", []string{"198.51.100.9"}, "198.51.100.9"},
+		{"multi-hop chain", resolver, "10.0.0.2:5000", []string{"198.51.100.9, 10.1.2.3, 192.168.1.1"}, "198.51.100.9"},
+		{"spoofed left-most entry ignored", resolver, "10.0.0.2:5000", []string{"6.6.6.6, 198.51.100.9, 10.1.2.3"}, "198.51.100.9"},
+		{"multiple headers", resolver, "10.0.0.2:5000", []string{"6.6.6.6", "198.51.100.9"}, "198.51.100.9"},
+		{"all hops trusted", resolver, "10.0.0.2:5000", []string{"10.9.9.9, 10.1.2.3"}, "10.9.9.9"},
+		{"malformed hop", resolver, "10.0.0.2:5000", []string{"198.51.100.9, garbage, 10.1.2.3"}, "10.1.2.3"},
+		{"no header from trusted peer", resolver, "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"ipv6", New([]netip.Prefix{}), "[2001:db8::1]:443", nil, "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, value := range tt.xff {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := tt.resolver.ClientIP(req); got != tt.expected {
+				t.Errorf("got %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePrefixes_Invalid(t *testing.T) {
+	if _, err := ParsePrefixes([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("want error for invalid CIDR")
+	}
+	if _, err := ParsePrefixes([]string{"proxy.local"}); err == nil {
+		t.Error("want error for hostname")
+	}
+}
+
+func TestResolver_Scheme(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("ParsePrefixes() error = %v", err)
+	}
+	resolver := New(trusted)
+
+	tests := []struct {
+		name     string
+		resolver *Resolver
+		remote   string
+		proto    string
+		tls      bool
+		want     string
+	}{
+		{"tls", New(nil), "203.0.113.7:5000", "", true, "https"},
+		{"plain", resolver, "203.0.113.7:5000", "", false, "http"},
+		{"trusted proxy https", resolver, "10.0.0.2:5000", "https", false, "https"},
+		{"trusted proxy http", resolver, "10.0.0.2:5000", "http", false, "http"},
+		{"trusted proxy list", resolver, "10.0.0.2:5000", "HTTPS, http", false, "https"},
+		{"untrusted peer ignored", resolver, "203.0.113.7:5000", "https", false, "http"},
+		{"no trusted proxies ignored", New(nil), "10.0.0.2:5000", "https", false, "http"},
+		{"unknown value", resolver, "10.0.0.2:5000", "wss", false, "http"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if got := tt.resolver.Scheme(req); got != tt.want {
+				t.Errorf("Scheme() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}